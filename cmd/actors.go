@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+)
+
+type actorStats struct {
+	actor   string
+	bot     bool
+	runs    int
+	failed  int
+	retries int
+	ciTime  time.Duration
+}
+
+// getActorStats aggregates runs by the user or bot that triggered them. Re-runs are
+// attributed to the user who re-ran them.
+func getActorStats(result map[string][]*github.WorkflowRun) []*actorStats {
+	statsMap := map[string]*actorStats{}
+	for _, runs := range result {
+		for _, run := range runs {
+			actor := run.GetTriggeringActor()
+			if actor == nil {
+				actor = run.GetActor()
+			}
+			stats, ok := statsMap[actor.GetLogin()]
+			if !ok {
+				stats = &actorStats{actor: actor.GetLogin(), bot: actor.GetType() == "Bot"}
+				statsMap[actor.GetLogin()] = stats
+			}
+			stats.runs++
+			if run.GetConclusion() != "success" {
+				stats.failed++
+			}
+			stats.retries += max(run.GetRunAttempt()-1, 0)
+			stats.ciTime += runDurations.Of(run)
+		}
+	}
+	var statsList []*actorStats
+	for _, stats := range statsMap {
+		statsList = append(statsList, stats)
+	}
+	slices.SortFunc(statsList, func(a, b *actorStats) int {
+		return cmp.Or(cmp.Compare(b.ciTime, a.ciTime), cmp.Compare(a.actor, b.actor))
+	})
+	return statsList
+}
+
+// printActorStats prints the top n actors by CI time consumed, with their run counts and
+// failure rates.
+func printActorStats(result map[string][]*github.WorkflowRun, top int) {
+	color.New(color.Bold).Println("\nCI consumption by actor")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "actor\tCI time\truns\tfailure rate\tre-runs")
+	for i, stats := range getActorStats(result) {
+		if i >= top {
+			break
+		}
+		actor := stats.actor
+		if stats.bot {
+			actor += " (bot)"
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%d\t%.0f%%\t%d",
+			actor, stats.ciTime.Round(time.Minute), stats.runs, 100*float32(stats.failed)/float32(stats.runs), stats.retries))
+	}
+	w.Flush()
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+)
+
+const (
+	// anomalyWindow is the number of preceding days with runs that the daily success rate
+	// is compared with.
+	anomalyWindow = 7
+	// anomalyMinStddev is the minimum standard deviation in percentage points, so that a
+	// workflow that always succeeded is not flagged for a single failure.
+	anomalyMinStddev = 5
+)
+
+// dailySuccessRate is the success rate of a workflow on a day in UTC.
+type dailySuccessRate struct {
+	day   time.Time
+	rate  float64
+	count int
+}
+
+// anomaly is a day whose success rate is an outlier compared with the preceding days.
+type anomaly struct {
+	workflow string
+	dailySuccessRate
+	mean   float64
+	zScore float64
+}
+
+// getDailySuccessRates returns the success rates of the runs per day, in chronological
+// order. Days without runs are skipped.
+func getDailySuccessRates(runs []*github.WorkflowRun) []dailySuccessRate {
+	success := map[time.Time]int{}
+	count := map[time.Time]int{}
+	for _, run := range runs {
+		day := run.GetCreatedAt().UTC().Truncate(24 * time.Hour)
+		count[day]++
+		if run.GetConclusion() == "success" {
+			success[day]++
+		}
+	}
+	var rates []dailySuccessRate
+	for day, n := range count {
+		rates = append(rates, dailySuccessRate{day: day, rate: 100 * float64(success[day]) / float64(n), count: n})
+	}
+	slices.SortFunc(rates, func(a, b dailySuccessRate) int {
+		return a.day.Compare(b.day)
+	})
+	return rates
+}
+
+// getAnomalies flags the days whose success rate deviates from the rolling mean of the
+// preceding anomalyWindow days by at least threshold standard deviations.
+func getAnomalies(result map[string][]*github.WorkflowRun, threshold float64) []anomaly {
+	var anomalies []anomaly
+	for workflow, runs := range result {
+		rates := getDailySuccessRates(runs)
+		for i := anomalyWindow; i < len(rates); i++ {
+			var sum, sumSquares float64
+			for _, r := range rates[i-anomalyWindow : i] {
+				sum += r.rate
+				sumSquares += r.rate * r.rate
+			}
+			mean := sum / anomalyWindow
+			stddev := math.Sqrt(max(sumSquares/anomalyWindow-mean*mean, 0))
+			zScore := (rates[i].rate - mean) / max(stddev, anomalyMinStddev)
+			if math.Abs(zScore) >= threshold {
+				anomalies = append(anomalies, anomaly{workflow: workflow, dailySuccessRate: rates[i], mean: mean, zScore: zScore})
+			}
+		}
+	}
+	slices.SortFunc(anomalies, func(a, b anomaly) int {
+		return cmp.Or(b.day.Compare(a.day), cmp.Compare(a.workflow, b.workflow))
+	})
+	return anomalies
+}
+
+// printAnomalies prints the days whose success rate is a significant outlier for the
+// workflow, most recent first.
+func printAnomalies(result map[string][]*github.WorkflowRun, threshold float64) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	color.New(color.Bold).Println("\nsuccess rate anomalies")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "day\tsuccess rate\texpected\tz-score\truns\tworkflow")
+	for _, a := range getAnomalies(result, threshold) {
+		zScore := fmt.Sprintf("%+.1f", a.zScore)
+		if a.zScore < 0 {
+			zScore = red(zScore)
+		} else {
+			zScore = green(zScore)
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%.0f%%\t%.0f%%\t%s\t%d\t%s", a.day.Format(time.DateOnly), a.rate, a.mean, zScore, a.count, a.workflow))
+	}
+	w.Flush()
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// getRunArtifacts lists the unexpired artifacts of the run whose names match the regex.
+func getRunArtifacts(ctx context.Context, client *github.Client, owner, repo string, runID int64, artifactRegexp *regexp.Regexp) ([]*github.Artifact, error) {
+	var result []*github.Artifact
+	listOptions := github.ListOptions{PerPage: 100}
+	for {
+		artifacts, res, err := client.Actions.ListWorkflowRunArtifacts(ctx, owner, repo, runID, &listOptions)
+		if err != nil {
+			return result, err
+		}
+		for _, artifact := range artifacts.Artifacts {
+			if !artifact.GetExpired() && artifactRegexp.MatchString(artifact.GetName()) {
+				result = append(result, artifact)
+			}
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		listOptions.Page = res.NextPage
+	}
+	return result, nil
+}
+
+// getFailureArtifacts lists the matching artifacts of the failed runs in parallel, and
+// returns them keyed by run ID.
+func getFailureArtifacts(ctx context.Context, client *github.Client, owner, repo string, runs []*github.WorkflowRun, artifactRegexp *regexp.Regexp) map[int64][]*github.Artifact {
+	result := map[int64][]*github.Artifact{}
+	tasks := make(chan int64)
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for runID := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				artifacts, err := getRunArtifacts(ctx, client, owner, repo, runID, artifactRegexp)
+				if err != nil {
+					slog.Error("Failed to list artifacts", slog.Int64("run-id", runID), slog.Any("error", err))
+					continue
+				}
+				mux.Lock()
+				result[runID] = artifacts
+				mux.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	for _, run := range runs {
+		if run.GetConclusion() == "failure" {
+			tasks <- run.GetID()
+		}
+	}
+	close(tasks)
+	wg.Wait()
+	return result
+}
+
+// artifactsForJob returns the artifacts that belong to the job. Artifacts don't record the
+// job that uploaded them, so they are matched by the matrix values in the job name (e.g.
+// "1.29" in "Installation and Connectivity Test (1.29)") appearing in the artifact name.
+// All the artifacts of the run are returned if the job name has no matrix values or none
+// of the artifacts match.
+func artifactsForJob(job *github.WorkflowJob, artifacts []*github.Artifact) []*github.Artifact {
+	name := job.GetName()
+	start := strings.LastIndex(name, "(")
+	if start < 0 || !strings.HasSuffix(name, ")") {
+		return artifacts
+	}
+	values := strings.Split(name[start+1:len(name)-1], ",")
+	var result []*github.Artifact
+	for _, artifact := range artifacts {
+		if !slices.ContainsFunc(values, func(value string) bool {
+			return !strings.Contains(artifact.GetName(), strings.TrimSpace(value))
+		}) {
+			result = append(result, artifact)
+		}
+	}
+	if len(result) == 0 {
+		return artifacts
+	}
+	return result
+}
+
+// printFailureArtifacts prints the artifacts uploaded by failed runs, such as sysdumps,
+// next to the failed jobs with links to download them.
+func printFailureArtifacts(ctx context.Context, client *github.Client, owner, repo string, runs []*github.WorkflowRun, artifactRegexp *regexp.Regexp) {
+	artifacts := getFailureArtifacts(ctx, client, owner, repo, runs, artifactRegexp)
+	var failedRuns []*github.WorkflowRun
+	for _, run := range runs {
+		if len(artifacts[run.GetID()]) > 0 {
+			failedRuns = append(failedRuns, run)
+		}
+	}
+	jobs := dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, failedRuns, numWorkers)
+	link := color.New(color.FgCyan).SprintFunc()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	color.New(color.FgRed, color.Bold).Println("\nfailure artifacts")
+	fmt.Fprintln(w, "job name\tartifact\tsize")
+	for _, run := range failedRuns {
+		for _, job := range jobs[run.GetID()] {
+			if job.GetConclusion() != "failure" {
+				continue
+			}
+			for _, artifact := range artifactsForJob(job, artifacts[run.GetID()]) {
+				artifactURL := fmt.Sprintf("%s/artifacts/%d", run.GetHTMLURL(), artifact.GetID())
+				fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%.1f MB", link(getLink(job.GetHTMLURL(), job.GetName())), link(getLink(artifactURL, artifact.GetName())), float64(artifact.GetSizeInBytes())/1024/1024))
+			}
+		}
+	}
+	w.Flush()
+}
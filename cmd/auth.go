@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage provider tokens",
+	Long: `Manage provider tokens stored in the OS keychain or in an encrypted file.
+
+Tokens in the GITHUB_TOKEN and GITLAB_TOKEN environment variables take precedence over
+stored tokens. The encrypted file is used if the keychain is not available, and requires
+the CI_DASHBOARD_PASSPHRASE environment variable.`,
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login provider",
+	Short: "Store the token of a provider (github, gitlab) read from stdin",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		provider, err := checkProvider(args[0])
+		if err != nil {
+			return err
+		}
+		store, err := getCredentialStore(cmd)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			fmt.Fprintf(os.Stderr, "Paste the %s token: ", provider)
+		}
+		token, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		token = strings.TrimSpace(token)
+		if token == "" {
+			return fmt.Errorf("failed to read the token from stdin: %w", err)
+		}
+		if err := store.set(provider, token); err != nil {
+			return err
+		}
+		fmt.Printf("Stored the %s token in the %s\n", provider, store.name())
+		return nil
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where the token of each provider comes from",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintln(w, "provider\ttoken")
+		for _, provider := range providerNames() {
+			envVar := tokenEnvVars[provider]
+			if os.Getenv(envVar) != "" {
+				fmt.Fprintln(w, fmt.Sprintf("%s\t%s", provider, color.GreenString("environment variable %s", envVar)))
+				continue
+			}
+			if token, store := getStoredToken(provider); token != "" {
+				fmt.Fprintln(w, fmt.Sprintf("%s\t%s", provider, color.GreenString(store.name())))
+				continue
+			}
+			fmt.Fprintln(w, fmt.Sprintf("%s\t%s", provider, color.RedString("not logged in")))
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout provider",
+	Short: "Remove the stored token of a provider (github, gitlab)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		provider, err := checkProvider(args[0])
+		if err != nil {
+			return err
+		}
+		store, err := getCredentialStore(cmd)
+		if err != nil {
+			return err
+		}
+		if err := store.delete(provider); err != nil {
+			return err
+		}
+		fmt.Printf("Removed the %s token from the %s\n", provider, store.name())
+		return nil
+	},
+}
+
+func providerNames() []string {
+	var names []string
+	for provider := range tokenEnvVars {
+		names = append(names, provider)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func checkProvider(provider string) (string, error) {
+	if _, ok := tokenEnvVars[provider]; !ok {
+		return "", fmt.Errorf("unknown provider %q, expected one of %s", provider, strings.Join(providerNames(), ", "))
+	}
+	return provider, nil
+}
+
+func getCredentialStore(cmd *cobra.Command) (credentialStore, error) {
+	kind, err := cmd.Flags().GetString("store")
+	if err != nil {
+		return nil, err
+	}
+	return newCredentialStore(kind)
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authLoginCmd)
+	authCmd.AddCommand(authStatusCmd)
+	authCmd.AddCommand(authLogoutCmd)
+
+	authCmd.PersistentFlags().String("store", "auto", "Where to store tokens (keychain, file, or auto to use the keychain if available)")
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var badgeCmd = &cobra.Command{
+	Use:   "badge owner repo",
+	Short: "Generate badges of the success rate of each workflow",
+	Long: `Generate badges of the success rate of each workflow over the chosen window, for
+embedding in READMEs. Badges are either shields.io URLs or SVG files written to
+<output>/<workflow>.svg. The colors follow --warn-below, --critical-below, and the
+healthThresholds in the config.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		workflowFlag, err := cmd.Flags().GetString("workflow")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if format != "url" && format != "markdown" && format != "svg" {
+			return fmt.Errorf("unknown badge format %q", format)
+		}
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		warnBelow, err = cmd.Flags().GetFloat32("warn-below")
+		if err != nil {
+			return err
+		}
+		criticalBelow, err = cmd.Flags().GetFloat32("critical-below")
+		if err != nil {
+			return err
+		}
+		if err := cfg.checkHealthThresholds(); err != nil {
+			return err
+		}
+		var workflows []string
+		if workflowFlag != "" {
+			workflows = append(workflows, workflowFlag)
+		} else {
+			workflows, err = dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+			if err != nil {
+				return err
+			}
+		}
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+		if format == "svg" {
+			if err := os.MkdirAll(output, 0755); err != nil {
+				return err
+			}
+		}
+		for _, workflow := range workflows {
+			runs := result[workflow]
+			if len(runs) == 0 {
+				continue
+			}
+			label := strings.TrimSuffix(strings.TrimSuffix(workflow, ".yaml"), ".yml")
+			message := fmt.Sprintf("%.0f%%", dashboard.SuccessRate(runs))
+			color := badgeColor(cfg, workflow, dashboard.SuccessRate(runs))
+			switch format {
+			case "url":
+				fmt.Printf("%s %s\n", workflow, shieldsURL(label, message, color))
+			case "markdown":
+				fmt.Printf("![%s](%s)\n", label, shieldsURL(label, message, color))
+			case "svg":
+				filename := filepath.Join(output, label+".svg")
+				if err := os.WriteFile(filename, []byte(badgeSVG(label, message, color)), 0644); err != nil {
+					return err
+				}
+				fmt.Println(filename)
+			}
+		}
+		return nil
+	},
+}
+
+// badgeColors are the hex colors of the shields.io color names used for badges.
+var badgeColors = map[string]string{
+	"brightgreen": "#4c1",
+	"yellow":      "#dfb317",
+	"red":         "#e05d44",
+}
+
+// badgeColor returns the shields.io color name for the success rate of the workflow.
+func badgeColor(cfg *config, workflow string, rate float32) string {
+	warn, critical := cfg.healthThresholds(workflow)
+	switch {
+	case rate < critical:
+		return "red"
+	case rate < warn:
+		return "yellow"
+	default:
+		return "brightgreen"
+	}
+}
+
+// shieldsURL returns the URL of a static shields.io badge. Dashes and underscores are
+// escaped by doubling them, since they separate the label, the message, and the color.
+func shieldsURL(label, message, color string) string {
+	escape := func(s string) string {
+		s = strings.ReplaceAll(s, "-", "--")
+		s = strings.ReplaceAll(s, "_", "__")
+		return url.PathEscape(s)
+	}
+	return fmt.Sprintf("https://img.shields.io/badge/%s-%s-%s", escape(label), escape(message), color)
+}
+
+// badgeSVG renders a flat badge similar to shields.io. Text widths are estimated, since
+// the font metrics are not available.
+func badgeSVG(label, message, color string) string {
+	const charWidth, padding = 7, 10
+	labelWidth := len(label)*charWidth + padding
+	messageWidth := len(message)*charWidth + padding
+	title := html.EscapeString(label + ": " + message)
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s">
+  <title>%s</title>
+  <rect width="%d" height="20" fill="#555"/>
+  <rect x="%d" width="%d" height="20" fill="%s"/>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="%d" y="14">%s</text>
+    <text x="%d" y="14">%s</text>
+  </g>
+</svg>
+`, labelWidth+messageWidth, title, title, labelWidth, labelWidth, messageWidth, badgeColors[color],
+		labelWidth/2, html.EscapeString(label), labelWidth+messageWidth/2, html.EscapeString(message))
+}
+
+func init() {
+	rootCmd.AddCommand(badgeCmd)
+
+	badgeCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	badgeCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	badgeCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	badgeCmd.Flags().StringP("workflow", "w", "", "Workflow name (e.g. aks-byocni.yaml)")
+	badgeCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	badgeCmd.Flags().String("format", "url", "Badge format (url, markdown, svg)")
+	badgeCmd.Flags().StringP("output", "o", "badges", "Directory to write SVG badges to")
+	badgeCmd.Flags().Float32("warn-below", warnBelow, "Color badges of workflows with a success rate below this percentage yellow")
+	badgeCmd.Flags().Float32("critical-below", criticalBelow, "Color badges of workflows with a success rate below this percentage red")
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var bisectCmd = &cobra.Command{
+	Use:   "bisect owner repo workflow",
+	Short: "Find the commit range after which a workflow started failing consistently",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 3 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		workflow := args[2]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		runs, err := getWorkflowRuns(ctx, client, owner, repo, branch, workflow, event, numRuns, daysToTimeRange(days))
+		if err != nil {
+			return err
+		}
+		printFailuresBySHA(runs)
+		return printBreakingRange(ctx, client, owner, repo, runs)
+	},
+}
+
+// printFailuresBySHA prints the number of failed and successful runs for each commit,
+// newest commit first.
+func printFailuresBySHA(runs []*github.WorkflowRun) {
+	type commitRuns struct {
+		sha     string
+		first   time.Time
+		success int
+		failure int
+	}
+	var commits []*commitRuns
+	bySHA := map[string]*commitRuns{}
+	for _, run := range runs {
+		c, ok := bySHA[run.GetHeadSHA()]
+		if !ok {
+			c = &commitRuns{sha: run.GetHeadSHA()}
+			bySHA[run.GetHeadSHA()] = c
+			commits = append(commits, c)
+		}
+		c.first = run.GetRunStartedAt().Time
+		if run.GetConclusion() == "success" {
+			c.success++
+		} else {
+			c.failure++
+		}
+	}
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "sha\tfirst run\tsuccess\tfailure")
+	for _, c := range commits {
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%s",
+			c.sha[:min(len(c.sha), 7)], formatTime(c.first), green(c.success), red(c.failure)))
+	}
+	w.Flush()
+}
+
+// printBreakingRange prints the commits between the last successful run and the first
+// run of the current failure streak. Runs are expected to be sorted from newest to oldest.
+func printBreakingRange(ctx context.Context, client *github.Client, owner, repo string, runs []*github.WorkflowRun) error {
+	streak := dashboard.FailureStreak(runs)
+	fmt.Println()
+	if streak == 0 {
+		color.New(color.FgGreen).Println("The latest run succeeded.")
+		return nil
+	}
+	firstBad := runs[streak-1]
+	if streak == len(runs) {
+		color.New(color.FgRed).Printf("All %d runs failed. The first failure is at %s.\n", streak, firstBad.GetHeadSHA())
+		return nil
+	}
+	lastGood := runs[streak]
+	color.New(color.FgRed, color.Bold).Printf("Failing consistently for %d runs since %s.\n",
+		streak, formatTime(firstBad.GetRunStartedAt().Time))
+	if lastGood.GetHeadSHA() == firstBad.GetHeadSHA() {
+		fmt.Printf("The last successful run and the first failed run are on the same commit %s, "+
+			"so the failures are likely not caused by a code change.\n", firstBad.GetHeadSHA())
+		return nil
+	}
+	comparison, _, err := client.Repositories.CompareCommits(ctx, owner, repo, lastGood.GetHeadSHA(), firstBad.GetHeadSHA(), &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d commits between the last successful run and the first failed run:\n", comparison.GetTotalCommits())
+	color.New(color.FgCyan, color.Underline).Println(comparison.GetHTMLURL())
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	for _, commit := range comparison.Commits {
+		message, _, _ := strings.Cut(commit.GetCommit().GetMessage(), "\n")
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s", commit.GetSHA()[:7], commit.GetCommit().GetAuthor().GetName(), message))
+	}
+	w.Flush()
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(bisectCmd)
+
+	bisectCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	bisectCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	bisectCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	bisectCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+}
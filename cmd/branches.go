@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// printBranchMatrix prints the success rate of each workflow on each branch, keyed by
+// branch and workflow file name, with the workflows that fail the most on any branch
+// first. The rates are colored with the health thresholds of the workflows.
+func printBranchMatrix(cfg *config, branches []string, result map[string]map[string][]*github.WorkflowRun) {
+	workflowSet := map[string]struct{}{}
+	for _, workflows := range result {
+		for workflow, runs := range workflows {
+			if len(runs) > 0 {
+				workflowSet[workflow] = struct{}{}
+			}
+		}
+	}
+	// lowest returns the lowest success rate of the workflow across the branches.
+	lowest := func(workflow string) float32 {
+		rate := float32(101)
+		for _, branch := range branches {
+			if runs := result[branch][workflow]; len(runs) > 0 {
+				rate = min(rate, dashboard.SuccessRate(runs))
+			}
+		}
+		return rate
+	}
+	var workflows []string
+	for workflow := range workflowSet {
+		workflows = append(workflows, workflow)
+	}
+	slices.SortFunc(workflows, func(a, b string) int {
+		return cmp.Or(cmp.Compare(lowest(a), lowest(b)), cmp.Compare(a, b))
+	})
+	bold := color.New(color.Bold).SprintFunc()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	var header []string
+	for _, branch := range branches {
+		header = append(header, bold(branch))
+	}
+	fmt.Fprintln(w, fmt.Sprintf("workflow\t%s", strings.Join(header, "\t")))
+	for _, workflow := range workflows {
+		var cells []string
+		for _, branch := range branches {
+			runs := result[branch][workflow]
+			if len(runs) == 0 {
+				cells = append(cells, "-")
+				continue
+			}
+			success := 0
+			for _, run := range runs {
+				if run.GetConclusion() == "success" {
+					success++
+				}
+			}
+			rate := dashboard.SuccessRate(runs)
+			warn, critical := cfg.healthThresholds(workflow)
+			c := color.New(color.FgGreen)
+			if rate < critical {
+				c = color.New(color.FgRed)
+			} else if rate < warn {
+				c = color.New(color.FgYellow)
+			}
+			cells = append(cells, c.Sprintf("%.0f%% %d/%d", rate, success, len(runs)))
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s", workflow, strings.Join(cells, "\t")))
+	}
+	w.Flush()
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// bundle is a portable set of recorded API responses for offline analysis. It is written
+// by the fetch command and replayed with --from-bundle.
+type bundle struct {
+	CreatedAt time.Time `json:"createdAt"`
+	// Responses are keyed by bundleKey.
+	Responses map[string]bundleResponse `json:"responses"`
+}
+
+type bundleResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// bundleKey returns the key of the request in the bundle. The created query parameter is
+// ignored because it is computed from the current time, which differs between fetching
+// and analysis.
+func bundleKey(req *http.Request) string {
+	u := *req.URL
+	q := u.Query()
+	q.Del("created")
+	u.RawQuery = q.Encode()
+	return req.Method + " " + u.String()
+}
+
+// recordingTransport records the responses of GET requests into a bundle.
+type recordingTransport struct {
+	base   http.RoundTripper
+	mux    sync.Mutex
+	bundle bundle
+}
+
+func newRecordingTransport(base http.RoundTripper) *recordingTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &recordingTransport{
+		base:   base,
+		bundle: bundle{CreatedAt: time.Now().UTC(), Responses: map[string]bundleResponse{}},
+	}
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || req.Method != http.MethodGet || resp.StatusCode >= http.StatusInternalServerError {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.mux.Lock()
+	t.bundle.Responses[bundleKey(req)] = bundleResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}
+	t.mux.Unlock()
+	return resp, nil
+}
+
+func (t *recordingTransport) write(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	t.mux.Lock()
+	err = json.NewEncoder(zw).Encode(t.bundle)
+	t.mux.Unlock()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// replayTransport serves responses from a bundle without network access.
+type replayTransport struct {
+	bundle bundle
+}
+
+func newReplayTransport(filename string) (*replayTransport, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %s: %w", filename, err)
+	}
+	t := &replayTransport{}
+	if err := json.NewDecoder(zr).Decode(&t.bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle %s: %w", filename, err)
+	}
+	return t, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r, ok := t.bundle.Responses[bundleKey(req)]
+	if !ok {
+		return nil, fmt.Errorf("%s %s is not in the bundle, fetch it with the same flags", req.Method, req.URL)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header,
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// stepCategoryRule assigns steps whose names match the regular expression to a category.
+// The first matching rule wins.
+type stepCategoryRule struct {
+	Category string `yaml:"category"`
+	Regex    string `yaml:"regex"`
+}
+
+// otherCategory is the category of steps that match no rule.
+const otherCategory = "other"
+
+// defaultStepCategoryRules are used if the config has no step categories.
+var defaultStepCategoryRules = []stepCategoryRule{
+	{Category: "teardown", Regex: `(?i)^post |^complete job|clean ?up|teardown|upload|artifact|sysdump|gather`},
+	{Category: "checkout", Regex: `(?i)checkout|clone`},
+	{Category: "setup", Regex: `(?i)set ?up|install|pull|login|cache|download|provision|create cluster`},
+	{Category: "build", Regex: `(?i)build|compile|make`},
+	{Category: "test", Regex: `(?i)test|e2e|conformance|check|verify|lint|run`},
+}
+
+// overheadCategories are the categories that do not build or test anything.
+var overheadCategories = []string{"checkout", "setup", "teardown"}
+
+type stepCategorizer struct {
+	// categories are in the order of the rules, followed by otherCategory.
+	categories []string
+	regexps    []*regexp.Regexp
+	rules      []stepCategoryRule
+}
+
+func newStepCategorizer(rules []stepCategoryRule) (*stepCategorizer, error) {
+	if len(rules) == 0 {
+		rules = defaultStepCategoryRules
+	}
+	c := &stepCategorizer{rules: rules}
+	for _, rule := range rules {
+		r, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex for step category %s: %w", rule.Category, err)
+		}
+		c.regexps = append(c.regexps, r)
+	}
+	// Display the categories in the order of a typical job rather than the order of the
+	// rules, which is about precedence.
+	for _, category := range []string{"checkout", "setup", "build", "test", "teardown"} {
+		if slices.ContainsFunc(rules, func(rule stepCategoryRule) bool { return rule.Category == category }) {
+			c.categories = append(c.categories, category)
+		}
+	}
+	for _, rule := range rules {
+		if !slices.Contains(c.categories, rule.Category) {
+			c.categories = append(c.categories, rule.Category)
+		}
+	}
+	c.categories = append(c.categories, otherCategory)
+	return c, nil
+}
+
+func (c *stepCategorizer) categorize(step string) string {
+	for i, r := range c.regexps {
+		if r.MatchString(step) {
+			return c.rules[i].Category
+		}
+	}
+	return otherCategory
+}
+
+type categoryStats struct {
+	workflow string
+	runs     int
+	total    time.Duration
+	// durations is the time spent in the steps of each category.
+	durations map[string]time.Duration
+}
+
+// getCategoryStats sums the durations of the steps of all the jobs by category.
+func getCategoryStats(c *stepCategorizer, workflow string, runs []*github.WorkflowRun, jobs map[int64][]*github.WorkflowJob) categoryStats {
+	stats := categoryStats{workflow: workflow, durations: map[string]time.Duration{}}
+	for _, run := range runs {
+		runJobs, ok := jobs[run.GetID()]
+		if !ok {
+			continue
+		}
+		stats.runs++
+		for _, job := range runJobs {
+			for _, step := range job.Steps {
+				if step.StartedAt == nil || step.CompletedAt == nil {
+					continue
+				}
+				d := step.GetCompletedAt().Sub(step.GetStartedAt().Time)
+				stats.durations[c.categorize(step.GetName())] += d
+				stats.total += d
+			}
+		}
+	}
+	return stats
+}
+
+// printStepCategories prints the share of step time spent in each category per workflow,
+// and how much of it is overhead rather than building and testing.
+func printStepCategories(ctx context.Context, client *github.Client, cfg *config, owner, repo string, result map[string][]*github.WorkflowRun) error {
+	c, err := newStepCategorizer(cfg.StepCategories)
+	if err != nil {
+		return err
+	}
+	var statsList []categoryStats
+	for workflow, runs := range result {
+		stats := getCategoryStats(c, workflow, runs, dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, runs, numWorkers))
+		if stats.total > 0 {
+			statsList = append(statsList, stats)
+		}
+	}
+	overhead := func(stats categoryStats) time.Duration {
+		var d time.Duration
+		for _, category := range overheadCategories {
+			d += stats.durations[category]
+		}
+		return d
+	}
+	slices.SortFunc(statsList, func(a, b categoryStats) int {
+		return cmp.Or(cmp.Compare(float64(overhead(b))/float64(b.total), float64(overhead(a))/float64(a.total)), cmp.Compare(a.workflow, b.workflow))
+	})
+	percent := func(d, total time.Duration) string {
+		return fmt.Sprintf("%.0f%%", 100*float64(d)/float64(total))
+	}
+	color.New(color.Bold).Println("\nstep time by category")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, fmt.Sprintf("average per run\t%s\toverhead\tworkflow", strings.Join(c.categories, "\t")))
+	for _, stats := range statsList {
+		var shares []string
+		for _, category := range c.categories {
+			shares = append(shares, percent(stats.durations[category], stats.total))
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%s",
+			(stats.total/time.Duration(stats.runs)).Round(time.Second),
+			strings.Join(shares, "\t"),
+			color.New(color.FgYellow).Sprint(percent(overhead(stats), stats.total)),
+			stats.workflow,
+		))
+	}
+	w.Flush()
+	return nil
+}
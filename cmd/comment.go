@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+// commentMarker identifies comments posted by ci-dashboard so that they are updated
+// instead of posting new ones.
+const commentMarker = "<!-- ci-dashboard -->"
+
+var commentCmd = &cobra.Command{
+	Use:   "comment owner repo pr-number",
+	Short: "Post the CI health summary as a pull request comment",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 3 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		number, err := strconv.Atoi(args[2])
+		if err != nil {
+			return fmt.Errorf("invalid pull request number %q: %w", args[2], err)
+		}
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		workflows, err := dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+		if err != nil {
+			return err
+		}
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+		body := commentMarker + "\n" + markdownSummary(cfg, owner, repo, branch, event, result)
+		return upsertComment(ctx, client, owner, repo, number, body)
+	},
+}
+
+// upsertComment updates the comment with commentMarker on the issue or pull request, or
+// posts a new comment if there is none.
+func upsertComment(ctx context.Context, client *github.Client, owner, repo string, number int, body string) error {
+	listOptions := github.IssueListCommentsOptions{}
+	for {
+		comments, res, err := client.Issues.ListComments(ctx, owner, repo, number, &listOptions)
+		if err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			if strings.HasPrefix(comment.GetBody(), commentMarker) {
+				_, _, err := client.Issues.EditComment(ctx, owner, repo, comment.GetID(), &github.IssueComment{Body: &body})
+				if err == nil {
+					slog.Info("Updated comment", slog.String("url", comment.GetHTMLURL()))
+				}
+				return err
+			}
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		listOptions.Page = res.NextPage
+	}
+	comment, _, err := client.Issues.CreateComment(ctx, owner, repo, number, &github.IssueComment{Body: &body})
+	if err != nil {
+		return err
+	}
+	slog.Info("Posted comment", slog.String("url", comment.GetHTMLURL()))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(commentCmd)
+
+	commentCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	commentCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	commentCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	commentCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var compareReposCmd = &cobra.Command{
+	Use:   "compare-repos ownerA/repoA ownerB/repoB",
+	Short: "Compare a workflow across two repositories",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		workflowA, err := cmd.Flags().GetString("workflow")
+		if err != nil {
+			return err
+		}
+		workflowB, err := cmd.Flags().GetString("workflow-b")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		if workflowA == "" {
+			return fmt.Errorf("--workflow is required")
+		}
+		if workflowB == "" {
+			workflowB = workflowA
+		}
+		created := daysToTimeRange(days)
+		type column struct {
+			name        string
+			successRate string
+			duration    string
+			runs        int
+		}
+		var columns []column
+		for i, workflow := range []string{workflowA, workflowB} {
+			owner, repo, ok := strings.Cut(args[i], "/")
+			if !ok {
+				return fmt.Errorf("invalid repository %q: expected owner/repo", args[i])
+			}
+			runs, err := getWorkflowRuns(ctx, client, owner, repo, branch, workflow, event, numRuns, created)
+			if err != nil {
+				return err
+			}
+			c := column{name: fmt.Sprintf("%s/%s/%s", owner, repo, workflow), successRate: "N/A", duration: "N/A", runs: len(runs)}
+			if len(runs) > 0 {
+				c.successRate = fmt.Sprintf("%0.f%%", dashboard.SuccessRate(runs))
+				c.duration = dashboard.AverageSuccessDuration(runs, runDurations).String()
+			}
+			columns = append(columns, c)
+		}
+		bold := color.New(color.Bold).SprintFunc()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintln(w, fmt.Sprintf("\t%s\t%s", bold(columns[0].name), bold(columns[1].name)))
+		fmt.Fprintln(w, fmt.Sprintf("success rate\t%s\t%s", columns[0].successRate, columns[1].successRate))
+		fmt.Fprintln(w, fmt.Sprintf("average duration\t%s\t%s", columns[0].duration, columns[1].duration))
+		fmt.Fprintln(w, fmt.Sprintf("runs\t%d\t%d", columns[0].runs, columns[1].runs))
+		w.Flush()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(compareReposCmd)
+
+	compareReposCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	compareReposCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	compareReposCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	compareReposCmd.Flags().StringP("workflow", "w", "", "Workflow name (e.g. conformance.yaml)")
+	compareReposCmd.Flags().String("workflow-b", "", "Workflow name in the second repository if it differs from --workflow")
+	compareReposCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+}
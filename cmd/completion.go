@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+// registerCompletions adds dynamic completion of workflow file names to the --workflow
+// flags and the workflow arguments of the command and its subcommands, so that users
+// don't have to run list first to remember the exact file names.
+func registerCompletions(cmd *cobra.Command) {
+	if cmd.Flags().Lookup("workflow") != nil {
+		cmd.RegisterFlagCompletionFunc("workflow", completeWorkflows)
+	}
+	if strings.HasSuffix(cmd.Use, " owner repo workflow") && cmd.ValidArgsFunction == nil {
+		cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 2 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeWorkflows(cmd, args, toComplete)
+		}
+	}
+	for _, c := range cmd.Commands() {
+		registerCompletions(c)
+	}
+}
+
+// completeWorkflows lists the workflows of the repository given as the owner and repo
+// arguments, or as an owner/repo argument.
+func completeWorkflows(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var owner, repo string
+	switch {
+	case len(args) > 0 && strings.Contains(args[0], "/"):
+		owner, repo, _ = strings.Cut(args[0], "/")
+	case len(args) >= 2:
+		owner, repo = args[0], args[1]
+	default:
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	// newClient exits without a token, which would break the completion script.
+	if getToken("github") == "" {
+		cobra.CompErrorln("Set GITHUB_TOKEN environment variable or run auth login github")
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	workflows, err := dashboard.GetWorkflows(ctx, newClient().Actions, owner, repo)
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []string
+	for _, workflow := range workflows {
+		if strings.HasPrefix(workflow, toComplete) {
+			completions = append(completions, workflow)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
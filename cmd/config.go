@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// config is the configuration file specified with the --config flag.
+type config struct {
+	// Dimensions extract dimensions such as Kubernetes versions or cloud providers
+	// from workflow file names.
+	Dimensions []dimensionRule `yaml:"dimensions"`
+	// NameRules normalize job and step names before failures are aggregated.
+	NameRules []nameRule `yaml:"nameRules"`
+	// Metadata extract values such as component versions from job logs.
+	Metadata []metadataRule `yaml:"metadata"`
+	// KnownIssues mark workflows that are expected to fail.
+	KnownIssues []knownIssue `yaml:"knownIssues"`
+	// SLOs are the service level objectives checked by the slo command.
+	SLOs []slo `yaml:"slos"`
+	// Notifiers receive alerts from the daemon command.
+	Notifiers []notifierConfig `yaml:"notifiers"`
+	// Events select the event for each branch when --event auto is passed.
+	Events []eventRule `yaml:"events"`
+	// Groups categorize workflows to roll up their stats in the summary.
+	Groups []workflowGroup `yaml:"groups"`
+	// Owners map workflows and jobs to teams for --by-team. CODEOWNERS entries of the
+	// workflow files are used for workflows that match no rule.
+	Owners []ownerRule `yaml:"owners"`
+	// StepCategories classify steps for --step-categories. Defaults to
+	// defaultStepCategoryRules.
+	StepCategories []stepCategoryRule `yaml:"stepCategories"`
+	// HealthThresholds override --warn-below and --critical-below per workflow.
+	HealthThresholds []healthThreshold `yaml:"healthThresholds"`
+}
+
+// loadConfig reads the configuration file specified with the --config flag. It returns
+// an empty configuration if the flag is not set.
+func loadConfig(cmd *cobra.Command) (*config, error) {
+	filename, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return nil, err
+	}
+	cfg := &config{}
+	if filename == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	for _, k := range cfg.KnownIssues {
+		if k.Until == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, k.Until); err != nil {
+			return nil, fmt.Errorf("invalid until %q of known issue %s in %s, expected YYYY-MM-DD", k.Until, k.Issue, filename)
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+)
+
+// failureEvent is a time window in which many workflows failed, which suggests an
+// infrastructure problem rather than a bug in any single workflow.
+type failureEvent struct {
+	from      time.Time
+	to        time.Time
+	workflows []string
+}
+
+// getCorrelatedFailures returns time windows no longer than window in which at least
+// minWorkflows distinct workflows failed.
+func getCorrelatedFailures(result map[string][]*github.WorkflowRun, window time.Duration, minWorkflows int) []failureEvent {
+	type failure struct {
+		workflow string
+		at       time.Time
+	}
+	var failures []failure
+	for workflow, runs := range result {
+		for _, run := range runs {
+			if run.GetConclusion() != "success" {
+				failures = append(failures, failure{workflow: workflow, at: run.GetRunStartedAt().Time})
+			}
+		}
+	}
+	slices.SortFunc(failures, func(a, b failure) int {
+		return a.at.Compare(b.at)
+	})
+	var events []failureEvent
+	for i := 0; i < len(failures); {
+		workflows := map[string]struct{}{}
+		j := i
+		for ; j < len(failures) && failures[j].at.Sub(failures[i].at) <= window; j++ {
+			workflows[failures[j].workflow] = struct{}{}
+		}
+		if len(workflows) < minWorkflows {
+			i++
+			continue
+		}
+		event := failureEvent{from: failures[i].at, to: failures[j-1].at}
+		for workflow := range workflows {
+			event.workflows = append(event.workflows, workflow)
+		}
+		slices.Sort(event.workflows)
+		events = append(events, event)
+		i = j
+	}
+	return events
+}
+
+func printCorrelatedFailures(result map[string][]*github.WorkflowRun, window time.Duration, minWorkflows int) {
+	events := getCorrelatedFailures(result, window, minWorkflows)
+	if len(events) == 0 {
+		return
+	}
+	color.New(color.FgRed, color.Bold).Println("\ncorrelated failure events")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "from\tto\tcount\tworkflows")
+	for _, event := range events {
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%d\t%s",
+			formatTime(event.from), formatTime(event.to), len(event.workflows), strings.Join(event.workflows, ", ")))
+	}
+	w.Flush()
+}
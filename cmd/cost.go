@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+// defaultRates are the per-minute rates in USD for GitHub-hosted runners.
+var defaultRates = map[string]string{
+	"UBUNTU":  "0.008",
+	"WINDOWS": "0.016",
+	"MACOS":   "0.08",
+}
+
+var costCmd = &cobra.Command{
+	Use:   "cost owner repo",
+	Short: "Estimate billable minutes and cost",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		workflowFlag, err := cmd.Flags().GetString("workflow")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		rateFlag, err := cmd.Flags().GetStringToString("rate")
+		if err != nil {
+			return err
+		}
+		rates, err := parseRates(rateFlag)
+		if err != nil {
+			return err
+		}
+		var workflows []string
+		if workflowFlag != "" {
+			workflows = append(workflows, workflowFlag)
+		} else {
+			workflows, err = dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+			if err != nil {
+				return err
+			}
+		}
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+		printCost(getBillableMinutes(ctx, client, owner, repo, result), rates)
+		return nil
+	},
+}
+
+func parseRates(rateFlag map[string]string) (map[string]float64, error) {
+	rates := map[string]float64{}
+	for _, m := range []map[string]string{defaultRates, rateFlag} {
+		for runner, value := range m {
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rate for %s: %w", runner, err)
+			}
+			rates[strings.ToUpper(runner)] = rate
+		}
+	}
+	return rates, nil
+}
+
+// getBillableMinutes returns billable minutes keyed by workflow and runner type. Each job
+// is rounded up to the nearest minute the same way GitHub bills it.
+func getBillableMinutes(ctx context.Context, client *github.Client, owner, repo string, result map[string][]*github.WorkflowRun) map[string]map[string]int64 {
+	type task struct {
+		workflow string
+		runID    int64
+	}
+	minutes := map[string]map[string]int64{}
+	tasks := make(chan task)
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for t := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				usage, _, err := client.Actions.GetWorkflowRunUsageByID(ctx, owner, repo, t.runID)
+				if err != nil {
+					slog.Error("Failed to get workflow run usage", slog.Int64("run-id", t.runID), slog.Any("error", err))
+					continue
+				}
+				if usage.Billable == nil {
+					continue
+				}
+				mux.Lock()
+				if _, ok := minutes[t.workflow]; !ok {
+					minutes[t.workflow] = map[string]int64{}
+				}
+				for runner, bill := range *usage.Billable {
+					for _, jobRun := range bill.JobRuns {
+						minutes[t.workflow][runner] += (jobRun.GetDurationMS() + 59999) / 60000
+					}
+				}
+				mux.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	for workflow, runs := range result {
+		for _, run := range runs {
+			tasks <- task{workflow: workflow, runID: run.GetID()}
+		}
+	}
+	close(tasks)
+	wg.Wait()
+	return minutes
+}
+
+func printCost(minutes map[string]map[string]int64, rates map[string]float64) {
+	type workflowCost struct {
+		workflow string
+		minutes  int64
+		cost     float64
+	}
+	var costs []workflowCost
+	runnerMinutes := map[string]int64{}
+	for workflow, m := range minutes {
+		c := workflowCost{workflow: workflow}
+		for runner, value := range m {
+			c.minutes += value
+			c.cost += float64(value) * rates[runner]
+			runnerMinutes[runner] += value
+		}
+		costs = append(costs, c)
+	}
+	slices.SortFunc(costs, func(a, b workflowCost) int {
+		return int(b.minutes - a.minutes)
+	})
+	bold := color.New(color.Bold)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "billable minutes\testimated cost\tworkflow")
+	for _, c := range costs {
+		fmt.Fprintln(w, fmt.Sprintf("%d\t$%.2f\t%s", c.minutes, c.cost, c.workflow))
+	}
+	w.Flush()
+	var runners []string
+	for runner := range runnerMinutes {
+		runners = append(runners, runner)
+	}
+	slices.Sort(runners)
+	bold.Println("\nrunner types")
+	fmt.Fprintln(w, "runner\tbillable minutes\trate\testimated cost")
+	var totalMinutes int64
+	var totalCost float64
+	for _, runner := range runners {
+		cost := float64(runnerMinutes[runner]) * rates[runner]
+		totalMinutes += runnerMinutes[runner]
+		totalCost += cost
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%d\t$%.3f\t$%.2f", runner, runnerMinutes[runner], rates[runner], cost))
+	}
+	fmt.Fprintln(w, fmt.Sprintf("total\t%d\t\t$%.2f", totalMinutes, totalCost))
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(costCmd)
+
+	costCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	costCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	costCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	costCmd.Flags().StringP("workflow", "w", "", "Workflow name (e.g. aks-byocni.yaml)")
+	costCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	costCmd.Flags().StringToString("rate", nil, "Per-minute rate in USD by runner type (e.g. UBUNTU=0.008,MACOS=0.08)")
+}
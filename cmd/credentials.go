@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// credentialService is the service name of the tokens in the keychain.
+	credentialService = "ci-dashboard"
+	// credentialKeyIterations is the number of PBKDF2 iterations to derive the key of the
+	// credentials file from the passphrase.
+	credentialKeyIterations = 200000
+)
+
+// tokenEnvVars are the environment variables that take precedence over stored tokens.
+var tokenEnvVars = map[string]string{
+	"github": "GITHUB_TOKEN",
+	"gitlab": "GITLAB_TOKEN",
+}
+
+// credentialStore stores provider tokens.
+type credentialStore interface {
+	name() string
+	// get returns an empty token if there is none for the provider.
+	get(provider string) (string, error)
+	set(provider, token string) error
+	delete(provider string) error
+}
+
+// newCredentialStore returns the store of the given kind: keychain, file, or auto to use
+// the keychain if available.
+func newCredentialStore(kind string) (credentialStore, error) {
+	switch kind {
+	case "auto":
+		if keychain, err := newKeychainStore(); err == nil {
+			return keychain, nil
+		}
+		return newFileStore()
+	case "keychain":
+		return newKeychainStore()
+	case "file":
+		return newFileStore()
+	default:
+		return nil, fmt.Errorf("unknown credential store %q", kind)
+	}
+}
+
+// getToken returns the token of the provider from its environment variable, or from the
+// keychain or the credentials file if it is not set. It returns an empty token if none is
+// found.
+func getToken(provider string) string {
+	if token := os.Getenv(tokenEnvVars[provider]); token != "" {
+		return token
+	}
+	token, _ := getStoredToken(provider)
+	return token
+}
+
+// getStoredToken returns the token of the provider from the first store that has it, along
+// with the store.
+func getStoredToken(provider string) (string, credentialStore) {
+	for _, kind := range []string{"keychain", "file"} {
+		store, err := newCredentialStore(kind)
+		if err != nil {
+			slog.Debug("Credential store is not available", slog.String("store", kind), slog.Any("error", err))
+			continue
+		}
+		token, err := store.get(provider)
+		if err != nil {
+			slog.Warn("Failed to read token", slog.String("store", kind), slog.String("provider", provider), slog.Any("error", err))
+			continue
+		}
+		if token != "" {
+			return token, store
+		}
+	}
+	return "", nil
+}
+
+// keychainStore stores tokens in the macOS keychain with the security command, or in the
+// Secret Service on Linux with the secret-tool command.
+type keychainStore struct{}
+
+func newKeychainStore() (*keychainStore, error) {
+	var command string
+	switch runtime.GOOS {
+	case "darwin":
+		command = "security"
+	case "linux":
+		command = "secret-tool"
+	default:
+		return nil, fmt.Errorf("keychain is not supported on %s", runtime.GOOS)
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return nil, fmt.Errorf("keychain is not available: %w", err)
+	}
+	return &keychainStore{}, nil
+}
+
+func (s *keychainStore) name() string {
+	return "keychain"
+}
+
+func (s *keychainStore) get(provider string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "find-generic-password", "-s", credentialService, "-a", provider, "-w")
+	} else {
+		cmd = exec.Command("secret-tool", "lookup", "service", credentialService, "provider", provider)
+	}
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// Both commands exit with a non-zero code if the item does not exist.
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (s *keychainStore) set(provider, token string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		// Pass the token in a command on stdin rather than as an argument, which other users
+		// could see in the process list.
+		if strings.ContainsAny(token, "\"\\\n") {
+			return fmt.Errorf("token contains quotes, backslashes or newlines")
+		}
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w \"%s\"\n", credentialService, provider, token))
+	} else {
+		cmd = exec.Command("secret-tool", "store", "--label", fmt.Sprintf("%s %s token", credentialService, provider), "service", credentialService, "provider", provider)
+		cmd.Stdin = strings.NewReader(token)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (s *keychainStore) delete(provider string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "delete-generic-password", "-s", credentialService, "-a", provider)
+	} else {
+		cmd = exec.Command("secret-tool", "clear", "service", credentialService, "provider", provider)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// fileStore stores tokens in a file in the user config directory, encrypted with AES-GCM
+// and a key derived from the CI_DASHBOARD_PASSPHRASE environment variable. The file
+// contains the salt, the nonce, and the encrypted JSON object of tokens keyed by provider.
+type fileStore struct {
+	filename   string
+	passphrase string
+}
+
+func newFileStore() (*fileStore, error) {
+	passphrase := os.Getenv("CI_DASHBOARD_PASSPHRASE")
+	if passphrase == "" {
+		return nil, fmt.Errorf("set CI_DASHBOARD_PASSPHRASE environment variable to use the credentials file")
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &fileStore{filename: filepath.Join(dir, "ci-dashboard", "credentials"), passphrase: passphrase}, nil
+}
+
+func (s *fileStore) name() string {
+	return "file " + s.filename
+}
+
+func (s *fileStore) read() (map[string]string, error) {
+	tokens := map[string]string{}
+	data, err := os.ReadFile(s.filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 16 {
+		return nil, fmt.Errorf("%s is corrupted", s.filename)
+	}
+	aead, err := newCredentialCipher(s.passphrase, data[:16])
+	if err != nil {
+		return nil, err
+	}
+	data = data[16:]
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("%s is corrupted", s.filename)
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s, check CI_DASHBOARD_PASSPHRASE: %w", s.filename, err)
+	}
+	if err := json.Unmarshal(plaintext, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (s *fileStore) write(tokens map[string]string) error {
+	plaintext, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	aead, err := newCredentialCipher(s.passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data := append(salt, nonce...)
+	data = aead.Seal(data, nonce, plaintext, nil)
+	if err := os.MkdirAll(filepath.Dir(s.filename), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.filename, data, 0600)
+}
+
+func (s *fileStore) get(provider string) (string, error) {
+	tokens, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	return tokens[provider], nil
+}
+
+func (s *fileStore) set(provider, token string) error {
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	tokens[provider] = token
+	return s.write(tokens)
+}
+
+func (s *fileStore) delete(provider string) error {
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	delete(tokens, provider)
+	return s.write(tokens)
+}
+
+func newCredentialCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(credentialKey(passphrase, salt))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// credentialKey derives the AES-256 key of the credentials file from the passphrase.
+func credentialKey(passphrase string, salt []byte) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, credentialKeyIterations, 32, sha256.New)
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestCredentialKey(t *testing.T) {
+	// The key must stay the same as in earlier versions to decrypt existing credentials
+	// files.
+	want := "7f2c954f85f5934bde900ac77e9dfba6f55a39244eb24496bbac967f5ef3a251"
+	if got := hex.EncodeToString(credentialKey("correct horse battery staple", []byte("0123456789abcdef"))); got != want {
+		t.Errorf("credentialKey() = %s, want %s", got, want)
+	}
+}
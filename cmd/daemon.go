@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon owner repo",
+	Short: "Periodically evaluate alerts and send them to the notifiers in the configuration file",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			return err
+		}
+		failUnder, err := cmd.Flags().GetFloat32("fail-under")
+		if err != nil {
+			return err
+		}
+		once, err := cmd.Flags().GetBool("once")
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if len(cfg.Notifiers) == 0 {
+			return fmt.Errorf("no notifiers defined in the configuration file")
+		}
+		listen, err := cmd.Flags().GetString("listen")
+		if err != nil {
+			return err
+		}
+		webhookEvents := make(chan *github.WorkflowRunEvent, webhookQueueSize)
+		serveErr := make(chan error, 1)
+		if listen != "" {
+			secret := os.Getenv("GITHUB_WEBHOOK_SECRET")
+			if secret == "" {
+				return fmt.Errorf("set GITHUB_WEBHOOK_SECRET environment variable to verify webhooks")
+			}
+			// Listen before the first evaluation so that an address in use fails right away.
+			listener, err := net.Listen("tcp", listen)
+			if err != nil {
+				return err
+			}
+			server := &http.Server{Handler: newWebhookHandler([]byte(secret), webhookEvents)}
+			go func() {
+				if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					serveErr <- fmt.Errorf("failed to serve webhooks: %w", err)
+				}
+			}()
+			defer server.Close()
+			slog.Info("Listening for workflow_run webhooks", slog.String("address", listen))
+		}
+		// notified keeps the latest run ID each notifier successfully sent an alert of a
+		// workflow for, so that the same failure is not sent again on the next evaluation,
+		// while failed sends are retried. Notifiers are keyed by their index.
+		type notifiedKey struct {
+			notifier int
+			workflow string
+		}
+		notified := map[notifiedKey]int64{}
+		evaluate := func(result map[string][]*github.WorkflowRun) {
+			alerts := getAlerts(cfg, owner, repo, branch, event, result, failUnder)
+			sent := 0
+			for i, n := range cfg.Notifiers {
+				var pending []alert
+				for _, a := range alerts {
+					if n.Filter.match(a) && notified[notifiedKey{i, a.Workflow}] != result[a.Workflow][0].GetID() {
+						pending = append(pending, a)
+					}
+				}
+				if len(pending) == 0 {
+					continue
+				}
+				if err := notify(ctx, n, pending); err != nil {
+					slog.Error("Failed to send alerts", slog.Any("error", err))
+					continue
+				}
+				for _, a := range pending {
+					notified[notifiedKey{i, a.Workflow}] = result[a.Workflow][0].GetID()
+				}
+				sent += len(pending)
+			}
+			slog.Info("Evaluated alerts", slog.Int("alerts", len(alerts)), slog.Int("sent", sent))
+		}
+		result := map[string][]*github.WorkflowRun{}
+		for {
+			workflows, err := dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+			if err != nil {
+				slog.Error("Failed to get workflows", slog.Any("error", err))
+			} else {
+				result = getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+				evaluate(result)
+			}
+			if once {
+				return nil
+			}
+			// Between the full evaluations, only the workflow of each webhook is refreshed.
+			next := time.After(interval)
+		wait:
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case err := <-serveErr:
+					return err
+				case <-next:
+					break wait
+				case e := <-webhookEvents:
+					run := e.GetWorkflowRun()
+					if e.GetRepo().GetFullName() != owner+"/"+repo || run.GetHeadBranch() != branch || run.GetEvent() != event {
+						continue
+					}
+					workflow := path.Base(e.GetWorkflow().GetPath())
+					runs, err := getWorkflowRuns(ctx, client, owner, repo, branch, workflow, event, numRuns, daysToTimeRange(days))
+					if err != nil {
+						slog.Error("Failed to get workflow runs", slog.String("workflow", workflow), slog.Any("error", err))
+						continue
+					}
+					result[workflow] = runs
+					evaluate(result)
+				}
+			}
+		}
+	},
+}
+
+// getAlerts returns alerts for workflows whose latest run failed or whose success rate is
+// below failUnder percent. Workflows with an active known issue are excluded.
+func getAlerts(cfg *config, owner, repo, branch, event string, result map[string][]*github.WorkflowRun, failUnder float32) []alert {
+	var alerts []alert
+	for workflow, runs := range result {
+		if len(runs) == 0 || cfg.getKnownIssue(workflow) != nil {
+			continue
+		}
+		rate := dashboard.SuccessRate(runs)
+		streak := dashboard.FailureStreak(runs)
+		if streak == 0 && rate >= failUnder {
+			continue
+		}
+		message := fmt.Sprintf("success rate %0.f%%", rate)
+		if streak > 0 {
+			message = fmt.Sprintf("%d consecutive failures, %s", streak, message)
+		}
+		alerts = append(alerts, alert{
+			Owner:    owner,
+			Repo:     repo,
+			Workflow: workflow,
+			URL: fmt.Sprintf("https://github.com/%s/%s/actions/workflows/%s?query=branch%%3A%s+event%%3A%s++",
+				owner, repo, workflow, branch, event),
+			SuccessRate: rate,
+			Streak:      streak,
+			Message:     message,
+		})
+	}
+	slices.SortFunc(alerts, func(a, b alert) int {
+		return b.Streak - a.Streak
+	})
+	return alerts
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+
+	daemonCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	daemonCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	daemonCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	daemonCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	daemonCmd.Flags().Duration("interval", time.Hour, "Interval between evaluations")
+	daemonCmd.Flags().Float32("fail-under", 80, "Alert if a workflow's success rate is below this percentage")
+	daemonCmd.Flags().Bool("once", false, "Evaluate alerts once and exit")
+	daemonCmd.Flags().String("listen", "", "Address to receive workflow_run webhooks on (e.g. :8080), to evaluate alerts as soon as runs complete. The secret is read from GITHUB_WEBHOOK_SECRET")
+}
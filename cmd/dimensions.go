@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+)
+
+// dimensionRule extracts a dimension value from workflow file names. The value is the
+// first capture group of the regular expression, or the whole match if the expression
+// has no capture groups.
+type dimensionRule struct {
+	Name  string `yaml:"name"`
+	Regex string `yaml:"regex"`
+}
+
+type dimensionStats struct {
+	value     string
+	workflows int
+	success   int
+	count     int
+}
+
+func getDimensionStats(rule dimensionRule, result map[string][]*github.WorkflowRun) ([]dimensionStats, error) {
+	r, err := regexp.Compile(rule.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex for dimension %s: %w", rule.Name, err)
+	}
+	statsMap := map[string]*dimensionStats{}
+	for workflow, runs := range result {
+		match := r.FindStringSubmatch(workflow)
+		if match == nil {
+			continue
+		}
+		value := match[0]
+		if len(match) > 1 {
+			value = match[1]
+		}
+		stats, ok := statsMap[value]
+		if !ok {
+			stats = &dimensionStats{value: value}
+			statsMap[value] = stats
+		}
+		stats.workflows++
+		for _, run := range runs {
+			if run.GetConclusion() == "success" {
+				stats.success++
+			}
+			stats.count++
+		}
+	}
+	var statsList []dimensionStats
+	for _, stats := range statsMap {
+		statsList = append(statsList, *stats)
+	}
+	slices.SortFunc(statsList, func(a, b dimensionStats) int {
+		return cmp.Compare(a.value, b.value)
+	})
+	return statsList, nil
+}
+
+func printDimensions(rules []dimensionRule, result map[string][]*github.WorkflowRun) error {
+	bold := color.New(color.Bold)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	for _, rule := range rules {
+		statsList, err := getDimensionStats(rule, result)
+		if err != nil {
+			return err
+		}
+		bold.Printf("\n%s\n", rule.Name)
+		fmt.Fprintln(w, fmt.Sprintf("%s\tsuccess rate\tworkflows", rule.Name))
+		for _, stats := range statsList {
+			rate := "N/A"
+			if stats.count > 0 {
+				rate = fmt.Sprintf("%0.f%% %d/%d", 100*float32(stats.success)/float32(stats.count), stats.success, stats.count)
+			}
+			fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%d", stats.value, rate, stats.workflows))
+		}
+		w.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// createDiscussion creates a discussion in the category of the repository, and returns
+// the URL of the discussion.
+func createDiscussion(ctx context.Context, owner, repo, category, title, body string) (string, error) {
+	const query = `query($owner: String!, $repo: String!) {
+  repository(owner: $owner, name: $repo) {
+    id
+    discussionCategories(first: 100) { nodes { id name } }
+  }
+}`
+	var data struct {
+		Repository *struct {
+			ID                   string `json:"id"`
+			DiscussionCategories struct {
+				Nodes []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"nodes"`
+			} `json:"discussionCategories"`
+		} `json:"repository"`
+	}
+	if err := graphQL(ctx, query, map[string]any{"owner": owner, "repo": repo}, &data); err != nil {
+		return "", err
+	}
+	if data.Repository == nil {
+		return "", fmt.Errorf("repository %s/%s not found", owner, repo)
+	}
+	categoryID := ""
+	for _, node := range data.Repository.DiscussionCategories.Nodes {
+		if strings.EqualFold(node.Name, category) {
+			categoryID = node.ID
+		}
+	}
+	if categoryID == "" {
+		return "", fmt.Errorf("discussion category %q not found in %s/%s", category, owner, repo)
+	}
+	const mutation = `mutation($repo: ID!, $category: ID!, $title: String!, $body: String!) {
+  createDiscussion(input: {repositoryId: $repo, categoryId: $category, title: $title, body: $body}) { discussion { url } }
+}`
+	var result struct {
+		CreateDiscussion struct {
+			Discussion struct {
+				URL string `json:"url"`
+			} `json:"discussion"`
+		} `json:"createDiscussion"`
+	}
+	vars := map[string]any{"repo": data.Repository.ID, "category": categoryID, "title": title, "body": body}
+	if err := graphQL(ctx, mutation, vars, &result); err != nil {
+		return "", err
+	}
+	return result.CreateDiscussion.Discussion.URL, nil
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// durationMethods are the ways to measure the duration of runs:
+//
+//	updated   the time from the start to the last update of the run, which overestimates
+//	          runs that were re-run or updated later
+//	timing    the wall-clock duration from the timing API
+//	billable  the billable duration of all the jobs from the timing API, which is 0 for
+//	          runs of public repositories
+//	jobs      the sum of the durations of the jobs
+var durationMethods = []string{"updated", "timing", "billable", "jobs"}
+
+// runDurations are the durations measured by applyDurationMethod, which all the stats use
+// instead of the duration from the last update of the runs.
+var runDurations dashboard.Durations
+
+// applyDurationMethod measures the durations of the runs with the method, and sets them
+// as runDurations. The runs are left as is, and runs whose duration failed to be measured
+// keep the duration from their last update.
+func applyDurationMethod(ctx context.Context, client *github.Client, owner, repo, method string, result map[string][]*github.WorkflowRun) {
+	var allRuns []*github.WorkflowRun
+	for _, runs := range result {
+		allRuns = append(allRuns, runs...)
+	}
+	if method == "jobs" {
+		runDurations = getJobDurations(ctx, client, owner, repo, allRuns)
+	} else {
+		runDurations = getUsageDurations(ctx, client, owner, repo, method, allRuns)
+	}
+}
+
+// getJobDurations returns the sums of the durations of the jobs of the runs keyed by run
+// ID. Runs without any job with start and completion times are omitted.
+func getJobDurations(ctx context.Context, client *github.Client, owner, repo string, runs []*github.WorkflowRun) dashboard.Durations {
+	result := dashboard.Durations{}
+	untimed := 0
+	for runID, jobs := range dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, runs, numWorkers) {
+		var total time.Duration
+		timed := false
+		for _, job := range jobs {
+			if job.CompletedAt != nil && job.StartedAt != nil {
+				total += job.GetCompletedAt().Sub(job.GetStartedAt().Time)
+				timed = true
+			}
+		}
+		if !timed {
+			untimed++
+			continue
+		}
+		result[runID] = total
+	}
+	if untimed > 0 {
+		slog.Warn("Runs without job timings keep the duration from their last update", slog.Int("runs", untimed))
+	}
+	return result
+}
+
+// getUsageDurations fetches the wall-clock or the billable durations of the runs from the
+// timing API in parallel, and returns them keyed by run ID.
+func getUsageDurations(ctx context.Context, client *github.Client, owner, repo, method string, runs []*github.WorkflowRun) dashboard.Durations {
+	result := dashboard.Durations{}
+	unbilled := 0
+	bar := newProgress("Fetching run timings", len(runs))
+	tasks := make(chan int64)
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for runID := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				usage, _, err := client.Actions.GetWorkflowRunUsageByID(ctx, owner, repo, runID)
+				bar.increment()
+				if err != nil {
+					slog.Error("Failed to get run timing", slog.Int64("run-id", runID), slog.Any("error", err))
+					continue
+				}
+				ms := usage.GetRunDurationMS()
+				if method == "billable" {
+					ms = 0
+					if billable := usage.GetBillable(); billable != nil {
+						for _, bill := range *billable {
+							ms += bill.GetTotalMS()
+						}
+					}
+					// Runs of public repositories are free, and have no billable time.
+					if ms == 0 {
+						mux.Lock()
+						unbilled++
+						mux.Unlock()
+						continue
+					}
+				}
+				mux.Lock()
+				result[runID] = time.Duration(ms) * time.Millisecond
+				mux.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	for _, run := range runs {
+		tasks <- run.GetID()
+	}
+	close(tasks)
+	wg.Wait()
+	bar.finish()
+	if unbilled > 0 {
+		slog.Warn("Runs without billable time keep the duration from their last update, as in public repositories",
+			slog.Int("runs", unbilled))
+	}
+	return result
+}
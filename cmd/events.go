@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// eventRule selects the event for branches matching the glob pattern when --event auto
+// is passed. The pattern is matched against the whole branch name, so * also matches /.
+type eventRule struct {
+	Branch string `yaml:"branch"`
+	Event  string `yaml:"event"`
+}
+
+// defaultEventRules are used after the rules in the configuration file. Main branches
+// run scheduled workflows, release branches are tested on push, and other branches are
+// assumed to be pull request branches.
+var defaultEventRules = []eventRule{
+	{Branch: "main", Event: "schedule"},
+	{Branch: "master", Event: "schedule"},
+	{Branch: "v*", Event: "push"},
+	{Branch: "release*", Event: "push"},
+	{Branch: "*", Event: "pull_request"},
+}
+
+// getEvent returns the value of the --event flag. If it is auto, the event is selected
+// based on the --branch flag and the event rules in the configuration file.
+func getEvent(cmd *cobra.Command) (string, error) {
+	event, err := cmd.Flags().GetString("event")
+	if err != nil || event != "auto" {
+		return event, err
+	}
+	branch, err := cmd.Flags().GetString("branch")
+	if err != nil {
+		return "", err
+	}
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return "", err
+	}
+	return selectEvent(cfg, branch)
+}
+
+// selectEvent returns the event of the first event rule that matches the branch.
+func selectEvent(cfg *config, branch string) (string, error) {
+	for _, rule := range append(cfg.Events, defaultEventRules...) {
+		matched, err := matchBranch(rule.Branch, branch)
+		if err != nil {
+			return "", fmt.Errorf("invalid branch pattern %q: %w", rule.Branch, err)
+		}
+		if matched {
+			slog.Info("Selected event", slog.String("branch", branch), slog.String("event", rule.Event))
+			return rule.Event, nil
+		}
+	}
+	return "", fmt.Errorf("no event rule matches branch %q", branch)
+}
+
+// matchBranch reports whether the branch matches the glob pattern. Unlike path.Match, *
+// and ? also match /, so that release* matches release/1.0.
+func matchBranch(pattern, branch string) (bool, error) {
+	// path.Match only treats / as a separator, so it is replaced with a character that
+	// can't appear in branch names.
+	const separator = "\x00"
+	return path.Match(strings.ReplaceAll(pattern, "/", separator), strings.ReplaceAll(branch, "/", separator))
+}
+
+// sensitiveEvents maps security-sensitive events to a description of their risk profile.
+var sensitiveEvents = map[string]string{
+	"pull_request_target": "Runs in the context of the base repository with a read/write token and access to secrets, " +
+		"even for pull requests from forks. Checking out and running the pull request code is a privilege escalation.",
+	"issue_comment": "Can be triggered by anyone who can comment on an issue or pull request. " +
+		"Workflows must check the commenter's permissions before acting on the comment.",
+	"pull_request_review": "Can be triggered by anyone who can review a pull request. " +
+		"Untrusted review content must not be interpolated into scripts.",
+	"pull_request_review_comment": "Can be triggered by anyone who can comment on a pull request diff. " +
+		"Untrusted comment content must not be interpolated into scripts.",
+	"workflow_run": "Runs with access to secrets after another workflow completes, including workflows triggered by forks. " +
+		"Artifacts downloaded from the triggering run are untrusted.",
+	"issues": "Can be triggered by anyone who can open an issue. " +
+		"Untrusted issue titles and bodies must not be interpolated into scripts.",
+	"discussion_comment": "Can be triggered by anyone who can comment on a discussion. " +
+		"Untrusted comment content must not be interpolated into scripts.",
+}
+
+// printEventWarning prints the risk profile of the event if it is security-sensitive.
+func printEventWarning(event string) {
+	risk, ok := sensitiveEvents[event]
+	if !ok {
+		return
+	}
+	yellow := color.New(color.FgYellow, color.Bold)
+	yellow.Printf("warning: %s is a security-sensitive event\n", event)
+	fmt.Printf("%s\n\n", risk)
+}
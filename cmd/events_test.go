@@ -0,0 +1,28 @@
+package cmd
+
+import "testing"
+
+func TestSelectEvent(t *testing.T) {
+	cfg := &config{Events: []eventRule{{Branch: "ft/*", Event: "push"}}}
+	for _, tt := range []struct {
+		branch string
+		want   string
+	}{
+		{"main", "schedule"},
+		{"v1.15", "push"},
+		{"release/1.0", "push"},
+		{"release-1.0", "push"},
+		{"ft/main/ipv6", "push"},
+		{"feature/x", "pull_request"},
+		{"pr/user/fix", "pull_request"},
+	} {
+		got, err := selectEvent(cfg, tt.branch)
+		if err != nil {
+			t.Errorf("selectEvent(%q) returned error: %v", tt.branch, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("selectEvent(%q) = %q, want %q", tt.branch, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export owner repo",
+	Short: "Export raw run, job, and step records",
+	Long: `Export raw run, job, and step records for offline analysis.
+
+The command does not write database or Parquet files itself. The sqlite-script format
+writes a script of SQLite statements, which creates a database when loaded with:
+
+    sqlite3 ci.db < export.sql
+
+The csv format writes runs.csv, jobs.csv, and steps.csv to the output directory, which
+can be loaded with pandas.read_csv or converted to Parquet. The columns of the tables
+follow the versioned JSON schema printed by --schema.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := cmd.Flags().GetBool("schema")
+		if err != nil {
+			return err
+		}
+		if schema {
+			fmt.Print(exportSchema)
+			return nil
+		}
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		workflowFlag, err := cmd.Flags().GetString("workflow")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		var workflows []string
+		if workflowFlag != "" {
+			workflows = append(workflows, workflowFlag)
+		} else {
+			workflows, err = dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+			if err != nil {
+				return err
+			}
+		}
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+		tables := getExportTables(ctx, client, owner, repo, result)
+		switch format {
+		case "sqlite-script":
+			out := os.Stdout
+			if output != "" {
+				out, err = os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer out.Close()
+			}
+			return writeSQL(out, tables)
+		case "csv":
+			if output == "" {
+				output = "."
+			}
+			return writeCSV(output, tables)
+		default:
+			return fmt.Errorf("unknown format %q, expected one of sqlite-script, csv", format)
+		}
+	},
+}
+
+// exportSchema is the JSON schema of the rows of the exported tables, printed by
+// export --schema.
+//
+//go:embed schemas/export-v1.json
+var exportSchema string
+
+// exportTable is a table of records. Values are either strings or int64.
+type exportTable struct {
+	name    string
+	columns []string
+	// types are SQLite column types.
+	types []string
+	rows  [][]any
+}
+
+// newExportTables returns the empty runs, jobs, and steps tables.
+func newExportTables() (runs, jobs, steps *exportTable) {
+	runs = &exportTable{
+		name:    "runs",
+		columns: []string{"id", "workflow", "head_sha", "head_branch", "event", "conclusion", "attempt", "actor", "created_at", "started_at", "updated_at", "url"},
+		types:   []string{"INTEGER PRIMARY KEY", "TEXT", "TEXT", "TEXT", "TEXT", "TEXT", "INTEGER", "TEXT", "TEXT", "TEXT", "TEXT", "TEXT"},
+	}
+	jobs = &exportTable{
+		name:    "jobs",
+		columns: []string{"id", "run_id", "name", "conclusion", "runner_name", "labels", "created_at", "started_at", "completed_at", "url"},
+		types:   []string{"INTEGER PRIMARY KEY", "INTEGER", "TEXT", "TEXT", "TEXT", "TEXT", "TEXT", "TEXT", "TEXT", "TEXT"},
+	}
+	steps = &exportTable{
+		name:    "steps",
+		columns: []string{"job_id", "number", "name", "conclusion", "started_at", "completed_at"},
+		types:   []string{"INTEGER", "INTEGER", "TEXT", "TEXT", "TEXT", "TEXT"},
+	}
+	return runs, jobs, steps
+}
+
+func getExportTables(ctx context.Context, client *github.Client, owner, repo string, result map[string][]*github.WorkflowRun) []*exportTable {
+	runs, jobs, steps := newExportTables()
+	var allRuns []*github.WorkflowRun
+	for workflow, workflowRuns := range result {
+		for _, run := range workflowRuns {
+			allRuns = append(allRuns, run)
+			runs.rows = append(runs.rows, []any{
+				run.GetID(), workflow, run.GetHeadSHA(), run.GetHeadBranch(), run.GetEvent(), run.GetConclusion(),
+				int64(run.GetRunAttempt()), run.GetActor().GetLogin(), formatTimestamp(run.CreatedAt),
+				formatTimestamp(run.RunStartedAt), formatTimestamp(run.UpdatedAt), run.GetHTMLURL(),
+			})
+		}
+	}
+	for runID, runJobs := range dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, allRuns, numWorkers) {
+		for _, job := range runJobs {
+			jobs.rows = append(jobs.rows, []any{
+				job.GetID(), runID, job.GetName(), job.GetConclusion(), job.GetRunnerName(), strings.Join(job.Labels, ","),
+				formatTimestamp(job.CreatedAt), formatTimestamp(job.StartedAt), formatTimestamp(job.CompletedAt), job.GetHTMLURL(),
+			})
+			for _, step := range job.Steps {
+				steps.rows = append(steps.rows, []any{
+					job.GetID(), step.GetNumber(), step.GetName(), step.GetConclusion(),
+					formatTimestamp(step.StartedAt), formatTimestamp(step.CompletedAt),
+				})
+			}
+		}
+	}
+	return []*exportTable{runs, jobs, steps}
+}
+
+func formatTimestamp(t *github.Timestamp) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func writeSQL(out io.Writer, tables []*exportTable) error {
+	if _, err := fmt.Fprintln(out, "BEGIN TRANSACTION;"); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		var columns []string
+		for i, column := range table.columns {
+			columns = append(columns, column+" "+table.types[i])
+		}
+		if _, err := fmt.Fprintf(out, "CREATE TABLE IF NOT EXISTS %s (%s);\n", table.name, strings.Join(columns, ", ")); err != nil {
+			return err
+		}
+		for _, row := range table.rows {
+			var values []string
+			for _, value := range row {
+				switch v := value.(type) {
+				case int64:
+					values = append(values, strconv.FormatInt(v, 10))
+				default:
+					values = append(values, "'"+strings.ReplaceAll(fmt.Sprint(v), "'", "''")+"'")
+				}
+			}
+			if _, err := fmt.Fprintf(out, "INSERT OR REPLACE INTO %s VALUES (%s);\n", table.name, strings.Join(values, ", ")); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintln(out, "COMMIT;")
+	return err
+}
+
+func writeCSV(dir string, tables []*exportTable) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		f, err := os.Create(filepath.Join(dir, table.name+".csv"))
+		if err != nil {
+			return err
+		}
+		w := csv.NewWriter(f)
+		w.Write(table.columns)
+		for _, row := range table.rows {
+			record := make([]string, len(row))
+			for i, value := range row {
+				record[i] = fmt.Sprint(value)
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	exportCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	exportCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	exportCmd.Flags().StringP("workflow", "w", "", "Workflow name (e.g. aks-byocni.yaml)")
+	exportCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	exportCmd.Flags().StringP("format", "f", "sqlite-script", "Export format (sqlite-script, csv)")
+	exportCmd.Flags().StringP("output", "o", "", "Output file for sqlite-script format (default stdout), or output directory for csv format (default current directory)")
+	exportCmd.Flags().Bool("schema", false, "Print the JSON schema of the rows of the exported tables, and exit")
+}
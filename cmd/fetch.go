@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var fetchCmd = &cobra.Command{
+	Use:   "fetch owner repo",
+	Short: "Fetch workflow runs, jobs, and logs into a bundle for offline analysis",
+	Long: `Fetch workflow runs, jobs, and logs of failed jobs into a bundle for offline analysis.
+
+Copy the bundle to the analysis machine and pass it with --from-bundle to the show, runs,
+export, and other commands. The commands must be run with the same branch, event, number,
+and workflow flags as the fetch command, because only the fetched responses are available.
+The GraphQL API is not supported offline.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		workflowFlag, err := cmd.Flags().GetString("workflow")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		recorder := newRecordingTransport(httpClient.Transport)
+		httpClient.Transport = recorder
+		client := newClient()
+		// Workflows are always fetched so that the bundle also works without the workflow
+		// flag for the workflows it contains.
+		workflows, err := dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+		if err != nil {
+			return err
+		}
+		if workflowFlag != "" {
+			workflows = []string{workflowFlag}
+		}
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+		var allRuns []*github.WorkflowRun
+		var jobLogs []jobLog
+		for _, runs := range result {
+			allRuns = append(allRuns, runs...)
+			jobLogs = append(jobLogs, getFailureDetails(ctx, client, owner, repo, runs).jobLogs...)
+		}
+		dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, allRuns, numWorkers)
+		analyzeLogs(ctx, jobLogs, 0)
+		// Also fetch what the show command needs besides runs, jobs, and logs.
+		if _, err := getRemovedWorkflowRuns(ctx, client, owner, repo, branch, event, numRuns, daysToTimeRange(days)); err != nil {
+			slog.Error("Failed to get removed workflows", slog.Any("error", err))
+		}
+		if event == "schedule" {
+			getSchedulesForWorkflows(ctx, client, owner, repo, branch, workflows)
+		}
+		if err := recorder.write(output); err != nil {
+			return err
+		}
+		slog.Info("Wrote bundle", slog.String("file", output),
+			slog.Int("runs", len(allRuns)), slog.Int("responses", len(recorder.bundle.Responses)))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fetchCmd)
+
+	fetchCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	fetchCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	fetchCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to fetch")
+	fetchCmd.Flags().StringP("workflow", "w", "", "Workflow name (e.g. aks-byocni.yaml)")
+	fetchCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	fetchCmd.Flags().StringP("output", "o", "bundle.json.gz", "Output file of the bundle")
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+type firstFailureStats struct {
+	workflow string
+	failed   int
+	average  time.Duration
+	median   time.Duration
+	// averageDuration is the average duration of the failed runs.
+	averageDuration time.Duration
+}
+
+// getTimeToFirstFailure returns how long each failed run took until its first job failed.
+func getTimeToFirstFailure(ctx context.Context, client *github.Client, owner, repo string, runs []*github.WorkflowRun) []time.Duration {
+	var failedRuns []*github.WorkflowRun
+	for _, run := range runs {
+		if run.GetConclusion() == "failure" {
+			failedRuns = append(failedRuns, run)
+		}
+	}
+	jobs := dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, failedRuns, numWorkers)
+	var result []time.Duration
+	for _, run := range failedRuns {
+		var firstFailure time.Time
+		for _, job := range jobs[run.GetID()] {
+			if job.GetConclusion() != "failure" {
+				continue
+			}
+			if firstFailure.IsZero() || job.GetCompletedAt().Before(firstFailure) {
+				firstFailure = job.GetCompletedAt().Time
+			}
+		}
+		if !firstFailure.IsZero() {
+			result = append(result, firstFailure.Sub(run.GetRunStartedAt().Time))
+		}
+	}
+	return result
+}
+
+func printTimeToFirstFailure(ctx context.Context, client *github.Client, owner, repo string, result map[string][]*github.WorkflowRun) {
+	var statsList []firstFailureStats
+	for workflow, runs := range result {
+		durations := getTimeToFirstFailure(ctx, client, owner, repo, runs)
+		if len(durations) == 0 {
+			continue
+		}
+		slices.Sort(durations)
+		var total, totalRunDuration time.Duration
+		for _, d := range durations {
+			total += d
+		}
+		failed := 0
+		for _, run := range runs {
+			if run.GetConclusion() == "failure" {
+				failed++
+				totalRunDuration += runDurations.Of(run)
+			}
+		}
+		statsList = append(statsList, firstFailureStats{
+			workflow:        workflow,
+			failed:          failed,
+			average:         (total / time.Duration(len(durations))).Round(time.Second),
+			median:          durations[len(durations)/2].Round(time.Second),
+			averageDuration: (totalRunDuration / time.Duration(failed)).Round(time.Second),
+		})
+	}
+	slices.SortFunc(statsList, func(a, b firstFailureStats) int {
+		return int(b.average - a.average)
+	})
+	color.New(color.FgRed, color.Bold).Println("\ntime to first failure")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "average\tmedian\taverage failed run duration\tfailed runs\tworkflow")
+	for _, stats := range statsList {
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%d\t%s",
+			stats.average, stats.median, stats.averageDuration, stats.failed, stats.workflow))
+	}
+	w.Flush()
+}
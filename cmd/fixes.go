@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+)
+
+// recovery is a pair of the last failed run and the first successful run after it.
+type recovery struct {
+	lastRed    *github.WorkflowRun
+	firstGreen *github.WorkflowRun
+}
+
+// getRecoveries returns the points where the workflow went from failure to success.
+// Runs are expected to be sorted from newest to oldest.
+func getRecoveries(runs []*github.WorkflowRun) []recovery {
+	var recoveries []recovery
+	for i := 0; i+1 < len(runs); i++ {
+		if runs[i].GetConclusion() == "success" && runs[i+1].GetConclusion() == "failure" &&
+			runs[i].GetHeadSHA() != runs[i+1].GetHeadSHA() {
+			recoveries = append(recoveries, recovery{lastRed: runs[i+1], firstGreen: runs[i]})
+		}
+	}
+	return recoveries
+}
+
+// printProbableFixes prints pull requests merged between the last failed run and the
+// first successful run of each recovery as probable fixes.
+func printProbableFixes(ctx context.Context, client *github.Client, owner, repo string, runs []*github.WorkflowRun) {
+	green := color.New(color.FgGreen, color.Bold)
+	link := color.New(color.FgCyan, color.Underline).SprintFunc()
+	green.Println("\nprobable fixes")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "recovered at\tpull request\ttitle\tlink")
+	for _, r := range getRecoveries(runs) {
+		prs, err := getMergedPullRequests(ctx, client, owner, repo, r.lastRed.GetHeadSHA(), r.firstGreen.GetHeadSHA())
+		if err != nil {
+			slog.Error("Failed to get merged pull requests", slog.Any("error", err))
+			continue
+		}
+		for _, pr := range prs {
+			fmt.Fprintln(w, fmt.Sprintf("%s\t#%d\t%s\t%s",
+				formatTime(r.firstGreen.GetRunStartedAt().Time), pr.GetNumber(), pr.GetTitle(), link(pr.GetHTMLURL())))
+		}
+	}
+	w.Flush()
+}
@@ -2,78 +2,275 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"os"
 	"path"
 	"slices"
+	"sync"
 
 	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
 )
 
-func getWorkflows(ctx context.Context, client *github.Client, owner, repo string) ([]string, error) {
-	listOptions := github.ListOptions{}
-	var workflows []*github.Workflow
+// newClient returns a GitHub client authenticated with the GITHUB_TOKEN environment
+// variable, or the token stored with auth login. It exits if there is no token.
+func newClient() *github.Client {
+	// No token is needed to replay a bundle.
+	if _, ok := httpClient.Transport.(*replayTransport); ok {
+		return github.NewClient(httpClient)
+	}
+	token := getToken("github")
+	if token == "" {
+		slog.Error("Set GITHUB_TOKEN environment variable or run auth login github")
+		os.Exit(1)
+	}
+	return github.NewClient(httpClient).WithAuthToken(token)
+}
+
+// getOrgRepos returns the names of non-archived repositories in the organization that
+// match any of the include patterns (or all if empty) and none of the exclude patterns.
+func getOrgRepos(ctx context.Context, client *github.Client, org string, include, exclude []string) ([]string, error) {
+	listOptions := github.RepositoryListByOrgOptions{}
+	var repos []string
 	for {
-		wf, res, err := client.Actions.ListWorkflows(ctx, owner, repo, &listOptions)
+		page, res, err := client.Repositories.ListByOrg(ctx, org, &listOptions)
 		if err != nil {
 			return nil, err
 		}
-		workflows = append(workflows, wf.Workflows...)
+		for _, repo := range page {
+			if repo.GetArchived() {
+				continue
+			}
+			if (len(include) == 0 || matchAny(include, repo.GetName())) && !matchAny(exclude, repo.GetName()) {
+				repos = append(repos, repo.GetName())
+			}
+		}
 		if res.NextPage == 0 {
 			break
 		}
 		listOptions.Page = res.NextPage
 	}
-	var filepaths []string
-	for _, workflow := range workflows {
-		filepaths = append(filepaths, path.Base(workflow.GetPath()))
+	slices.Sort(repos)
+	return repos, nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
 	}
-	slices.Sort(filepaths)
-	return filepaths, nil
+	return false
 }
 
+// countedConclusions are the run conclusions included in the stats. Runs with other
+// conclusions, such as cancelled, are ignored unless added with --include-conclusions.
+var countedConclusions = slices.Clone(dashboard.DefaultConclusions)
+
+// optionalConclusions are the conclusions that can be added to countedConclusions.
+var optionalConclusions = []string{"cancelled", "timed_out", "action_required", "skipped"}
+
+// limitMode is how the number of runs and the days limits are combined. In the and mode,
+// up to the number of runs within the days are fetched. In the or mode, the latest number
+// of runs and all the runs within the days are fetched.
+var limitMode = "and"
+
+var (
+	allRunsFetchedMu sync.Mutex
+	// allRunsFetched are the workflows whose runs were fewer than the number of runs in the
+	// and limit mode because there are no older runs outside of the days, keyed by
+	// workflow file name. It tells limitedBy that the days did not limit the runs.
+	allRunsFetched = map[string]bool{}
+)
+
+var (
+	excludedRunsMu sync.Mutex
+	// excludedRuns are the fetched runs whose conclusions are not counted, keyed by
+	// workflow file name, so that the conclusion breakdown can show them.
+	excludedRuns = map[string][]*github.WorkflowRun{}
+)
+
 func getWorkflowRuns(ctx context.Context, client *github.Client, owner, repo, branch, workflow, event string, count int, created string) ([]*github.WorkflowRun, error) {
-	listOptions := github.ListWorkflowRunsOptions{
+	opts := runOptions(branch, event, count, created)
+	var excluded []*github.WorkflowRun
+	opts.Excluded = func(run *github.WorkflowRun) {
+		excluded = append(excluded, run)
+	}
+	runs, err := dashboard.GetWorkflowRuns(ctx, client.Actions, owner, repo, workflow, opts)
+	if successCriteria != nil {
+		dashboard.ApplySuccessCriteria(ctx, client.Actions, owner, repo, runs, successCriteria, numWorkers)
+	}
+	excludedRunsMu.Lock()
+	excludedRuns[workflow] = excluded
+	excludedRunsMu.Unlock()
+	if err != nil || limitMode != "and" || count == dashboard.AllRuns || len(runs) >= count {
+		return runs, err
+	}
+	if from, _ := dashboard.SplitTimeRange(created); from != "" {
+		older, err := hasRunsBefore(ctx, client, owner, repo, branch, workflow, event, from)
+		if err != nil {
+			slog.Debug("Failed to check for older runs", slog.String("workflow", workflow), slog.Any("error", err))
+		} else if !older {
+			allRunsFetchedMu.Lock()
+			allRunsFetched[workflow] = true
+			allRunsFetchedMu.Unlock()
+		}
+	}
+	return runs, nil
+}
+
+// hasRunsBefore returns true if the workflow has runs created before the time, regardless
+// of their conclusions.
+func hasRunsBefore(ctx context.Context, client *github.Client, owner, repo, branch, workflow, event, before string) (bool, error) {
+	if event == "merge_group" {
+		// The head branches of merge_group runs are temporary merge queue branches.
+		branch = ""
+	}
+	runs, _, err := client.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, workflow, &github.ListWorkflowRunsOptions{
 		Branch:      branch,
 		Event:       event,
+		Created:     "<" + before,
+		ListOptions: github.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		return false, err
+	}
+	return runs.GetTotalCount() > 0, nil
+}
+
+// listWorkflowRuns returns up to count runs with counted conclusions using the given list
+// function, which is called with each page of the list options.
+func listWorkflowRuns(branch, event string, count int, created string, list func(*github.ListWorkflowRunsOptions) (*github.WorkflowRuns, *github.Response, error)) ([]*github.WorkflowRun, error) {
+	return dashboard.ListWorkflowRuns(runOptions(branch, event, count, created), list)
+}
+
+// runOptions returns the options to fetch up to count runs with the limit mode and the
+// counted conclusions selected by the flags.
+func runOptions(branch, event string, count int, created string) dashboard.RunOptions {
+	return dashboard.RunOptions{
+		Branch:      branch,
+		Event:       event,
+		Count:       count,
 		Created:     created,
-		ListOptions: github.ListOptions{},
+		LimitMode:   limitMode,
+		Conclusions: countedConclusions,
 	}
-	var workflowRuns []*github.WorkflowRun
-	for {
-		runs, res, err := client.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, workflow, &listOptions)
-		if err != nil {
-			return workflowRuns, err
+}
+
+// getRemovedWorkflowRuns returns runs of workflows whose files were deleted, keyed by the
+// workflow file name.
+func getRemovedWorkflowRuns(ctx context.Context, client *github.Client, owner, repo, branch, event string, count int, created string) (map[string][]*github.WorkflowRun, error) {
+	workflows, err := dashboard.ListWorkflows(ctx, client.Actions, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string][]*github.WorkflowRun{}
+	for _, workflow := range workflows {
+		if workflow.GetState() != "deleted" {
+			continue
 		}
-		for _, run := range runs.WorkflowRuns {
-			if run.GetConclusion() == "success" || run.GetConclusion() == "failure" {
-				workflowRuns = append(workflowRuns, run)
-			}
+		runs, err := listWorkflowRuns(branch, event, count, created, func(opts *github.ListWorkflowRunsOptions) (*github.WorkflowRuns, *github.Response, error) {
+			return client.Actions.ListWorkflowRunsByID(ctx, owner, repo, workflow.GetID(), opts)
+		})
+		if err != nil {
+			slog.Error("Failed to get workflow runs", slog.String("workflow", workflow.GetPath()), slog.Any("error", err))
+			continue
 		}
-		if res.NextPage == 0 || len(workflowRuns) >= count {
-			break
+		if len(runs) > 0 {
+			result[path.Base(workflow.GetPath())] = runs
 		}
-		listOptions.Page = res.NextPage
 	}
-	if len(workflowRuns) > count {
-		return workflowRuns[:count], nil
+	return result, nil
+}
+
+// getWorkflowRunsForWorkflows fetches workflow runs for the given workflows in parallel,
+// and returns them keyed by workflow file name. Workflows that failed to fetch are logged
+// and omitted.
+func getWorkflowRunsForWorkflows(ctx context.Context, client *github.Client, owner, repo, branch string, workflows []string, event string, count int, created string) map[string][]*github.WorkflowRun {
+	result, failures := fetchWorkflowRuns(ctx, githubProvider{client}, owner, repo, branch, workflows, event, count, created)
+	for workflow, err := range failures {
+		slog.Error("Failed to get workflow runs", slog.String("workflow", workflow), slog.Any("error", err))
+	}
+	return result
+}
+
+// fetchWorkflowRuns is like getWorkflowRunsForWorkflows, but also returns the errors of
+// the workflows that failed to fetch, keyed by workflow file name. Deleted workflows are
+// skipped without an error.
+func fetchWorkflowRuns(ctx context.Context, p provider, owner, repo, branch string, workflows []string, event string, count int, created string) (map[string][]*github.WorkflowRun, map[string]error) {
+	tasks := make(chan string)
+	result := map[string][]*github.WorkflowRun{}
+	failures := map[string]error{}
+	bar := newProgress("Fetching workflow runs", len(workflows))
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for workflow := range tasks {
+				// Drain the remaining tasks without requests once the context is cancelled.
+				if ctx.Err() != nil {
+					mux.Lock()
+					failures[workflow] = ctx.Err()
+					mux.Unlock()
+					continue
+				}
+				runs, err := p.getWorkflowRuns(ctx, owner, repo, branch, workflow, event, count, created)
+				bar.increment()
+				if errors.Is(err, dashboard.ErrWorkflowNotFound) {
+					slog.Debug("Skipping workflow", slog.Any("error", err))
+					continue
+				}
+				mux.Lock()
+				if err != nil {
+					failures[workflow] = err
+				} else {
+					result[workflow] = runs
+				}
+				mux.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	for _, workflow := range workflows {
+		tasks <- workflow
 	}
-	return workflowRuns, nil
+	close(tasks)
+	wg.Wait()
+	bar.finish()
+	return result, failures
 }
 
-func getJobs(ctx context.Context, client *github.Client, owner, repo string, runID int64) ([]*github.WorkflowJob, error) {
-	listOptions := github.ListWorkflowJobsOptions{
-		ListOptions: github.ListOptions{},
+// getWorkflowFile returns the content of the workflow file on the given branch.
+func getWorkflowFile(ctx context.Context, client *github.Client, owner, repo, branch, workflow string) (string, error) {
+	file, _, _, err := client.Repositories.GetContents(ctx, owner, repo, ".github/workflows/"+workflow, &github.RepositoryContentGetOptions{Ref: branch})
+	if err != nil {
+		return "", err
 	}
-	var result []*github.WorkflowJob
-	for {
-		jobs, res, err := client.Actions.ListWorkflowJobs(ctx, owner, repo, runID, &listOptions)
+	return file.GetContent()
+}
+
+// getMergedPullRequests returns pull requests merged between the base and head commits.
+func getMergedPullRequests(ctx context.Context, client *github.Client, owner, repo, base, head string) ([]*github.PullRequest, error) {
+	comparison, _, err := client.Repositories.CompareCommits(ctx, owner, repo, base, head, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, err
+	}
+	seen := map[int]struct{}{}
+	var result []*github.PullRequest
+	for _, commit := range comparison.Commits {
+		prs, _, err := client.PullRequests.ListPullRequestsWithCommit(ctx, owner, repo, commit.GetSHA(), nil)
 		if err != nil {
 			return result, err
 		}
-		result = append(result, jobs.Jobs...)
-		if res.NextPage == 0 {
-			break
+		for _, pr := range prs {
+			if _, ok := seen[pr.GetNumber()]; ok || pr.MergedAt == nil {
+				continue
+			}
+			seen[pr.GetNumber()] = struct{}{}
+			result = append(result, pr)
 		}
-		listOptions.Page = res.NextPage
 	}
 	return result, nil
 }
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// gitlabDefaultWorkflow is the workflow name for all the pipelines of a project. GitLab
+// pipelines are defined in a single file, but can be told apart by their names.
+const gitlabDefaultWorkflow = ".gitlab-ci.yml"
+
+// gitlabEvents maps GitHub event names to GitLab pipeline sources.
+var gitlabEvents = map[string]string{
+	"pull_request":      "merge_request_event",
+	"workflow_dispatch": "web",
+}
+
+// gitlabConclusions maps GitLab statuses to GitHub conclusions.
+var gitlabConclusions = map[string]string{
+	"success":  "success",
+	"failed":   "failure",
+	"canceled": "cancelled",
+	"skipped":  "skipped",
+	"manual":   "action_required",
+}
+
+// gitlabUnsupportedShowFlags are the show flags that need jobs, logs, or other GitHub APIs,
+// which are not implemented for GitLab.
+var gitlabUnsupportedShowFlags = []string{
+	"actors", "anomalies", "baseline", "branches", "by-team", "correlation-min-workflows",
+	"correlation-window", "duration-method", "exclude-repos", "failure-artifacts", "fixes",
+	"group-by-metadata", "histogram", "include-repos", "log-context", "matrix", "max-log-size", "max-logs",
+	"quarantine-output", "quarantine-prune", "queue-time", "required-checks", "retries",
+	"scorecard", "stale-workflows", "step-categories", "step-retries", "success-expr",
+	"time-to-failure", "timeout-ratio", "top-tests", "yes",
+}
+
+// gitlabProvider is the provider for GitLab CI. Projects are identified by owner/repo,
+// where owner can contain subgroups.
+type gitlabProvider struct {
+	baseURL string
+	token   string
+}
+
+func newGitLabProvider(baseURL string) (*gitlabProvider, error) {
+	token := getToken("gitlab")
+	if token == "" {
+		return nil, fmt.Errorf("set GITLAB_TOKEN environment variable or run auth login gitlab")
+	}
+	return &gitlabProvider{baseURL: strings.TrimSuffix(baseURL, "/"), token: token}, nil
+}
+
+// get sends a GET request to the GitLab API, and returns the body and the next page
+// number, which is 0 on the last page.
+func (p *gitlabProvider) get(ctx context.Context, owner, repo, path string, query url.Values) ([]byte, int, error) {
+	u := fmt.Sprintf("%s/api/v4/projects/%s/%s", p.baseURL, url.PathEscape(owner+"/"+repo), path)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", p.token)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		// Unlike GitHub workflows, the pipelines of a project always exist, so the project
+		// itself is missing or not accessible with the token.
+		return nil, 0, fmt.Errorf("project %s/%s not found", owner, repo)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	next, _ := strconv.Atoi(resp.Header.Get("X-Next-Page"))
+	return body, next, nil
+}
+
+func (p *gitlabProvider) getWorkflows(ctx context.Context, owner, repo string) ([]string, error) {
+	return []string{gitlabDefaultWorkflow}, nil
+}
+
+// workflowURL returns the pipelines page of the project. Pipelines are not filtered by
+// name, since the page cannot filter by it.
+func (p *gitlabProvider) workflowURL(owner, repo, branch, workflow, event string) string {
+	query := url.Values{}
+	if branch != "" {
+		query.Set("ref", branch)
+	}
+	if event != "" {
+		query.Set("source", cmp.Or(gitlabEvents[event], event))
+	}
+	u := fmt.Sprintf("%s/%s/%s/-/pipelines", p.baseURL, owner, repo)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// getWorkflowRuns returns up to count pipelines, newest first. The API only filters
+// pipelines by update time, so the created filter is applied here the same way as by
+// dashboard.ListWorkflowRuns, including the or limit mode.
+func (p *gitlabProvider) getWorkflowRuns(ctx context.Context, owner, repo, branch, workflow, event string, count int, created string) ([]*github.WorkflowRun, error) {
+	query := url.Values{"per_page": {"100"}}
+	if branch != "" {
+		query.Set("ref", branch)
+	}
+	if event != "" {
+		query.Set("source", cmp.Or(gitlabEvents[event], event))
+	}
+	if workflow != gitlabDefaultWorkflow {
+		query.Set("name", workflow)
+	}
+	// Pipelines are listed by descending ID, which is the order of their creation.
+	query.Set("order_by", "id")
+	query.Set("sort", "desc")
+	var from, to time.Time
+	if fromFilter, toFilter := dashboard.SplitTimeRange(created); fromFilter != "" || toFilter != "" {
+		var err error
+		if fromFilter != "" {
+			if from, err = time.Parse(time.RFC3339, fromFilter); err != nil {
+				return nil, err
+			}
+			// Pipelines are updated after they are created, so this only narrows the query
+			// down in the and mode, where older pipelines are never included.
+			if limitMode == "and" || count == dashboard.AllRuns {
+				query.Set("updated_after", fromFilter)
+			}
+		}
+		if toFilter != "" {
+			if to, err = time.Parse(time.RFC3339, toFilter); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// done is set once the remaining pipelines are all older than the created filter
+	// allows.
+	var runs []*github.WorkflowRun
+	done := false
+	for page := 1; page != 0 && !done; {
+		query.Set("page", strconv.Itoa(page))
+		body, next, err := p.get(ctx, owner, repo, "pipelines", query)
+		if err != nil {
+			return runs, err
+		}
+		var pipelines []struct {
+			ID        int64     `json:"id"`
+			SHA       string    `json:"sha"`
+			Ref       string    `json:"ref"`
+			Status    string    `json:"status"`
+			Source    string    `json:"source"`
+			CreatedAt time.Time `json:"created_at"`
+			UpdatedAt time.Time `json:"updated_at"`
+			WebURL    string    `json:"web_url"`
+		}
+		if err := json.Unmarshal(body, &pipelines); err != nil {
+			return runs, err
+		}
+		for _, pipeline := range pipelines {
+			if !to.IsZero() && pipeline.CreatedAt.After(to) {
+				continue
+			}
+			// In the and mode, only pipelines within the range are included up to count. In
+			// the or mode, the latest count pipelines are included plus any others within
+			// the range.
+			inRange := from.IsZero() || !pipeline.CreatedAt.Before(from)
+			if len(runs) >= count && (limitMode == "and" || !inRange) ||
+				!inRange && (limitMode == "and" || count == dashboard.AllRuns) {
+				done = true
+				break
+			}
+			conclusion := gitlabConclusions[pipeline.Status]
+			if !slices.Contains(countedConclusions, conclusion) {
+				continue
+			}
+			runs = append(runs, &github.WorkflowRun{
+				ID:         github.Int64(pipeline.ID),
+				Name:       github.String(workflow),
+				HeadBranch: github.String(pipeline.Ref),
+				HeadSHA:    github.String(pipeline.SHA),
+				Event:      github.String(pipeline.Source),
+				Status:     github.String("completed"),
+				Conclusion: github.String(conclusion),
+				HTMLURL:    github.String(pipeline.WebURL),
+				// The list API does not return the start time of pipelines.
+				CreatedAt:    &github.Timestamp{Time: pipeline.CreatedAt},
+				RunStartedAt: &github.Timestamp{Time: pipeline.CreatedAt},
+				UpdatedAt:    &github.Timestamp{Time: pipeline.UpdatedAt},
+				RunAttempt:   github.Int(1),
+			})
+		}
+		page = next
+	}
+	return runs, nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGitLabGetWorkflowRuns(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	// Pipelines 10 to 1 were created daily, newest first. Pipeline 2 was cancelled, and
+	// pipeline 1, the oldest, was updated recently.
+	type pipeline struct {
+		ID        int64     `json:"id"`
+		Status    string    `json:"status"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	var pipelines []pipeline
+	for i := 1; i <= 10; i++ {
+		status := "success"
+		if i == 9 {
+			status = "canceled"
+		}
+		created := now.AddDate(0, 0, -i)
+		updated := created.Add(time.Hour)
+		if i == 10 {
+			updated = now
+		}
+		pipelines = append(pipelines, pipeline{ID: int64(11 - i), Status: status, CreatedAt: created, UpdatedAt: updated})
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Serve 3 pipelines per page, ignoring the time filters.
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		start := min((page-1)*3, len(pipelines))
+		end := min(start+3, len(pipelines))
+		if end < len(pipelines) {
+			w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+		}
+		json.NewEncoder(w).Encode(pipelines[start:end])
+	}))
+	defer server.Close()
+	p := &gitlabProvider{baseURL: server.URL}
+	from := now.AddDate(0, 0, -5).Format(time.RFC3339)
+	for _, tt := range []struct {
+		mode    string
+		count   int
+		created string
+		want    []int64
+	}{
+		{"and", 3, ">=" + from, []int64{10, 9, 8}},
+		{"and", 10, ">=" + from, []int64{10, 9, 8, 7, 6}},
+		{"or", 3, ">=" + from, []int64{10, 9, 8, 7, 6}},
+		{"or", 9, ">=" + from, []int64{10, 9, 8, 7, 6, 5, 4, 3, 1}},
+		{"and", 10, now.AddDate(0, 0, -8).Format(time.RFC3339) + ".." + now.AddDate(0, 0, -4).Format(time.RFC3339), []int64{7, 6, 5, 4, 3}},
+	} {
+		limitMode = tt.mode
+		runs, err := p.getWorkflowRuns(context.Background(), "o", "r", "", gitlabDefaultWorkflow, "", tt.count, tt.created)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int64
+		for _, run := range runs {
+			got = append(got, run.GetID())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s mode with count %d and created %s: got pipelines %v, want %v", tt.mode, tt.count, tt.created, got, tt.want)
+		}
+	}
+	limitMode = "and"
+}
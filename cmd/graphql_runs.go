@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// graphQLBatchSize is the number of workflows fetched in a single GraphQL query.
+const graphQLBatchSize = 10
+
+// graphQLWorkflowRun is a workflow run returned by the GraphQL API.
+type graphQLWorkflowRun struct {
+	DatabaseID int64     `json:"databaseId"`
+	RunNumber  int       `json:"runNumber"`
+	Event      string    `json:"event"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+	URL        string    `json:"url"`
+	CheckSuite struct {
+		Conclusion string `json:"conclusion"`
+		Commit     struct {
+			OID string `json:"oid"`
+		} `json:"commit"`
+		Branch *struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+		// CheckRuns are the jobs of the latest attempt of the run.
+		CheckRuns struct {
+			Nodes []struct {
+				StartedAt *time.Time `json:"startedAt"`
+			} `json:"nodes"`
+		} `json:"checkRuns"`
+	} `json:"checkSuite"`
+}
+
+// graphQLRunFields are the fields of graphQLWorkflowRun in a query.
+const graphQLRunFields = `databaseId runNumber event createdAt updatedAt url
+    checkSuite { conclusion commit { oid } branch { name } checkRuns(first: 100, filterBy: {checkType: LATEST}) { nodes { startedAt } } }`
+
+// toWorkflowRun converts the run to the REST API representation. The GraphQL API does
+// not expose when the run started, so the start of its earliest job in the latest attempt
+// is used instead, or the creation time if none of its jobs started.
+func (r graphQLWorkflowRun) toWorkflowRun() *github.WorkflowRun {
+	branch := ""
+	if r.CheckSuite.Branch != nil {
+		branch = r.CheckSuite.Branch.Name
+	}
+	var startedAt time.Time
+	for _, checkRun := range r.CheckSuite.CheckRuns.Nodes {
+		if checkRun.StartedAt != nil && (startedAt.IsZero() || checkRun.StartedAt.Before(startedAt)) {
+			startedAt = *checkRun.StartedAt
+		}
+	}
+	if startedAt.IsZero() {
+		startedAt = r.CreatedAt
+	}
+	return &github.WorkflowRun{
+		ID:           github.Int64(r.DatabaseID),
+		RunNumber:    github.Int(r.RunNumber),
+		Event:        github.String(strings.ToLower(r.Event)),
+		HeadSHA:      github.String(r.CheckSuite.Commit.OID),
+		HeadBranch:   github.String(branch),
+		Conclusion:   github.String(strings.ToLower(r.CheckSuite.Conclusion)),
+		CreatedAt:    &github.Timestamp{Time: r.CreatedAt},
+		RunStartedAt: &github.Timestamp{Time: startedAt},
+		UpdatedAt:    &github.Timestamp{Time: r.UpdatedAt},
+		HTMLURL:      github.String(r.URL),
+	}
+}
+
+// getWorkflowRunsGraphQL fetches workflow runs for the given workflows using the GraphQL
+// API, batching graphQLBatchSize workflows per query. The GraphQL API does not support
+// filtering runs, so pages of 100 runs of each workflow are fetched and filtered here
+// until there are count runs or the runs were created before since.
+func getWorkflowRunsGraphQL(ctx context.Context, client *github.Client, owner, repo, branch string, workflows []string, event string, count int, since time.Time) (map[string][]*github.WorkflowRun, error) {
+	nodeIDs, err := getWorkflowNodeIDs(ctx, client, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, workflow := range workflows {
+		if _, ok := nodeIDs[workflow]; ok {
+			pending = append(pending, workflow)
+		} else {
+			slog.Error("Workflow not found", slog.String("workflow", workflow))
+		}
+	}
+	result := map[string][]*github.WorkflowRun{}
+	for _, workflow := range pending {
+		result[workflow] = nil
+	}
+	// cursors are the end cursors of the pages fetched so far.
+	cursors := map[string]string{}
+	for len(pending) > 0 {
+		batch := pending[:min(graphQLBatchSize, len(pending))]
+		pending = pending[len(batch):]
+		var sb strings.Builder
+		sb.WriteString("query {\n")
+		for i, workflow := range batch {
+			after := ""
+			if cursor, ok := cursors[workflow]; ok {
+				after = fmt.Sprintf(", after: %q", cursor)
+			}
+			fmt.Fprintf(&sb, `  w%d: node(id: %q) { ... on Workflow { runs(first: 100%s, orderBy: {field: CREATED_AT, direction: DESC}) {
+    pageInfo { hasNextPage endCursor }
+    nodes { %s }
+  } } }
+`, i, nodeIDs[workflow], after, graphQLRunFields)
+		}
+		sb.WriteString("}")
+		var data map[string]struct {
+			Runs struct {
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Nodes []graphQLWorkflowRun `json:"nodes"`
+			} `json:"runs"`
+		}
+		if err := graphQL(ctx, sb.String(), nil, &data); err != nil {
+			return result, err
+		}
+		for i, workflow := range batch {
+			page := data[fmt.Sprintf("w%d", i)].Runs
+			done := !page.PageInfo.HasNextPage
+			for _, node := range page.Nodes {
+				run := node.toWorkflowRun()
+				// The runs are sorted by creation time, so the following ones are older.
+				if run.GetCreatedAt().Before(since) {
+					done = true
+					break
+				}
+				if (branch != "" && run.GetHeadBranch() != branch) ||
+					(event != "" && run.GetEvent() != event) ||
+					!slices.Contains(countedConclusions, run.GetConclusion()) {
+					continue
+				}
+				result[workflow] = append(result[workflow], run)
+				if len(result[workflow]) >= count {
+					done = true
+					break
+				}
+			}
+			if !done {
+				cursors[workflow] = page.PageInfo.EndCursor
+				pending = append(pending, workflow)
+			}
+		}
+	}
+	return result, nil
+}
+
+// getWorkflowNodeIDs returns GraphQL node IDs of the workflows keyed by file name.
+func getWorkflowNodeIDs(ctx context.Context, client *github.Client, owner, repo string) (map[string]string, error) {
+	workflows, err := dashboard.ListWorkflows(ctx, client.Actions, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]string{}
+	for _, workflow := range workflows {
+		result[path.Base(workflow.GetPath())] = workflow.GetNodeID()
+	}
+	return result, nil
+}
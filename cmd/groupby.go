@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// summaryGroupings are the periods that --group-by buckets the runs of each workflow into.
+var summaryGroupings = []string{"day", "week", "month"}
+
+// periodStart returns the start of the day, the week starting on Monday, or the month
+// that contains t in the display time zone.
+func periodStart(t time.Time, groupBy string) time.Time {
+	t = t.In(displayLocation)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, displayLocation)
+	switch groupBy {
+	case "week":
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, displayLocation)
+	default:
+		return day
+	}
+}
+
+// periodLabel returns the label of the period starting at start.
+func periodLabel(start time.Time, groupBy string) string {
+	if groupBy == "month" {
+		return start.Format("2006-01")
+	}
+	return formatDate(start)
+}
+
+// printGroupedSummary prints a table per workflow with the success rate and the average
+// duration of each period, oldest first, for the workflows in statsList.
+func printGroupedSummary(result map[string][]*github.WorkflowRun, statsList []workflowStats, top int, opts summaryOptions) {
+	for i, stats := range statsList {
+		if i >= top {
+			break
+		}
+		periods := map[time.Time][]*github.WorkflowRun{}
+		for _, run := range result[stats.Workflow] {
+			start := periodStart(run.GetRunStartedAt().Time, opts.groupBy)
+			periods[start] = append(periods[start], run)
+		}
+		var starts []time.Time
+		for start := range periods {
+			starts = append(starts, start)
+		}
+		slices.SortFunc(starts, func(a, b time.Time) int {
+			return a.Compare(b)
+		})
+		color.New(color.Bold).Printf("\n%s\n", stats.Workflow)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintln(w, fmt.Sprintf("%s\tsuccess rate\taverage duration", opts.groupBy))
+		for _, start := range starts {
+			runs := periods[start]
+			success := 0
+			var total time.Duration
+			for _, run := range runs {
+				if run.GetConclusion() == "success" {
+					success++
+					total += runDurations.Of(run)
+				}
+			}
+			average := "-"
+			if success > 0 {
+				average = (total / time.Duration(success)).Round(time.Second).String()
+			}
+			fmt.Fprintln(w, fmt.Sprintf("%s\t%0.f%% %d/%d\t%s", periodLabel(start, opts.groupBy), dashboard.SuccessRate(runs), success, len(runs), average))
+		}
+		w.Flush()
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+)
+
+// workflowGroup is a named category of workflows, such as e2e or unit. A workflow can
+// belong to multiple groups.
+type workflowGroup struct {
+	Name string `yaml:"name"`
+	// Workflows are file name patterns (e.g. conformance-*.yaml).
+	Workflows []string `yaml:"workflows"`
+}
+
+// ungroupedName is the name of the group of workflows that match no group.
+const ungroupedName = "ungrouped"
+
+type groupStats struct {
+	name      string
+	workflows int
+	// failing is the number of workflows whose latest run failed.
+	failing      int
+	success      int
+	count        int
+	totalSeconds float64
+}
+
+func getGroupStats(groups []workflowGroup, result map[string][]*github.WorkflowRun) []groupStats {
+	statsList := make([]groupStats, len(groups)+1)
+	for i, group := range groups {
+		statsList[i].name = group.Name
+	}
+	statsList[len(groups)].name = ungroupedName
+	for workflow, runs := range result {
+		grouped := false
+		for i, group := range groups {
+			if matchAny(group.Workflows, workflow) {
+				grouped = true
+				statsList[i].add(runs)
+			}
+		}
+		if !grouped {
+			statsList[len(groups)].add(runs)
+		}
+	}
+	if statsList[len(groups)].workflows == 0 {
+		statsList = statsList[:len(groups)]
+	}
+	return statsList
+}
+
+func (s *groupStats) add(runs []*github.WorkflowRun) {
+	s.workflows++
+	if len(runs) > 0 && runs[0].GetConclusion() != "success" {
+		s.failing++
+	}
+	for _, run := range runs {
+		if run.GetConclusion() == "success" {
+			s.success++
+			s.totalSeconds += runDurations.Of(run).Seconds()
+		}
+		s.count++
+	}
+}
+
+// printGroups prints the stats of the workflows rolled up by group.
+func printGroups(groups []workflowGroup, result map[string][]*github.WorkflowRun) {
+	if len(groups) == 0 {
+		return
+	}
+	color.New(color.Bold).Println("\ngroups")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "group\tsuccess rate\taverage duration\tworkflows\tfailing")
+	for _, stats := range getGroupStats(groups, result) {
+		rate := "N/A"
+		duration := "N/A"
+		if stats.count > 0 {
+			rate = fmt.Sprintf("%0.f%% %d/%d", 100*float32(stats.success)/float32(stats.count), stats.success, stats.count)
+		}
+		if stats.success > 0 {
+			duration = (time.Second * time.Duration(stats.totalSeconds/float64(stats.success))).String()
+		}
+		failing := ""
+		if stats.failing > 0 {
+			failing = color.New(color.FgRed).Sprintf("%d", stats.failing)
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%d\t%s", stats.name, rate, duration, stats.workflows, failing))
+	}
+	w.Flush()
+}
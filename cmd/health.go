@@ -0,0 +1,51 @@
+package cmd
+
+import "fmt"
+
+// Success rates below which workflows are shown as WARN and FAIL in the dashboard, set
+// with the --warn-below and --critical-below flags.
+var (
+	warnBelow     float32 = 80
+	criticalBelow float32 = 50
+)
+
+// healthThreshold overrides the success rates below which a workflow is shown as WARN
+// and FAIL, since teams have different definitions of healthy. Zero values fall back to
+// the flags.
+type healthThreshold struct {
+	Workflow string  `yaml:"workflow"`
+	Warn     float32 `yaml:"warn"`
+	Critical float32 `yaml:"critical"`
+}
+
+// healthThresholds returns the success rates below which the workflow is shown as WARN
+// and FAIL.
+func (c *config) healthThresholds(workflow string) (float32, float32) {
+	warn, critical := warnBelow, criticalBelow
+	for _, t := range c.HealthThresholds {
+		if t.Workflow != workflow {
+			continue
+		}
+		if t.Warn > 0 {
+			warn = t.Warn
+		}
+		if t.Critical > 0 {
+			critical = t.Critical
+		}
+	}
+	return warn, critical
+}
+
+// checkHealthThresholds returns an error if the FAIL threshold of a workflow is above its
+// WARN threshold, given the flags and the overrides of the config.
+func (c *config) checkHealthThresholds() error {
+	if criticalBelow > warnBelow {
+		return fmt.Errorf("--critical-below %.0f is above --warn-below %.0f", criticalBelow, warnBelow)
+	}
+	for _, t := range c.HealthThresholds {
+		if warn, critical := c.healthThresholds(t.Workflow); critical > warn {
+			return fmt.Errorf("critical threshold %.0f of %s is above its warn threshold %.0f", critical, t.Workflow, warn)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import "testing"
+
+func TestCheckHealthThresholds(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		thresholds []healthThreshold
+		wantErr    bool
+	}{
+		{"defaults", nil, false},
+		{"both set", []healthThreshold{{Workflow: "ci.yaml", Warn: 95, Critical: 90}}, false},
+		{"critical above warn", []healthThreshold{{Workflow: "ci.yaml", Warn: 60, Critical: 70}}, true},
+		{"warn below the default critical", []healthThreshold{{Workflow: "ci.yaml", Warn: 40}}, true},
+		{"critical above the default warn", []healthThreshold{{Workflow: "ci.yaml", Critical: 90}}, true},
+	} {
+		cfg := &config{HealthThresholds: tt.thresholds}
+		if err := cfg.checkHealthThresholds(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: checkHealthThresholds() returned %v, want error %t", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var heatmapCmd = &cobra.Command{
+	Use:   "heatmap owner repo",
+	Short: "Show failure rate by hour of day and day of week",
+	Long: `Show failure rate by hour of day and day of week in UTC, to reveal patterns such as
+scheduled runs that collide with a maintenance window.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		workflowFlag, err := cmd.Flags().GetString("workflow")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		var workflows []string
+		if workflowFlag != "" {
+			workflows = append(workflows, workflowFlag)
+		} else {
+			workflows, err = dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+			if err != nil {
+				return err
+			}
+		}
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+		printHeatmap(result)
+		return nil
+	},
+}
+
+// heatmapCell counts runs created in an hour of a weekday.
+type heatmapCell struct {
+	failure int
+	count   int
+}
+
+func printHeatmap(result map[string][]*github.WorkflowRun) {
+	var cells [7][24]heatmapCell
+	for _, runs := range result {
+		for _, run := range runs {
+			t := run.GetCreatedAt().UTC()
+			cell := &cells[t.Weekday()][t.Hour()]
+			cell.count++
+			if run.GetConclusion() != "success" {
+				cell.failure++
+			}
+		}
+	}
+	fmt.Print("UTC  ")
+	for hour := 0; hour < 24; hour++ {
+		fmt.Printf("%-3d", hour)
+	}
+	fmt.Println()
+	// Start the week on Monday.
+	for i := 1; i <= 7; i++ {
+		weekday := time.Weekday(i % 7)
+		fmt.Printf("%s  ", weekday.String()[:3])
+		for hour := 0; hour < 24; hour++ {
+			fmt.Print(heatmapSymbol(cells[weekday][hour]))
+		}
+		fmt.Println()
+	}
+	fmt.Print("\nfailure rate: ")
+	for _, c := range []struct {
+		label string
+		cell  heatmapCell
+	}{
+		{"no runs", heatmapCell{}},
+		{"0%", heatmapCell{failure: 0, count: 1}},
+		{"<20%", heatmapCell{failure: 1, count: 10}},
+		{"<50%", heatmapCell{failure: 3, count: 10}},
+		{">=50%", heatmapCell{failure: 1, count: 1}},
+	} {
+		fmt.Printf("%s %s  ", heatmapSymbol(c.cell), c.label)
+	}
+	fmt.Println()
+}
+
+// heatmapSymbol returns a shade for the failure rate of the cell, so that the heatmap is
+// readable without colors too.
+func heatmapSymbol(cell heatmapCell) string {
+	if cell.count == 0 {
+		return heatmapShade(" · ", "   ")
+	}
+	rate := 100 * float32(cell.failure) / float32(cell.count)
+	switch {
+	case rate == 0:
+		return color.New(color.FgGreen).Sprint(heatmapShade("░░░", "..."))
+	case rate < 20:
+		return color.New(color.FgYellow).Sprint(heatmapShade("▒▒▒", "ooo"))
+	case rate < 50:
+		return color.New(color.FgHiRed).Sprint(heatmapShade("▓▓▓", "OOO"))
+	default:
+		return color.New(color.FgRed).Sprint(heatmapShade("███", "###"))
+	}
+}
+
+// heatmapShade returns the ASCII shade with --ascii.
+func heatmapShade(shade, ascii string) string {
+	if asciiOutput {
+		return ascii
+	}
+	return shade
+}
+
+func init() {
+	rootCmd.AddCommand(heatmapCmd)
+
+	heatmapCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	heatmapCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	heatmapCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	heatmapCmd.Flags().StringP("workflow", "w", "", "Workflow name (e.g. aks-byocni.yaml)")
+	heatmapCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+)
+
+const (
+	// histogramBins is the number of equal-width duration ranges of a histogram.
+	histogramBins = 10
+	// histogramWidth is the length of the longest bar of a histogram.
+	histogramWidth = 40
+)
+
+// durationHistogram counts the durations in histogramBins equal-width ranges between the
+// shortest and the longest duration. It returns the counts and the width of the ranges.
+func durationHistogram(durations []time.Duration) ([]int, time.Duration) {
+	lo, hi := slices.Min(durations), slices.Max(durations)
+	width := max((hi-lo)/histogramBins, time.Second)
+	counts := make([]int, histogramBins)
+	for _, d := range durations {
+		counts[min(int((d-lo)/width), histogramBins-1)]++
+	}
+	return counts, width
+}
+
+// printHistograms prints a histogram of the durations of the successful runs of each
+// workflow, which reveals bimodal distributions such as cache hits and misses that
+// averages hide.
+func printHistograms(result map[string][]*github.WorkflowRun) {
+	var workflows []string
+	for workflow := range result {
+		workflows = append(workflows, workflow)
+	}
+	slices.Sort(workflows)
+	color.New(color.Bold).Println("\nduration histograms of successful runs")
+	for _, workflow := range workflows {
+		var durations []time.Duration
+		for _, run := range result[workflow] {
+			if run.GetConclusion() == "success" {
+				durations = append(durations, runDurations.Of(run))
+			}
+		}
+		if len(durations) == 0 {
+			continue
+		}
+		counts, width := durationHistogram(durations)
+		lo := slices.Min(durations)
+		fmt.Printf("\n%s (%d runs)\n", workflow, len(durations))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		for i, count := range counts {
+			from := (lo + time.Duration(i)*width).Round(time.Second)
+			to := (lo + time.Duration(i+1)*width).Round(time.Second)
+			bar := strings.Repeat("#", count*histogramWidth/slices.Max(counts))
+			fmt.Fprintln(w, fmt.Sprintf("  %s\t- %s\t| %s %d", from, to, bar, count))
+		}
+		w.Flush()
+	}
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+)
+
+//go:embed templates/bundle.html
+var bundleTemplate string
+
+// htmlBundle is the data of the HTML bundle template.
+type htmlBundle struct {
+	Report report
+	// Thresholds are the success rates below which each workflow is shown as WARN and
+	// FAIL, keyed by workflow file name. The empty key holds the defaults, which are used
+	// for the daily success rates of all the workflows.
+	Thresholds map[string][2]float32
+}
+
+// writeHTMLBundle writes a self-contained HTML file with the report embedded as JSON,
+// colored with the health thresholds of the config.
+func writeHTMLBundle(out io.Writer, r report, cfg *config) error {
+	t, err := template.New("bundle").Parse(bundleTemplate)
+	if err != nil {
+		return err
+	}
+	bundle := htmlBundle{Report: r, Thresholds: map[string][2]float32{"": {warnBelow, criticalBelow}}}
+	for _, w := range r.Workflows {
+		warn, critical := cfg.healthThresholds(w.Workflow)
+		bundle.Thresholds[w.Workflow] = [2]float32{warn, critical}
+	}
+	return t.Execute(out, bundle)
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect owner repo",
+	Short: "Inspect workflow files and cross-reference them with run data",
+	Long: `Inspect the workflow files on the branch and cross-reference them with run data to list
+workflows that have not run recently, jobs without timeout-minutes, and times at which
+many scheduled workflows start together.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		staleDays, err := cmd.Flags().GetInt("stale-days")
+		if err != nil {
+			return err
+		}
+		overlap, err := cmd.Flags().GetInt("overlap")
+		if err != nil {
+			return err
+		}
+		workflows, err := dashboard.ListWorkflows(ctx, client.Actions, owner, repo)
+		if err != nil {
+			return err
+		}
+		inspections := inspectWorkflows(ctx, client, owner, repo, branch, workflows)
+		printStaleWorkflows(owner, repo, inspections, time.Now().AddDate(0, 0, -staleDays))
+		printJobsWithoutTimeout(inspections)
+		printScheduleOverlaps(inspections, overlap, time.Now())
+		return nil
+	},
+}
+
+// workflowInspection is what inspect found about a workflow.
+type workflowInspection struct {
+	workflow string
+	state    string
+	// lastRun is the latest run on any branch, or nil if the workflow never ran.
+	lastRun *github.WorkflowRun
+	// jobsWithoutTimeout are the IDs of the jobs that run on a runner without
+	// timeout-minutes, so they can run for up to 6 hours.
+	jobsWithoutTimeout []string
+	crons              []string
+}
+
+// parseJobsWithoutTimeout returns the IDs of the jobs without timeout-minutes in the
+// workflow file.
+func parseJobsWithoutTimeout(content string) ([]string, error) {
+	var wf struct {
+		Jobs map[string]struct {
+			// Uses is set for jobs that call a reusable workflow, which has its own timeouts.
+			Uses    string `yaml:"uses"`
+			Timeout any    `yaml:"timeout-minutes"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &wf); err != nil {
+		return nil, err
+	}
+	var jobs []string
+	for id, job := range wf.Jobs {
+		if job.Uses == "" && job.Timeout == nil {
+			jobs = append(jobs, id)
+		}
+	}
+	slices.Sort(jobs)
+	return jobs, nil
+}
+
+// inspectWorkflows fetches the latest run and the file of each workflow in parallel.
+func inspectWorkflows(ctx context.Context, client *github.Client, owner, repo, branch string, workflows []*github.Workflow) []workflowInspection {
+	var result []workflowInspection
+	tasks := make(chan *github.Workflow)
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for workflow := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				inspection := workflowInspection{workflow: path.Base(workflow.GetPath()), state: workflow.GetState()}
+				runs, _, err := client.Actions.ListWorkflowRunsByID(ctx, owner, repo, workflow.GetID(), &github.ListWorkflowRunsOptions{ListOptions: github.ListOptions{PerPage: 1}})
+				if err != nil {
+					slog.Error("Failed to get workflow runs", slog.String("workflow", inspection.workflow), slog.Any("error", err))
+					continue
+				}
+				if len(runs.WorkflowRuns) > 0 {
+					inspection.lastRun = runs.WorkflowRuns[0]
+				}
+				content, err := getWorkflowFile(ctx, client, owner, repo, branch, inspection.workflow)
+				if err == nil {
+					inspection.jobsWithoutTimeout, err = parseJobsWithoutTimeout(content)
+				}
+				if err == nil {
+					inspection.crons, err = parseWorkflowSchedules(content)
+				}
+				if err != nil {
+					// Workflows such as dynamic ones for Dependabot have no file.
+					slog.Debug("Failed to inspect workflow file", slog.String("workflow", inspection.workflow), slog.Any("error", err))
+				}
+				mux.Lock()
+				result = append(result, inspection)
+				mux.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	for _, workflow := range workflows {
+		if workflow.GetState() != "deleted" {
+			tasks <- workflow
+		}
+	}
+	close(tasks)
+	wg.Wait()
+	slices.SortFunc(result, func(a, b workflowInspection) int {
+		return cmp.Compare(a.workflow, b.workflow)
+	})
+	return result
+}
+
+func printStaleWorkflows(owner, repo string, inspections []workflowInspection, since time.Time) {
+	color.New(color.FgYellow, color.Bold).Printf("\nworkflows that have not run since %s\n", formatDate(since))
+	link := color.New(color.FgCyan).SprintFunc()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "last run\tstate\tworkflow")
+	for _, inspection := range inspections {
+		lastRun := "never"
+		if inspection.lastRun != nil {
+			if inspection.lastRun.GetCreatedAt().After(since) {
+				continue
+			}
+			lastRun = getLink(inspection.lastRun.GetHTMLURL(), formatDate(inspection.lastRun.GetCreatedAt().Time))
+		}
+		workflowURL := fmt.Sprintf("https://github.com/%s/%s/actions/workflows/%s", owner, repo, inspection.workflow)
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s", lastRun, inspection.state, link(getLink(workflowURL, inspection.workflow))))
+	}
+	w.Flush()
+}
+
+func printJobsWithoutTimeout(inspections []workflowInspection) {
+	color.New(color.FgYellow, color.Bold).Println("\njobs without timeout-minutes")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "workflow\tjobs")
+	for _, inspection := range inspections {
+		if len(inspection.jobsWithoutTimeout) > 0 {
+			fmt.Fprintln(w, fmt.Sprintf("%s\t%s", inspection.workflow, strings.Join(inspection.jobsWithoutTimeout, ", ")))
+		}
+	}
+	w.Flush()
+}
+
+// printScheduleOverlaps prints the times within the next week at which at least overlap
+// scheduled workflows start together, competing for runners.
+func printScheduleOverlaps(inspections []workflowInspection, overlap int, now time.Time) {
+	slots := map[time.Time][]string{}
+	end := now.AddDate(0, 0, 7)
+	for _, inspection := range inspections {
+		for _, expr := range inspection.crons {
+			c, err := parseCron(expr)
+			if err != nil {
+				slog.Debug("Failed to parse cron expression", slog.String("cron", expr), slog.Any("error", err))
+				continue
+			}
+			for t := c.next(now); !t.IsZero() && t.Before(end); t = c.next(t) {
+				if !slices.Contains(slots[t], inspection.workflow) {
+					slots[t] = append(slots[t], inspection.workflow)
+				}
+			}
+		}
+	}
+	var times []time.Time
+	for t, workflows := range slots {
+		if len(workflows) >= overlap {
+			times = append(times, t)
+		}
+	}
+	slices.SortFunc(times, func(a, b time.Time) int {
+		return cmp.Or(len(slots[b])-len(slots[a]), a.Compare(b))
+	})
+	color.New(color.FgYellow, color.Bold).Printf("\nschedules with %d or more workflows starting together in the next week\n", overlap)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "time (UTC)\tcount\tworkflows")
+	for _, t := range times {
+		workflows := slots[t]
+		slices.Sort(workflows)
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%d\t%s", t.Format("Mon 15:04"), len(workflows), strings.Join(workflows, ", ")))
+	}
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(inspectCmd)
+
+	inspectCmd.Flags().StringP("branch", "b", "main", "Branch to read the workflow files from")
+	inspectCmd.Flags().Int("stale-days", 90, "List workflows that have not run in this number of days")
+	inspectCmd.Flags().Int("overlap", 3, "List times at which at least this number of scheduled workflows start together")
+}
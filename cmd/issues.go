@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var issuesCmd = &cobra.Command{
+	Use:   "issues owner repo",
+	Short: "File GitHub issues for recurring test failures and error logs",
+	Long: `File GitHub issues for failed tests and error logs that occurred at least --threshold
+times in the failed runs of a workflow. Issues are labeled with --label, and existing open
+issues with the label and the same title are updated instead of filing new ones.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		workflow, err := cmd.Flags().GetString("workflow")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		threshold, err := cmd.Flags().GetInt("threshold")
+		if err != nil {
+			return err
+		}
+		label, err := cmd.Flags().GetString("label")
+		if err != nil {
+			return err
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+		if workflow == "" {
+			return fmt.Errorf("--workflow is required")
+		}
+		runs, err := getWorkflowRuns(ctx, client, owner, repo, branch, workflow, event, numRuns, daysToTimeRange(days))
+		if err != nil {
+			return err
+		}
+		analysis := analyzeLogs(ctx, getFailureDetails(ctx, client, owner, repo, runs).jobLogs, 0)
+		existing, err := getLabeledIssues(ctx, client, owner, repo, label)
+		if err != nil {
+			return err
+		}
+		for _, kind := range []struct {
+			name   string
+			counts map[string]int
+			runs   map[string][]*github.WorkflowRun
+		}{
+			{name: "Failed test", counts: analysis.failedTestCount, runs: analysis.failedTestRuns},
+			{name: "Error log", counts: analysis.errorLogCount, runs: analysis.errorLogRuns},
+		} {
+			for _, count := range dashboard.SortByCount(kind.counts) {
+				if count.Count < threshold {
+					continue
+				}
+				title := fmt.Sprintf("%s in %s: %s", kind.name, workflow, truncate(count.Name, 100))
+				body := issueBody(workflow, count, kind.runs[count.Name], analysis.excerpts[count.Name])
+				if dryRun {
+					fmt.Printf("%s\n\n%s\n", title, body)
+					continue
+				}
+				if issue, ok := existing[title]; ok {
+					if _, _, err := client.Issues.Edit(ctx, owner, repo, issue.GetNumber(), &github.IssueRequest{Body: &body}); err != nil {
+						return err
+					}
+					slog.Info("Updated issue", slog.String("url", issue.GetHTMLURL()))
+					continue
+				}
+				issue, _, err := client.Issues.Create(ctx, owner, repo, &github.IssueRequest{
+					Title:  &title,
+					Body:   &body,
+					Labels: &[]string{label},
+				})
+				if err != nil {
+					return err
+				}
+				slog.Info("Filed issue", slog.String("url", issue.GetHTMLURL()))
+			}
+		}
+		return nil
+	},
+}
+
+// getLabeledIssues returns open issues with the given label keyed by title.
+func getLabeledIssues(ctx context.Context, client *github.Client, owner, repo, label string) (map[string]*github.Issue, error) {
+	listOptions := github.IssueListByRepoOptions{State: "open", Labels: []string{label}}
+	result := map[string]*github.Issue{}
+	for {
+		issues, res, err := client.Issues.ListByRepo(ctx, owner, repo, &listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			result[issue.GetTitle()] = issue
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		listOptions.Page = res.NextPage
+	}
+	return result, nil
+}
+
+func issueBody(workflow string, count dashboard.FailureCount, runs []*github.WorkflowRun, excerpt string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "`%s` failed **%d** times in `%s`.\n\n", count.Name, count.Count, workflow)
+	sb.WriteString("Affected runs:\n\n")
+	seen := map[int64]struct{}{}
+	for _, run := range runs {
+		if _, ok := seen[run.GetID()]; ok {
+			continue
+		}
+		seen[run.GetID()] = struct{}{}
+		fmt.Fprintf(&sb, "- [%s](%s) `%s`\n", run.GetRunStartedAt().Format(time.DateTime), run.GetHTMLURL(), run.GetHeadSHA())
+	}
+	if excerpt != "" {
+		fmt.Fprintf(&sb, "\nLog excerpt:\n\n```\n%s\n```\n", excerpt)
+	}
+	sb.WriteString("\nThis issue is maintained by ci-dashboard.\n")
+	return sb.String()
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+func init() {
+	rootCmd.AddCommand(issuesCmd)
+
+	issuesCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	issuesCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	issuesCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	issuesCmd.Flags().StringP("workflow", "w", "", "Workflow name (e.g. aks-byocni.yaml)")
+	issuesCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	issuesCmd.Flags().Int("threshold", 5, "File issues for failures that occurred at least this many times")
+	issuesCmd.Flags().String("label", "ci-dashboard", "Label used to mark and deduplicate filed issues")
+	issuesCmd.Flags().Bool("dry-run", false, "Print the issues instead of filing them")
+}
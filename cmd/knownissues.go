@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// knownIssue marks a workflow as failing because of a known issue until the given date.
+// Workflows with an active known issue are excluded from alert thresholds.
+type knownIssue struct {
+	Workflow string `yaml:"workflow"`
+	// Pattern is a regex matched against the failed tests, error messages, and log
+	// excerpts in the detailed dashboard. Known issues with a pattern tag failures in any
+	// workflow instead of a whole workflow.
+	Pattern string `yaml:"pattern"`
+	Issue   string `yaml:"issue"`
+	// Label is shown instead of the issue number (e.g. "flake").
+	Label string `yaml:"label"`
+	// Until is the date (YYYY-MM-DD) until which the workflow is expected to fail. The
+	// known issue never expires if it is empty.
+	Until string `yaml:"until"`
+}
+
+// active returns true if the known issue has not expired yet. Until is validated by
+// loadConfig, and a known issue with an invalid date is treated as expired.
+func (k knownIssue) active(now time.Time) bool {
+	if k.Until == "" {
+		return true
+	}
+	until, err := time.Parse(time.DateOnly, k.Until)
+	if err != nil {
+		return false
+	}
+	return now.Before(until.AddDate(0, 0, 1))
+}
+
+// getKnownIssue returns the active known issue for the workflow, or nil if there is none.
+func (c *config) getKnownIssue(workflow string) *knownIssue {
+	now := time.Now()
+	for i, k := range c.KnownIssues {
+		if k.Pattern == "" && k.Workflow == workflow && k.active(now) {
+			return &c.KnownIssues[i]
+		}
+	}
+	return nil
+}
+
+// knownIssueLabel returns a label like "known issue #1234" for the workflow, or an empty
+// string if the workflow has no active known issue.
+func (c *config) knownIssueLabel(workflow string) string {
+	k := c.getKnownIssue(workflow)
+	if k == nil {
+		return ""
+	}
+	return k.label()
+}
+
+// label returns the label of the known issue linked to the issue.
+func (k knownIssue) label() string {
+	text := k.Label
+	if text == "" {
+		text = "known issue #" + path.Base(k.Issue)
+	}
+	yellow := color.New(color.FgYellow).SprintFunc()
+	return yellow(getLink(k.Issue, text))
+}
+
+// knownIssueMatcher matches failure signatures against the active known issues with a
+// pattern.
+type knownIssueMatcher struct {
+	issues  []knownIssue
+	regexps []*regexp.Regexp
+}
+
+func newKnownIssueMatcher(issues []knownIssue) (*knownIssueMatcher, error) {
+	m := &knownIssueMatcher{}
+	now := time.Now()
+	for _, k := range issues {
+		if k.Pattern == "" || !k.active(now) {
+			continue
+		}
+		r, err := regexp.Compile(k.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid known issue pattern %q: %w", k.Pattern, err)
+		}
+		m.issues = append(m.issues, k)
+		m.regexps = append(m.regexps, r)
+	}
+	return m, nil
+}
+
+// match returns the first known issue whose pattern matches the signature, such as a
+// test name or an error message, or its log excerpt. It returns nil if there is none.
+func (m *knownIssueMatcher) match(signature, excerpt string) *knownIssue {
+	for i, r := range m.regexps {
+		if r.MatchString(signature) || r.MatchString(excerpt) {
+			return &m.issues[i]
+		}
+	}
+	return nil
+}
@@ -1,13 +1,25 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
+	_ "embed"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var listCmd = &cobra.Command{
@@ -15,30 +27,197 @@ var listCmd = &cobra.Command{
 	Short: "List workflows",
 
 	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := cmd.Flags().GetBool("schema")
+		if err != nil {
+			return err
+		}
+		if schema {
+			fmt.Print(workflowInfoSchema)
+			return nil
+		}
 		if len(args) != 2 {
 			cmd.Usage()
 			os.Exit(1)
 		}
-		token := os.Getenv("GITHUB_TOKEN")
-		if token == "" {
-			slog.Error("Set GITHUB_TOKEN environment variable")
-			os.Exit(1)
-		}
-		client := github.NewClient(nil).WithAuthToken(token)
+		client := newClient()
 		owner := args[0]
 		repo := args[1]
-		ctx := context.Background()
-		workflows, err := getWorkflows(ctx, client, owner, repo)
+		ctx := cmd.Context()
+		long, err := cmd.Flags().GetBool("long")
 		if err != nil {
 			return err
 		}
-		for _, workflow := range workflows {
-			fmt.Println(workflow)
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
 		}
+		if output != "text" && output != "json" {
+			return fmt.Errorf("unknown output format %q", output)
+		}
+		if !long && output == "text" {
+			workflows, err := dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+			if err != nil {
+				return err
+			}
+			for _, workflow := range workflows {
+				fmt.Println(workflow)
+			}
+			return nil
+		}
+		infos, err := getWorkflowInfos(ctx, client, owner, repo)
+		if err != nil {
+			return err
+		}
+		if output == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(infos)
+		}
+		printWorkflowInfos(infos)
 		return nil
 	},
 }
 
+// workflowInfoSchema is the JSON schema of list --output json, printed by list --schema.
+//
+//go:embed schemas/list-v1.json
+var workflowInfoSchema string
+
+// workflowInfo describes a workflow for list --long and --output json.
+type workflowInfo struct {
+	Workflow string `json:"workflow"`
+	Name     string `json:"name"`
+	// State is active, or why the workflow is disabled (e.g. disabled_manually).
+	State string `json:"state"`
+	// Events are the events that trigger the workflow, parsed from the workflow file on
+	// the default branch.
+	Events         []string   `json:"events"`
+	LastRun        *time.Time `json:"lastRun,omitempty"`
+	LastConclusion string     `json:"lastConclusion,omitempty"`
+	LastRunURL     string     `json:"lastRunURL,omitempty"`
+	BadgeURL       string     `json:"badgeURL"`
+}
+
+// getWorkflowInfos returns the workflows, excluding deleted ones, with their latest run
+// and trigger events.
+func getWorkflowInfos(ctx context.Context, client *github.Client, owner, repo string) ([]*workflowInfo, error) {
+	workflows, err := dashboard.ListWorkflows(ctx, client.Actions, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	var infos []*workflowInfo
+	for _, workflow := range workflows {
+		if workflow.GetState() == "deleted" {
+			continue
+		}
+		infos = append(infos, &workflowInfo{
+			Workflow: path.Base(workflow.GetPath()),
+			Name:     workflow.GetName(),
+			State:    workflow.GetState(),
+			BadgeURL: workflow.GetBadgeURL(),
+		})
+	}
+	slices.SortFunc(infos, func(a, b *workflowInfo) int {
+		return strings.Compare(a.Workflow, b.Workflow)
+	})
+	tasks := make(chan *workflowInfo)
+	wg := sync.WaitGroup{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for info := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				runs, _, err := client.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, info.Workflow,
+					&github.ListWorkflowRunsOptions{ListOptions: github.ListOptions{PerPage: 1}})
+				if err != nil {
+					slog.Error("Failed to get the latest run", slog.String("workflow", info.Workflow), slog.Any("error", err))
+				} else if len(runs.WorkflowRuns) > 0 {
+					run := runs.WorkflowRuns[0]
+					createdAt := run.GetCreatedAt().Time
+					info.LastRun = &createdAt
+					info.LastConclusion = cmp.Or(run.GetConclusion(), run.GetStatus())
+					info.LastRunURL = run.GetHTMLURL()
+				}
+				content, err := getWorkflowFile(ctx, client, owner, repo, "", info.Workflow)
+				if err == nil {
+					info.Events, err = parseWorkflowEvents(content)
+				}
+				if err != nil {
+					slog.Error("Failed to get the events of the workflow", slog.String("workflow", info.Workflow), slog.Any("error", err))
+				}
+			}
+			wg.Done()
+		}()
+	}
+	for _, info := range infos {
+		tasks <- info
+	}
+	close(tasks)
+	wg.Wait()
+	return infos, nil
+}
+
+// parseWorkflowEvents returns the events under on in the workflow file, which can be a
+// string, a list of events, or a mapping of events to their configuration.
+func parseWorkflowEvents(content string) ([]string, error) {
+	var wf struct {
+		On yaml.Node `yaml:"on"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &wf); err != nil {
+		return nil, err
+	}
+	var events []string
+	switch wf.On.Kind {
+	case yaml.ScalarNode:
+		events = append(events, wf.On.Value)
+	case yaml.SequenceNode:
+		for _, node := range wf.On.Content {
+			events = append(events, node.Value)
+		}
+	case yaml.MappingNode:
+		// Keys and values alternate in the content of mapping nodes.
+		for i := 0; i < len(wf.On.Content); i += 2 {
+			events = append(events, wf.On.Content[i].Value)
+		}
+	}
+	return events, nil
+}
+
+func printWorkflowInfos(infos []*workflowInfo) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "workflow\tstate\tlast run\tconclusion\tevents\tbadge")
+	for _, info := range infos {
+		state := info.State
+		if state != "active" {
+			state = yellow(state)
+		}
+		lastRun, conclusion := "never", ""
+		if info.LastRun != nil {
+			lastRun = getLink(info.LastRunURL, formatTime(*info.LastRun))
+			switch info.LastConclusion {
+			case "success":
+				conclusion = green(info.LastConclusion)
+			case "failure", "timed_out":
+				conclusion = red(info.LastConclusion)
+			default:
+				conclusion = info.LastConclusion
+			}
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s",
+			info.Workflow, state, lastRun, conclusion, strings.Join(info.Events, ","), info.BadgeURL))
+	}
+	w.Flush()
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().BoolP("long", "l", false, "Print the state, the latest run and its conclusion, the trigger events, and the badge URL of each workflow")
+	listCmd.Flags().StringP("output", "o", "text", "Output format (text, json). json includes the details of --long")
+	listCmd.Flags().Bool("schema", false, "Print the JSON schema of the json output format, and exit")
 }
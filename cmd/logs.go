@@ -0,0 +1,406 @@
+package cmd
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+const (
+	// maxLogLineSize is the maximum length of a log line to analyze. Longer lines, such
+	// as dumps of binary data, are truncated.
+	maxLogLineSize = 64 * 1024
+	// maxLogMatches is the maximum number of failed tests and error logs to count in a
+	// job log.
+	maxLogMatches = 10000
+)
+
+var (
+	failedTestRegexp = regexp.MustCompile(`Test \[(.*)]:`)
+	errorLogRegexp   = regexp.MustCompile(` level=error.*`)
+	errorMsgRegexp   = regexp.MustCompile(`msg="([^"]+)"`)
+)
+
+// jobLog is the logs URL of a failed job along with the workflow run it belongs to.
+type jobLog struct {
+	url *url.URL
+	run *github.WorkflowRun
+	job *github.WorkflowJob
+	// successRate of the workflow the job belongs to. Logs of workflows with lower
+	// success rates are analyzed first, see prioritizeJobLogs.
+	successRate float32
+}
+
+// failureDetails contains failed jobs and steps of failed workflow runs.
+type failureDetails struct {
+	*dashboard.FailureReport
+	jobLogs []jobLog
+	// incomplete is set if the jobs of a failed run or the logs URL of a failed job
+	// could not be fetched, so jobLogs misses some of the failed jobs.
+	incomplete bool
+}
+
+// getFailureDetails fetches jobs of the failed runs, counts failed jobs and steps, and
+// fetches the logs URLs of the failed jobs.
+func getFailureDetails(ctx context.Context, client *github.Client, owner, repo string, runs []*github.WorkflowRun) failureDetails {
+	report := dashboard.GetFailureReport(ctx, client.Actions, owner, repo, runs, numWorkers)
+	details := failureDetails{FailureReport: report, incomplete: report.MissingRuns > 0}
+	rate := dashboard.SuccessRate(runs)
+	runsByID := map[int64]*github.WorkflowRun{}
+	for _, run := range runs {
+		runsByID[run.GetID()] = run
+	}
+	var jobLogs []jobLog
+	for _, job := range report.Jobs {
+		jobLogs = append(jobLogs, jobLog{run: runsByID[job.GetRunID()], job: job, successRate: rate})
+	}
+	resolveLogURLs(ctx, client, owner, repo, jobLogs)
+	for _, jl := range jobLogs {
+		if jl.url == nil {
+			details.incomplete = true
+			continue
+		}
+		details.jobLogs = append(details.jobLogs, jl)
+	}
+	return details
+}
+
+// prioritizeJobLogs sorts the job logs from the lowest success rate of their workflows,
+// and keeps the first limit logs unless limit is 0, so that the logs of the least healthy
+// workflows are analyzed first and within sampling limits.
+func prioritizeJobLogs(jobLogs []jobLog, limit int) []jobLog {
+	jobLogs = slices.Clone(jobLogs)
+	slices.SortStableFunc(jobLogs, func(a, b jobLog) int {
+		return cmp.Compare(a.successRate, b.successRate)
+	})
+	if limit > 0 && len(jobLogs) > limit {
+		jobLogs = jobLogs[:limit]
+	}
+	return jobLogs
+}
+
+// logAnalysis contains failed tests and error logs found in job logs.
+type logAnalysis struct {
+	failedTestCount map[string]int
+	// failedTestRuns are the workflow runs each failed test failed in.
+	failedTestRuns map[string][]*github.WorkflowRun
+	errorLogCount  map[string]int
+	// errorLogRuns are the workflow runs each error message was logged in.
+	errorLogRuns map[string][]*github.WorkflowRun
+	// excerpts are a log line for each failed test and error message, along with the
+	// surrounding lines if requested.
+	excerpts map[string]string
+	// errorURLs are logs URLs of jobs with check-log-errors test failures.
+	errorURLs []string
+	// incomplete is set if some of the job logs could not be downloaded or read.
+	incomplete bool
+}
+
+// getJobLog starts downloading a job log. The caller must close the response body.
+func getJobLog(ctx context.Context, logsURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// analyzeLogs downloads the given job logs and finds failed tests and error logs in them.
+// The excerpts include contextLines lines before and after the first match of each failed
+// test and error message.
+func analyzeLogs(ctx context.Context, jobLogs []jobLog, contextLines int) logAnalysis {
+	analysis := logAnalysis{
+		failedTestCount: make(map[string]int),
+		failedTestRuns:  make(map[string][]*github.WorkflowRun),
+		errorLogCount:   make(map[string]int),
+		errorLogRuns:    make(map[string][]*github.WorkflowRun),
+		excerpts:        make(map[string]string),
+	}
+	bar := newProgress("Analyzing job logs", len(jobLogs))
+	tasks := make(chan jobLog)
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for jl := range tasks {
+				// Drain the remaining tasks without requests once the context is cancelled.
+				if ctx.Err() != nil {
+					mux.Lock()
+					analysis.incomplete = true
+					mux.Unlock()
+					continue
+				}
+				logsURL := jl.url.String()
+				resp, err := getJobLog(ctx, logsURL)
+				if err != nil {
+					bar.increment()
+					slog.Error("Failed to get logs", slog.String("url", logsURL), slog.Any("error", err))
+					mux.Lock()
+					analysis.incomplete = true
+					mux.Unlock()
+					continue
+				}
+				// Collect the matches of the log without holding the lock, and merge them
+				// once the whole log is read.
+				var failedTests, errorMsgs []string
+				excerpts := newExcerptCollector(contextLines)
+				err = scanLogLines(resp.Body, func(line string) {
+					excerpts.addLine(line)
+					if match := failedTestRegexp.FindStringSubmatch(line); match != nil && len(failedTests) < maxLogMatches {
+						failedTests = append(failedTests, match[1])
+						excerpts.capture(match[1], strings.TrimSpace(line))
+					}
+					if errorLog := errorLogRegexp.FindString(line); errorLog != "" && len(errorMsgs) < maxLogMatches {
+						if match := errorMsgRegexp.FindStringSubmatch(errorLog); len(match) == 2 {
+							errorMsgs = append(errorMsgs, match[1])
+							excerpts.capture(match[1], strings.TrimSpace(errorLog))
+						}
+					}
+					excerpts.addBefore(line)
+				})
+				resp.Body.Close()
+				bar.increment()
+				mux.Lock()
+				if err != nil {
+					slog.Error("Failed to read response body", slog.String("url", logsURL), slog.Any("error", err))
+					analysis.incomplete = true
+					mux.Unlock()
+					continue
+				}
+				for _, test := range failedTests {
+					analysis.failedTestCount[test]++
+					analysis.failedTestRuns[test] = append(analysis.failedTestRuns[test], jl.run)
+					if _, ok := analysis.excerpts[test]; !ok {
+						analysis.excerpts[test] = excerpts.excerpt(test)
+					}
+					if test == "check-log-errors" {
+						analysis.errorURLs = append(analysis.errorURLs, logsURL)
+					}
+				}
+				for _, msg := range errorMsgs {
+					analysis.errorLogCount[msg]++
+					analysis.errorLogRuns[msg] = append(analysis.errorLogRuns[msg], jl.run)
+					if _, ok := analysis.excerpts[msg]; !ok {
+						analysis.excerpts[msg] = excerpts.excerpt(msg)
+					}
+				}
+				mux.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	for _, jl := range prioritizeJobLogs(jobLogs, 0) {
+		tasks <- jl
+	}
+	close(tasks)
+	wg.Wait()
+	bar.finish()
+	return analysis
+}
+
+// excerptCollector captures the lines around the first match of each failed test and
+// error message while a log is streamed.
+type excerptCollector struct {
+	contextLines int
+	// before are the last contextLines lines.
+	before   []string
+	excerpts map[string]*logExcerpt
+	// pending are the excerpts that still need lines after the match.
+	pending []*logExcerpt
+}
+
+type logExcerpt struct {
+	lines []string
+	after int
+}
+
+func newExcerptCollector(contextLines int) *excerptCollector {
+	return &excerptCollector{contextLines: contextLines, excerpts: map[string]*logExcerpt{}}
+}
+
+// addLine appends the line to the excerpts that still need lines after the match.
+func (c *excerptCollector) addLine(line string) {
+	pending := c.pending[:0]
+	for _, e := range c.pending {
+		e.lines = append(e.lines, line)
+		e.after--
+		if e.after > 0 {
+			pending = append(pending, e)
+		}
+	}
+	c.pending = pending
+}
+
+// capture starts an excerpt of the matched line for the signature, unless there is one
+// already.
+func (c *excerptCollector) capture(signature, matched string) {
+	if _, ok := c.excerpts[signature]; ok {
+		return
+	}
+	e := &logExcerpt{lines: append(slices.Clone(c.before), matched), after: c.contextLines}
+	c.excerpts[signature] = e
+	if e.after > 0 {
+		c.pending = append(c.pending, e)
+	}
+}
+
+// addBefore remembers the line as a context line for the next matches.
+func (c *excerptCollector) addBefore(line string) {
+	if c.contextLines == 0 {
+		return
+	}
+	if len(c.before) == c.contextLines {
+		c.before = c.before[1:]
+	}
+	c.before = append(c.before, line)
+}
+
+func (c *excerptCollector) excerpt(signature string) string {
+	return strings.Join(c.excerpts[signature].lines, "\n")
+}
+
+// scanLogLines calls fn with each line of the log without the line break. Lines longer
+// than maxLogLineSize are truncated, so that memory use is bounded regardless of the log
+// size.
+func scanLogLines(r io.Reader, fn func(line string)) error {
+	br := bufio.NewReaderSize(r, maxLogLineSize)
+	for {
+		line, err := br.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			fn(string(line))
+			// Discard the rest of the line.
+			for errors.Is(err, bufio.ErrBufferFull) {
+				_, err = br.ReadSlice('\n')
+			}
+		} else if len(line) > 0 {
+			fn(strings.TrimRight(string(line), "\r\n"))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// printLogExcerpts prints an excerpt of the logs for each failed test and error message.
+func printLogExcerpts(analysis logAnalysis) {
+	red := color.New(color.FgRed, color.Bold)
+	bold := color.New(color.Bold)
+	red.Println("\nlog excerpts")
+	for _, counts := range []map[string]int{analysis.failedTestCount, analysis.errorLogCount} {
+		for _, count := range dashboard.SortByCount(counts) {
+			bold.Printf("\n%s (%d)\n", count.Name, count.Count)
+			for _, line := range strings.Split(analysis.excerpts[count.Name], "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+}
+
+// printLogAnalysis prints the failed tests and error logs. If there are known issues with
+// a pattern, failures are tagged with the matching known issue, and the number of known
+// and new failures is reported. If a baseline snapshot is given, failed tests and error
+// messages that are not in the baseline are marked as appearing for the first time.
+func printLogAnalysis(analysis logAnalysis, known *knownIssueMatcher, baseline *report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	red := color.New(color.FgRed, color.Bold)
+	tagged := len(known.issues) > 0
+	firstTimeCount := 0
+	// knownSignatures are the failed tests and error messages with a known issue.
+	knownSignatures := map[string]bool{}
+	var knownCount, newCount int
+	printCounts := func(header string, counts, baselineCounts map[string]int) {
+		if baseline != nil {
+			header += "\tsince baseline"
+		}
+		if tagged {
+			header += "\tknown issue"
+		}
+		fmt.Fprintln(w, header)
+		for _, count := range dashboard.SortByCount(counts) {
+			line := fmt.Sprintf("%s\t%d", count.Name, count.Count)
+			if baseline != nil {
+				since := ""
+				if _, ok := baselineCounts[count.Name]; !ok {
+					firstTimeCount++
+					since = color.New(color.FgRed).Sprint("first time")
+				}
+				line += "\t" + since
+			}
+			if tagged {
+				label := ""
+				if k := known.match(count.Name, analysis.excerpts[count.Name]); k != nil {
+					knownSignatures[count.Name] = true
+					knownCount += count.Count
+					label = k.label()
+				} else {
+					newCount += count.Count
+				}
+				line += "\t" + label
+			}
+			fmt.Fprintln(w, line)
+		}
+		w.Flush()
+	}
+	var baselineTests, baselineErrors map[string]int
+	if baseline != nil {
+		baselineTests, baselineErrors = baseline.FailedTests, baseline.ErrorClusters
+	}
+	red.Println("\nfailed tests")
+	printCounts("test name\tfailure count", analysis.failedTestCount, baselineTests)
+	red.Println("\nerror logs")
+	printCounts("error message\tcount", analysis.errorLogCount, baselineErrors)
+	if baseline != nil {
+		color.New(color.FgYellow).Printf("\n%d of %d failed tests and error messages appear for the first time since %s\n",
+			firstTimeCount, len(analysis.failedTestCount)+len(analysis.errorLogCount), formatTime(baseline.GeneratedAt))
+	}
+	if tagged {
+		// A run is known if all of its failures have a known issue.
+		runKnown := map[int64]bool{}
+		for _, signatureRuns := range []map[string][]*github.WorkflowRun{analysis.failedTestRuns, analysis.errorLogRuns} {
+			for signature, runs := range signatureRuns {
+				for _, run := range runs {
+					known, ok := runKnown[run.GetID()]
+					runKnown[run.GetID()] = (!ok || known) && knownSignatures[signature]
+				}
+			}
+		}
+		var knownRuns int
+		for _, known := range runKnown {
+			if known {
+				knownRuns++
+			}
+		}
+		color.New(color.FgYellow).Printf("\n%d known and %d new failures, %d of %d runs failed only because of known issues\n", knownCount, newCount, knownRuns, len(runKnown))
+	}
+	for _, errorLogsURL := range analysis.errorURLs {
+		slog.Debug("Jobs log URL with check-log-errors test failure", slog.String("logs-url", errorLogsURL))
+	}
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v59/github"
+)
+
+func TestPrioritizeJobLogs(t *testing.T) {
+	jobLogs := []jobLog{
+		{job: &github.WorkflowJob{ID: github.Int64(1)}, successRate: 90},
+		{job: &github.WorkflowJob{ID: github.Int64(2)}, successRate: 50},
+		{job: &github.WorkflowJob{ID: github.Int64(3)}, successRate: 90},
+		{job: &github.WorkflowJob{ID: github.Int64(4)}, successRate: 10},
+	}
+	for _, tt := range []struct {
+		limit int
+		want  []int64
+	}{
+		{0, []int64{4, 2, 1, 3}},
+		{2, []int64{4, 2}},
+		{10, []int64{4, 2, 1, 3}},
+	} {
+		var got []int64
+		for _, jl := range prioritizeJobLogs(jobLogs, tt.limit) {
+			got = append(got, jl.job.GetID())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("prioritizeJobLogs(limit %d) = jobs %v, want %v", tt.limit, got, tt.want)
+		}
+	}
+}
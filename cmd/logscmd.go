@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs owner repo",
+	Short: "Download the full logs of workflow runs into a local directory",
+	Long: `Download the full logs of workflow runs into a local directory, for offline grepping
+and retention beyond the log retention period of GitHub. The logs of each run are written
+to <output>/<owner>/<repo>/<workflow>/<run-id>-<attempt>/, along with the run metadata in
+run.json. Runs that were already downloaded are skipped.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		workflowFlag, err := cmd.Flags().GetString("workflow")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		failedOnly, err := cmd.Flags().GetBool("failed-only")
+		if err != nil {
+			return err
+		}
+		extract, err := cmd.Flags().GetBool("extract")
+		if err != nil {
+			return err
+		}
+		var workflows []string
+		if workflowFlag != "" {
+			workflows = append(workflows, workflowFlag)
+		} else {
+			workflows, err = dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+			if err != nil {
+				return err
+			}
+		}
+		printEventWarning(event)
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+		if failedOnly {
+			for workflow, runs := range result {
+				result[workflow] = slices.DeleteFunc(runs, func(run *github.WorkflowRun) bool {
+					return run.GetConclusion() == "success"
+				})
+			}
+		}
+		dir := filepath.Join(output, owner, repo)
+		downloaded, failed := downloadRunLogs(ctx, client, owner, repo, result, dir, extract)
+		fmt.Printf("Downloaded logs of %d runs to %s\n", downloaded, dir)
+		if failed > 0 {
+			return fmt.Errorf("failed to download logs of %d runs", failed)
+		}
+		return nil
+	},
+}
+
+// runLogsDir returns the directory of the logs of the run attempt.
+func runLogsDir(dir, workflow string, run *github.WorkflowRun) string {
+	return filepath.Join(dir, workflow, fmt.Sprintf("%d-%d", run.GetID(), run.GetRunAttempt()))
+}
+
+// downloadRunLogs downloads the logs of the runs in parallel, and returns the number of
+// runs downloaded and failed. Runs whose directory already exists are skipped.
+func downloadRunLogs(ctx context.Context, client *github.Client, owner, repo string, result map[string][]*github.WorkflowRun, dir string, extract bool) (int, int) {
+	type task struct {
+		workflow string
+		run      *github.WorkflowRun
+	}
+	downloaded, failed := 0, 0
+	tasks := make(chan task)
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for t := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				run := t.run
+				runDir := runLogsDir(dir, t.workflow, run)
+				if _, err := os.Stat(runDir); err == nil {
+					slog.Debug("Skipping downloaded run", slog.String("dir", runDir))
+					continue
+				}
+				err := downloadRunLog(ctx, client, owner, repo, run, runDir, extract)
+				mux.Lock()
+				if err != nil {
+					slog.Error("Failed to download logs", slog.Int64("run-id", run.GetID()), slog.Any("error", err))
+					failed++
+				} else {
+					downloaded++
+				}
+				mux.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	for workflow, runs := range result {
+		for _, run := range runs {
+			tasks <- task{workflow: workflow, run: run}
+		}
+	}
+	close(tasks)
+	wg.Wait()
+	return downloaded, failed
+}
+
+// downloadRunLog writes the logs zip of the run, or its extracted files, and the run
+// metadata to the directory. The directory is created only once the download succeeded,
+// so that interrupted downloads are retried.
+func downloadRunLog(ctx context.Context, client *github.Client, owner, repo string, run *github.WorkflowRun, runDir string, extract bool) error {
+	logsURL, _, err := client.Actions.GetWorkflowRunLogs(ctx, owner, repo, run.GetID(), 10)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logsURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	tmpDir := runDir + ".tmp"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	zipFile := filepath.Join(tmpDir, "logs.zip")
+	f, err := os.Create(zipFile)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if extract {
+		if err := extractZip(zipFile, tmpDir); err != nil {
+			return err
+		}
+		if err := os.Remove(zipFile); err != nil {
+			return err
+		}
+	}
+	metadata, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "run.json"), metadata, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpDir, runDir)
+}
+
+// extractZip extracts the files of the zip archive into the directory.
+func extractZip(filename, dir string) error {
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		name := filepath.Join(dir, filepath.FromSlash(f.Name))
+		// Reject paths that escape the directory.
+		if !strings.HasPrefix(name, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file name in zip: %s", f.Name)
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(name, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+		if err := extractZipFile(f, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipFile(f *zip.File, name string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	out, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("duplicate file name in zip: %s", f.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+
+	logsCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	logsCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	logsCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to download per workflow")
+	logsCmd.Flags().StringP("workflow", "w", "", "Workflow name (e.g. aks-byocni.yaml)")
+	logsCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	logsCmd.Flags().StringP("output", "o", "logs", "Directory to download the logs to")
+	logsCmd.Flags().Bool("failed-only", false, "Only download the logs of runs that did not succeed")
+	logsCmd.Flags().Bool("extract", false, "Extract the log files instead of keeping the zip archive of each run")
+}
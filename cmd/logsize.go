@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// estimateLogSize returns the total size of the job logs in bytes, using HEAD requests.
+// Logs whose size is unknown are not counted.
+func estimateLogSize(ctx context.Context, jobLogs []jobLog) int64 {
+	var total int64
+	tasks := make(chan jobLog)
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for jl := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				req, err := http.NewRequestWithContext(ctx, http.MethodHead, jl.url.String(), nil)
+				if err != nil {
+					continue
+				}
+				resp, err := httpClient.Do(req)
+				if err != nil {
+					slog.Debug("Failed to get log size", slog.String("url", jl.url.String()), slog.Any("error", err))
+					continue
+				}
+				resp.Body.Close()
+				if resp.ContentLength > 0 {
+					mux.Lock()
+					total += resp.ContentLength
+					mux.Unlock()
+				}
+			}
+			wg.Done()
+		}()
+	}
+	for _, jl := range jobLogs {
+		tasks <- jl
+	}
+	close(tasks)
+	wg.Wait()
+	return total
+}
+
+// confirmLogDownload returns true if the logs may be downloaded. If their total size
+// exceeds maxSize megabytes, it asks for confirmation on a terminal, and returns an error
+// otherwise unless yes is set. It also returns whether it asked, since the logs URLs
+// expire after a minute and need to be refreshed with resolveLogURLs afterwards.
+func confirmLogDownload(ctx context.Context, jobLogs []jobLog, maxSize int64, yes bool) (ok, asked bool, err error) {
+	if yes || maxSize <= 0 || len(jobLogs) == 0 {
+		return true, false, nil
+	}
+	size := estimateLogSize(ctx, jobLogs)
+	if size <= maxSize<<20 {
+		return true, false, nil
+	}
+	message := fmt.Sprintf("%d job logs are %.1f MB in total, above the limit of %d MB", len(jobLogs), float64(size)/(1<<20), maxSize)
+	ok, err = confirm(message, "download them")
+	return ok, true, err
+}
+
+// resolveLogURLs fetches the logs URLs of the jobs in place. URLs that fail to fetch are
+// left as is, so that they are nil or expired URLs that fail to download later.
+func resolveLogURLs(ctx context.Context, client *github.Client, owner, repo string, jobLogs []jobLog) {
+	bar := newProgress("Fetching logs URLs of failed jobs", len(jobLogs))
+	tasks := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for i := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				logsURL, err := dashboard.GetJobLogsURL(ctx, client.Actions, owner, repo, jobLogs[i].job.GetID())
+				bar.increment()
+				if err != nil {
+					slog.Error("Failed to get logs URL", slog.Int64("job", jobLogs[i].job.GetID()), slog.Any("error", err))
+					continue
+				}
+				jobLogs[i].url = logsURL
+			}
+			wg.Done()
+		}()
+	}
+	for i := range jobLogs {
+		tasks <- i
+	}
+	close(tasks)
+	wg.Wait()
+	bar.finish()
+}
+
+// confirm asks whether to go ahead with the action on a terminal, and returns an error
+// asking to pass --yes if stdin is not a terminal.
+func confirm(message, action string) (bool, error) {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false, fmt.Errorf("%s, pass --yes to %s", message, action)
+	}
+	fmt.Fprintf(os.Stderr, "%s. %s? [y/N] ", message, strings.ToUpper(action[:1])+action[1:])
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
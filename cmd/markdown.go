@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// healthGrade returns a letter grade for the overall success rate.
+func healthGrade(rate float32) string {
+	switch {
+	case rate >= 95:
+		return "A"
+	case rate >= 80:
+		return "B"
+	case rate >= 50:
+		return "C"
+	default:
+		return "F"
+	}
+}
+
+// overallSuccessRate returns the success rate across all the workflow runs.
+func overallSuccessRate(result map[string][]*github.WorkflowRun) (float32, int, int) {
+	success, count := 0, 0
+	for _, runs := range result {
+		for _, run := range runs {
+			if run.GetConclusion() == "success" {
+				success++
+			}
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, 0, 0
+	}
+	return 100 * float32(success) / float32(count), success, count
+}
+
+// markdownSummary renders the workflow stats as a Markdown table, lowest success rate first.
+func markdownSummary(cfg *config, owner, repo, branch, event string, result map[string][]*github.WorkflowRun) string {
+	var sb strings.Builder
+	rate, success, count := overallSuccessRate(result)
+	fmt.Fprintf(&sb, "**CI health: %s** (%0.f%% %d/%d runs succeeded, branch `%s`, event `%s`)\n\n",
+		healthGrade(rate), rate, success, count, branch, event)
+	sb.WriteString("| workflow | success rate | average duration | |\n")
+	sb.WriteString("| --- | --- | --- | --- |\n")
+	for _, workflow := range sortWorkflowsBySuccessRate(result) {
+		runs := result[workflow]
+		if len(runs) == 0 {
+			continue
+		}
+		workflowURL := fmt.Sprintf("https://github.com/%s/%s/actions/workflows/%s?query=branch%%3A%s+event%%3A%s++",
+			owner, repo, workflow, branch, event)
+		note := ""
+		if k := cfg.getKnownIssue(workflow); k != nil {
+			note = fmt.Sprintf("[known issue](%s)", k.Issue)
+		}
+		fmt.Fprintf(&sb, "| [%s](%s) | %0.f%% | %s | %s |\n",
+			workflow, workflowURL, dashboard.SuccessRate(runs), dashboard.AverageSuccessDuration(runs, runDurations), note)
+	}
+	fmt.Fprintf(&sb, "\nGenerated by ci-dashboard at %s.\n", time.Now().UTC().Format(time.DateTime+" MST"))
+	return sb.String()
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// matrixJobRegexp matches matrix job names like "e2e (1.29, cilium, ipv6)".
+var matrixJobRegexp = regexp.MustCompile(`^(.*?) \((.*)\)$`)
+
+// parseMatrixJobName splits a matrix job name into the job name and the matrix values.
+func parseMatrixJobName(name string) (string, []string, bool) {
+	match := matrixJobRegexp.FindStringSubmatch(name)
+	if match == nil {
+		return name, nil, false
+	}
+	return match[1], strings.Split(match[2], ", "), true
+}
+
+type matrixValueStats struct {
+	job      string
+	position int
+	value    string
+	failed   int
+	count    int
+}
+
+func getMatrixStats(jobs map[int64][]*github.WorkflowJob) []*matrixValueStats {
+	type key struct {
+		job      string
+		position int
+		value    string
+	}
+	statsMap := map[key]*matrixValueStats{}
+	for _, runJobs := range jobs {
+		for _, job := range runJobs {
+			if job.GetConclusion() != "success" && job.GetConclusion() != "failure" {
+				continue
+			}
+			name, values, ok := parseMatrixJobName(job.GetName())
+			if !ok {
+				continue
+			}
+			for i, value := range values {
+				k := key{job: name, position: i, value: value}
+				stats, ok := statsMap[k]
+				if !ok {
+					stats = &matrixValueStats{job: name, position: i, value: value}
+					statsMap[k] = stats
+				}
+				if job.GetConclusion() == "failure" {
+					stats.failed++
+				}
+				stats.count++
+			}
+		}
+	}
+	var statsList []*matrixValueStats
+	for _, stats := range statsMap {
+		statsList = append(statsList, stats)
+	}
+	slices.SortFunc(statsList, func(a, b *matrixValueStats) int {
+		return cmp.Or(cmp.Compare(a.job, b.job), cmp.Compare(a.position, b.position),
+			cmp.Compare(float32(b.failed)/float32(b.count), float32(a.failed)/float32(a.count)), cmp.Compare(a.value, b.value))
+	})
+	return statsList
+}
+
+// printMatrixStats prints failure rates of matrix jobs per matrix value, so that failures
+// specific to a value (e.g. a Kubernetes version) stand out.
+func printMatrixStats(ctx context.Context, client *github.Client, owner, repo string, result map[string][]*github.WorkflowRun) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	red := color.New(color.FgRed).SprintFunc()
+	for _, workflow := range sortWorkflowsBySuccessRate(result) {
+		statsList := getMatrixStats(dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, result[workflow], numWorkers))
+		if len(statsList) == 0 {
+			continue
+		}
+		color.New(color.Bold).Printf("\nmatrix dimensions of %s\n", workflow)
+		fmt.Fprintln(w, "job name\tdimension\tvalue\tfailure rate")
+		for _, stats := range statsList {
+			rate := fmt.Sprintf("%0.f%% %d/%d", 100*float32(stats.failed)/float32(stats.count), stats.failed, stats.count)
+			if stats.failed == stats.count {
+				rate = red(rate)
+			}
+			fmt.Fprintln(w, fmt.Sprintf("%s\t%d\t%s\t%s", stats.job, stats.position+1, stats.value, rate))
+		}
+		w.Flush()
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// metadataRule extracts a value such as a component version from job logs. The value is
+// the first capture group of the regular expression.
+type metadataRule struct {
+	Name  string `yaml:"name"`
+	Regex string `yaml:"regex"`
+}
+
+// metadataRules returns the metadata rules with the given name, or an error if the
+// configuration file has none.
+func (c *config) metadataRules(name string) ([]metadataRule, error) {
+	var rules []metadataRule
+	var names []string
+	for _, rule := range c.Metadata {
+		if rule.Name == name {
+			rules = append(rules, rule)
+		}
+		names = append(names, rule.Name)
+	}
+	if len(rules) == 0 {
+		if len(names) == 0 {
+			return nil, fmt.Errorf("unknown metadata key %q, the configuration file has no metadata rules", name)
+		}
+		return nil, fmt.Errorf("unknown metadata key %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+	return rules, nil
+}
+
+// getRunMetadata downloads job logs of each run until all the rules have matched, and
+// returns the extracted values keyed by run ID and rule name.
+func getRunMetadata(ctx context.Context, client *github.Client, owner, repo string, runs []*github.WorkflowRun, rules []metadataRule) (map[int64]map[string]string, error) {
+	regexps := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		r, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex for metadata %s: %w", rule.Name, err)
+		}
+		if r.NumSubexp() < 1 {
+			return nil, fmt.Errorf("regex for metadata %s must have a capture group", rule.Name)
+		}
+		regexps[i] = r
+	}
+	result := map[int64]map[string]string{}
+	if len(rules) == 0 {
+		return result, nil
+	}
+	tasks := make(chan *github.WorkflowRun)
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for run := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				jobs, err := dashboard.GetJobs(ctx, client.Actions, owner, repo, run.GetID())
+				if err != nil {
+					slog.Error("Failed to get jobs", slog.Int64("run-id", run.GetID()), slog.Any("error", err))
+					continue
+				}
+				values := map[string]string{}
+				for _, job := range jobs {
+					if len(values) == len(rules) {
+						break
+					}
+					logs, err := getJobLogs(ctx, client, owner, repo, job.GetID())
+					if err != nil {
+						slog.Debug("Failed to get job logs", slog.Int64("job-id", job.GetID()), slog.Any("error", err))
+						continue
+					}
+					for i, r := range regexps {
+						if _, ok := values[rules[i].Name]; ok {
+							continue
+						}
+						if match := r.FindSubmatch(logs); match != nil {
+							values[rules[i].Name] = string(match[1])
+						}
+					}
+				}
+				mux.Lock()
+				result[run.GetID()] = values
+				mux.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	for _, run := range runs {
+		tasks <- run
+	}
+	close(tasks)
+	wg.Wait()
+	return result, nil
+}
+
+// printMetadataGroups prints success rates of the runs grouped by the value of the
+// metadata key.
+func printMetadataGroups(key string, result map[string][]*github.WorkflowRun, metadata map[int64]map[string]string) {
+	type group struct {
+		value   string
+		success int
+		count   int
+	}
+	groups := map[string]*group{}
+	for _, runs := range result {
+		for _, run := range runs {
+			value, ok := metadata[run.GetID()][key]
+			if !ok {
+				value = "unknown"
+			}
+			g, ok := groups[value]
+			if !ok {
+				g = &group{value: value}
+				groups[value] = g
+			}
+			if run.GetConclusion() == "success" {
+				g.success++
+			}
+			g.count++
+		}
+	}
+	var groupList []*group
+	for _, g := range groups {
+		groupList = append(groupList, g)
+	}
+	slices.SortFunc(groupList, func(a, b *group) int {
+		return cmp.Compare(a.value, b.value)
+	})
+	color.New(color.Bold).Printf("\n%s\n", key)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, fmt.Sprintf("%s\tsuccess rate", key))
+	for _, g := range groupList {
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%0.f%% %d/%d", g.value, 100*float32(g.success)/float32(g.count), g.success, g.count))
+	}
+	w.Flush()
+}
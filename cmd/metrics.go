@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var pushMetricsCmd = &cobra.Command{
+	Use:   "push-metrics owner repo",
+	Short: "Push per-run and per-workflow metrics to a time-series database",
+	Long: `Push per-run and per-workflow metrics to a time-series database.
+
+The influx format writes InfluxDB line protocol to --url (e.g.
+http://localhost:8086/api/v2/write?org=ci&bucket=ci&precision=s), authenticated with
+the INFLUX_TOKEN environment variable, or to stdout if --url is not set.
+
+The timescale format writes SQL statements for TimescaleDB to stdout, which can be
+piped to psql.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		url, err := cmd.Flags().GetString("url")
+		if err != nil {
+			return err
+		}
+		workflows, err := dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+		if err != nil {
+			return err
+		}
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+		var buf bytes.Buffer
+		switch format {
+		case "influx":
+			writeInfluxLines(&buf, owner, repo, result, time.Now())
+		case "timescale":
+			writeTimescaleSQL(&buf, owner, repo, result, time.Now())
+		default:
+			return fmt.Errorf("unknown format %q", format)
+		}
+		if url == "" || format != "influx" {
+			_, err = io.Copy(os.Stdout, &buf)
+			return err
+		}
+		url, err = influxWriteURL(url)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &buf)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Token "+os.Getenv("INFLUX_TOKEN"))
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+		return nil
+	},
+}
+
+// influxWriteURL adds precision=s to the query of the InfluxDB write API URL if it is
+// missing, since InfluxDB defaults to nanoseconds but the timestamps are in seconds.
+func influxWriteURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid --url: %w", err)
+	}
+	query := u.Query()
+	switch precision := query.Get("precision"); precision {
+	case "s":
+		return rawURL, nil
+	case "":
+		query.Set("precision", "s")
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	default:
+		return "", fmt.Errorf("--url has precision=%s, but the timestamps are in seconds", precision)
+	}
+}
+
+var influxTagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+// writeInfluxLines writes a ci_run point for each run and a ci_workflow point for each
+// workflow in InfluxDB line protocol with second precision.
+func writeInfluxLines(out io.Writer, owner, repo string, result map[string][]*github.WorkflowRun, now time.Time) {
+	for workflow, runs := range result {
+		tags := fmt.Sprintf("repo=%s,workflow=%s", influxTagEscaper.Replace(owner+"/"+repo), influxTagEscaper.Replace(workflow))
+		for _, run := range runs {
+			success := 0
+			if run.GetConclusion() == "success" {
+				success = 1
+			}
+			fmt.Fprintf(out, "ci_run,%s,conclusion=%s success=%di,duration=%.0f,attempt=%di,id=%di %d\n",
+				tags, run.GetConclusion(), success, runDurations.Of(run).Seconds(), run.GetRunAttempt(), run.GetID(), run.GetRunStartedAt().Unix())
+		}
+		if len(runs) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "ci_workflow,%s success_rate=%.2f,average_duration=%.0f,runs=%di %d\n",
+			tags, dashboard.SuccessRate(runs), dashboard.AverageSuccessDuration(runs, runDurations).Seconds(), len(runs), now.Unix())
+	}
+}
+
+// writeTimescaleSQL writes SQL statements that create ci_runs and ci_workflows hypertables
+// and insert the metrics into them.
+func writeTimescaleSQL(out io.Writer, owner, repo string, result map[string][]*github.WorkflowRun, now time.Time) {
+	quote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	fmt.Fprintln(out, `CREATE TABLE IF NOT EXISTS ci_runs (time TIMESTAMPTZ NOT NULL, repo TEXT, workflow TEXT, id BIGINT, conclusion TEXT, duration DOUBLE PRECISION, attempt INTEGER, UNIQUE (time, id));`)
+	fmt.Fprintln(out, `SELECT create_hypertable('ci_runs', 'time', if_not_exists => TRUE);`)
+	fmt.Fprintln(out, `CREATE TABLE IF NOT EXISTS ci_workflows (time TIMESTAMPTZ NOT NULL, repo TEXT, workflow TEXT, success_rate DOUBLE PRECISION, average_duration DOUBLE PRECISION, runs INTEGER);`)
+	fmt.Fprintln(out, `SELECT create_hypertable('ci_workflows', 'time', if_not_exists => TRUE);`)
+	for workflow, runs := range result {
+		for _, run := range runs {
+			fmt.Fprintf(out, "INSERT INTO ci_runs VALUES (%s, %s, %s, %d, %s, %.0f, %d) ON CONFLICT DO NOTHING;\n",
+				quote(run.GetRunStartedAt().UTC().Format(time.RFC3339)), quote(owner+"/"+repo), quote(workflow),
+				run.GetID(), quote(run.GetConclusion()), runDurations.Of(run).Seconds(), run.GetRunAttempt())
+		}
+		if len(runs) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "INSERT INTO ci_workflows VALUES (%s, %s, %s, %.2f, %.0f, %d);\n",
+			quote(now.UTC().Format(time.RFC3339)), quote(owner+"/"+repo), quote(workflow),
+			dashboard.SuccessRate(runs), dashboard.AverageSuccessDuration(runs, runDurations).Seconds(), len(runs))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(pushMetricsCmd)
+
+	pushMetricsCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	pushMetricsCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	pushMetricsCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	pushMetricsCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	pushMetricsCmd.Flags().StringP("format", "f", "influx", "Metrics format (influx, timescale)")
+	pushMetricsCmd.Flags().String("url", "", "InfluxDB write API URL, with precision=s added if missing. Metrics are written to stdout if not set")
+}
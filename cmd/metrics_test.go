@@ -0,0 +1,23 @@
+package cmd
+
+import "testing"
+
+func TestInfluxWriteURL(t *testing.T) {
+	for _, tt := range []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{"http://localhost:8086/api/v2/write?org=ci&bucket=ci", "http://localhost:8086/api/v2/write?bucket=ci&org=ci&precision=s", false},
+		{"http://localhost:8086/api/v2/write?org=ci&bucket=ci&precision=s", "http://localhost:8086/api/v2/write?org=ci&bucket=ci&precision=s", false},
+		{"http://localhost:8086/api/v2/write?org=ci&bucket=ci&precision=ns", "", true},
+	} {
+		got, err := influxWriteURL(tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("influxWriteURL(%q) returned %v, want error %t", tt.url, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("influxWriteURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
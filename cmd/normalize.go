@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// nameRule rewrites job and step names matching the regular expression, so that names
+// embedding parameters like regions (e.g. "aks (westus2)") are aggregated together.
+type nameRule struct {
+	Regex       string `yaml:"regex"`
+	Replacement string `yaml:"replacement"`
+}
+
+type nameNormalizer struct {
+	regexps      []*regexp.Regexp
+	replacements []string
+}
+
+func newNameNormalizer(rules []nameRule) (*nameNormalizer, error) {
+	n := &nameNormalizer{}
+	for _, rule := range rules {
+		r, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name normalization regex %q: %w", rule.Regex, err)
+		}
+		n.regexps = append(n.regexps, r)
+		n.replacements = append(n.replacements, rule.Replacement)
+	}
+	return n, nil
+}
+
+// normalize applies all the rules to the name in order.
+func (n *nameNormalizer) normalize(name string) string {
+	for i, r := range n.regexps {
+		name = r.ReplaceAllString(name, n.replacements[i])
+	}
+	return name
+}
+
+// normalizeCounts merges counts of names that normalize to the same name.
+func (n *nameNormalizer) normalizeCounts(counts map[string]int) map[string]int {
+	result := make(map[string]int, len(counts))
+	for name, count := range counts {
+		result[n.normalize(name)] += count
+	}
+	return result
+}
+
+// normalizeURLs returns the URLs keyed by normalized names. If multiple names normalize
+// to the same name, any one of their URLs is kept.
+func (n *nameNormalizer) normalizeURLs(urls map[string]string) map[string]string {
+	result := make(map[string]string, len(urls))
+	for name, url := range urls {
+		result[n.normalize(name)] = url
+	}
+	return result
+}
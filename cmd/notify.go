@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// alert is a workflow that needs attention.
+type alert struct {
+	Owner       string  `json:"owner"`
+	Repo        string  `json:"repo"`
+	Workflow    string  `json:"workflow"`
+	URL         string  `json:"url"`
+	SuccessRate float32 `json:"successRate"`
+	// Streak is the number of consecutive failed runs.
+	Streak  int    `json:"streak"`
+	Message string `json:"message"`
+}
+
+// notifierConfig configures where alerts are sent to, and which alerts are sent.
+type notifierConfig struct {
+	Name string `yaml:"name"`
+	// Type is one of slack, pagerduty, or webhook.
+	Type string `yaml:"type"`
+	// URL is the Slack incoming webhook URL or the generic webhook URL.
+	URL string `yaml:"url"`
+	// RoutingKey is the PagerDuty integration key. It can be specified with an
+	// environment variable reference like $PAGERDUTY_ROUTING_KEY.
+	RoutingKey string      `yaml:"routingKey"`
+	Filter     alertFilter `yaml:"filter"`
+}
+
+// alertFilter selects the alerts sent to a notifier. An empty filter matches all alerts.
+type alertFilter struct {
+	// Workflows are glob patterns of workflow file names.
+	Workflows []string `yaml:"workflows"`
+	// MinStreak is the minimum number of consecutive failed runs.
+	MinStreak int `yaml:"minStreak"`
+	// MaxSuccessRate is the maximum success rate in percent.
+	MaxSuccessRate float32 `yaml:"maxSuccessRate"`
+}
+
+func (f alertFilter) match(a alert) bool {
+	if len(f.Workflows) > 0 && !matchAny(f.Workflows, a.Workflow) {
+		return false
+	}
+	if a.Streak < f.MinStreak {
+		return false
+	}
+	if f.MaxSuccessRate > 0 && a.SuccessRate > f.MaxSuccessRate {
+		return false
+	}
+	return true
+}
+
+// notify sends the alerts to the notifier.
+func notify(ctx context.Context, n notifierConfig, alerts []alert) error {
+	var err error
+	switch n.Type {
+	case "slack":
+		err = notifySlack(ctx, n, alerts)
+	case "pagerduty":
+		err = notifyPagerDuty(ctx, n, alerts)
+	case "webhook":
+		err = postJSON(ctx, n.URL, alerts)
+	default:
+		err = fmt.Errorf("unknown notifier type %q", n.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("notifier %s: %w", n.Name, err)
+	}
+	return nil
+}
+
+func notifySlack(ctx context.Context, n notifierConfig, alerts []alert) error {
+	var lines []string
+	for _, a := range alerts {
+		lines = append(lines, fmt.Sprintf("<%s|%s/%s %s>: %s", a.URL, a.Owner, a.Repo, a.Workflow, a.Message))
+	}
+	return postJSON(ctx, n.URL, map[string]string{"text": strings.Join(lines, "\n")})
+}
+
+func notifyPagerDuty(ctx context.Context, n notifierConfig, alerts []alert) error {
+	for _, a := range alerts {
+		event := map[string]any{
+			"routing_key":  os.ExpandEnv(n.RoutingKey),
+			"event_action": "trigger",
+			"dedup_key":    fmt.Sprintf("%s/%s/%s", a.Owner, a.Repo, a.Workflow),
+			"payload": map[string]any{
+				"summary":  fmt.Sprintf("%s/%s %s: %s", a.Owner, a.Repo, a.Workflow, a.Message),
+				"source":   a.URL,
+				"severity": "error",
+			},
+		}
+		if err := postJSON(ctx, "https://events.pagerduty.com/v2/enqueue", event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func postJSON(ctx context.Context, url string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, os.ExpandEnv(url), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// getOrgWorkflowRuns returns workflow runs keyed by repository and workflow file name.
+func getOrgWorkflowRuns(ctx context.Context, client *github.Client, org string, repos []string, branch, event string, count int, created string) map[string]map[string][]*github.WorkflowRun {
+	result := map[string]map[string][]*github.WorkflowRun{}
+	for _, repo := range repos {
+		workflows, err := dashboard.GetWorkflows(ctx, client.Actions, org, repo)
+		if err != nil {
+			slog.Error("Failed to get workflows", slog.String("repo", repo), slog.Any("error", err))
+			continue
+		}
+		result[repo] = getWorkflowRunsForWorkflows(ctx, client, org, repo, branch, workflows, event, count, created)
+	}
+	return result
+}
+
+func printOrgSummary(org string, result map[string]map[string][]*github.WorkflowRun, top int) {
+	type repoStats struct {
+		repo      string
+		workflows int
+		success   int
+		count     int
+	}
+	type orgWorkflowStats struct {
+		repo        string
+		workflow    string
+		successRate float32
+		count       int
+	}
+	var repoStatsList []repoStats
+	var workflowStatsList []orgWorkflowStats
+	for repo, workflows := range result {
+		stats := repoStats{repo: repo}
+		for workflow, runs := range workflows {
+			if len(runs) == 0 {
+				continue
+			}
+			stats.workflows++
+			for _, run := range runs {
+				if run.GetConclusion() == "success" {
+					stats.success++
+				}
+				stats.count++
+			}
+			workflowStatsList = append(workflowStatsList, orgWorkflowStats{
+				repo:        repo,
+				workflow:    workflow,
+				successRate: dashboard.SuccessRate(runs),
+				count:       len(runs),
+			})
+		}
+		if stats.count > 0 {
+			repoStatsList = append(repoStatsList, stats)
+		}
+	}
+	slices.SortFunc(repoStatsList, func(a, b repoStats) int {
+		return cmp.Or(cmp.Compare(float32(a.success)/float32(a.count), float32(b.success)/float32(b.count)), cmp.Compare(a.repo, b.repo))
+	})
+	slices.SortFunc(workflowStatsList, func(a, b orgWorkflowStats) int {
+		return cmp.Or(cmp.Compare(a.successRate, b.successRate), cmp.Compare(a.repo, b.repo), cmp.Compare(a.workflow, b.workflow))
+	})
+	link := color.New(color.FgCyan, color.Bold).SprintFunc()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "success rate\tworkflows\trepository")
+	for _, stats := range repoStatsList {
+		repoURL := fmt.Sprintf("https://github.com/%s/%s/actions", org, stats.repo)
+		fmt.Fprintln(w, fmt.Sprintf("%0.f%% %d/%d\t%d\t%s",
+			100*float32(stats.success)/float32(stats.count), stats.success, stats.count, stats.workflows, link(getLink(repoURL, stats.repo))))
+	}
+	w.Flush()
+	fmt.Fprintln(w, "\nsuccess rate\truns\tworkflow")
+	for i, stats := range workflowStatsList {
+		if i >= top {
+			break
+		}
+		workflowURL := fmt.Sprintf("https://github.com/%s/%s/actions/workflows/%s", org, stats.repo, stats.workflow)
+		fmt.Fprintln(w, fmt.Sprintf("%0.f%%\t%d\t%s",
+			stats.successRate, stats.count, link(getLink(workflowURL, stats.repo+"/"+stats.workflow))))
+	}
+	w.Flush()
+}
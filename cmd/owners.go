@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// unownedTeam is the team of workflows that match no owner rule and no CODEOWNERS entry.
+const unownedTeam = "unowned"
+
+// codeownersPaths are the locations GitHub looks for the CODEOWNERS file, in order.
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// ownerRule maps workflows and jobs to the team that owns them.
+type ownerRule struct {
+	Team string `yaml:"team"`
+	// Workflows are file name patterns (e.g. conformance-*.yaml).
+	Workflows []string `yaml:"workflows"`
+	// Jobs are regular expressions of job names. Failed jobs are attributed to the team
+	// even if the workflow is owned by another team.
+	Jobs []string `yaml:"jobs"`
+}
+
+// codeownersRule is a line of a CODEOWNERS file.
+type codeownersRule struct {
+	pattern string
+	owners  []string
+}
+
+// getCodeowners returns the rules of the CODEOWNERS file of the repository, or nil if
+// there is none.
+func getCodeowners(ctx context.Context, client *github.Client, owner, repo, branch string) []codeownersRule {
+	for _, p := range codeownersPaths {
+		file, _, _, err := client.Repositories.GetContents(ctx, owner, repo, p, &github.RepositoryContentGetOptions{Ref: branch})
+		if err != nil {
+			slog.Debug("Failed to get CODEOWNERS", slog.String("path", p), slog.Any("error", err))
+			continue
+		}
+		content, err := file.GetContent()
+		if err != nil {
+			slog.Error("Failed to decode CODEOWNERS", slog.String("path", p), slog.Any("error", err))
+			return nil
+		}
+		return parseCodeowners(content)
+	}
+	return nil
+}
+
+func parseCodeowners(content string) []codeownersRule {
+	var rules []codeownersRule
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		rules = append(rules, codeownersRule{pattern: fields[0], owners: fields[1:]})
+	}
+	return rules
+}
+
+// codeownersMatch returns true if the CODEOWNERS pattern matches the file path. It
+// supports the common subset of the gitignore syntax: anchored patterns, directories,
+// and * and ** wildcards.
+func codeownersMatch(pattern, file string) bool {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	if !anchored {
+		pattern = "**/" + pattern
+	}
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			re.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			re.WriteString("[^/]*")
+		case pattern[i] == '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	// A pattern without a wildcard at the end also matches everything in the directory.
+	// Unlike gitignore, CODEOWNERS doesn't match nested files for dir/*.
+	if !strings.HasSuffix(pattern, "*") {
+		re.WriteString("(/.*)?")
+	}
+	re.WriteString("$")
+	ok, _ := regexp.MatchString(re.String(), file)
+	return ok
+}
+
+// getWorkflowTeams returns the teams that own the workflow. Owner rules take precedence
+// over CODEOWNERS, where the last matching rule wins.
+func getWorkflowTeams(rules []ownerRule, codeowners []codeownersRule, workflow string) []string {
+	var teams []string
+	for _, rule := range rules {
+		if matchAny(rule.Workflows, workflow) && !slices.Contains(teams, rule.Team) {
+			teams = append(teams, rule.Team)
+		}
+	}
+	if len(teams) > 0 {
+		return teams
+	}
+	for i := len(codeowners) - 1; i >= 0; i-- {
+		if codeownersMatch(codeowners[i].pattern, path.Join(".github/workflows", workflow)) {
+			if len(codeowners[i].owners) == 0 {
+				break
+			}
+			return codeowners[i].owners
+		}
+	}
+	return []string{unownedTeam}
+}
+
+type teamStats struct {
+	team    string
+	success int
+	count   int
+	// workflows are the workflows the team owns.
+	workflows []string
+	// failing are the owned workflows whose latest run failed.
+	failing []string
+	// failedJobs counts failed jobs attributed to the team by job rules.
+	failedJobs map[string]int
+}
+
+func getTeamStats(rules []ownerRule, codeowners []codeownersRule, result map[string][]*github.WorkflowRun, jobs map[int64][]*github.WorkflowJob) ([]*teamStats, error) {
+	statsMap := map[string]*teamStats{}
+	getStats := func(team string) *teamStats {
+		stats, ok := statsMap[team]
+		if !ok {
+			stats = &teamStats{team: team, failedJobs: map[string]int{}}
+			statsMap[team] = stats
+		}
+		return stats
+	}
+	for workflow, runs := range result {
+		for _, team := range getWorkflowTeams(rules, codeowners, workflow) {
+			stats := getStats(team)
+			stats.workflows = append(stats.workflows, workflow)
+			if len(runs) > 0 && runs[0].GetConclusion() != "success" {
+				stats.failing = append(stats.failing, workflow)
+			}
+			for _, run := range runs {
+				if run.GetConclusion() == "success" {
+					stats.success++
+				}
+				stats.count++
+			}
+		}
+	}
+	for _, rule := range rules {
+		for _, pattern := range rule.Jobs {
+			r, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid job regex for team %s: %w", rule.Team, err)
+			}
+			for _, runJobs := range jobs {
+				for _, job := range runJobs {
+					if job.GetConclusion() == "failure" && r.MatchString(job.GetName()) {
+						getStats(rule.Team).failedJobs[job.GetName()]++
+					}
+				}
+			}
+		}
+	}
+	var statsList []*teamStats
+	for _, stats := range statsMap {
+		slices.Sort(stats.workflows)
+		slices.Sort(stats.failing)
+		statsList = append(statsList, stats)
+	}
+	slices.SortFunc(statsList, func(a, b *teamStats) int {
+		return cmp.Compare(a.team, b.team)
+	})
+	return statsList, nil
+}
+
+// printTeams prints the health of the workflows and jobs each team owns, along with the
+// failing workflows to route to the team.
+func printTeams(ctx context.Context, client *github.Client, rules []ownerRule, owner, repo, branch, event string, result map[string][]*github.WorkflowRun) error {
+	codeowners := getCodeowners(ctx, client, owner, repo, branch)
+	var jobs map[int64][]*github.WorkflowJob
+	if slices.ContainsFunc(rules, func(rule ownerRule) bool { return len(rule.Jobs) > 0 }) {
+		var failedRuns []*github.WorkflowRun
+		for _, runs := range result {
+			for _, run := range runs {
+				if run.GetConclusion() == "failure" {
+					failedRuns = append(failedRuns, run)
+				}
+			}
+		}
+		jobs = dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, failedRuns, numWorkers)
+	}
+	statsList, err := getTeamStats(rules, codeowners, result, jobs)
+	if err != nil {
+		return err
+	}
+	color.New(color.Bold).Println("\nteams")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "team\tsuccess rate\tworkflows\tfailing")
+	for _, stats := range statsList {
+		rate := "N/A"
+		if stats.count > 0 {
+			rate = fmt.Sprintf("%0.f%% %d/%d", 100*float32(stats.success)/float32(stats.count), stats.success, stats.count)
+		}
+		failing := ""
+		if len(stats.failing) > 0 {
+			failing = color.New(color.FgRed).Sprintf("%d", len(stats.failing))
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%d\t%s", stats.team, rate, len(stats.workflows), failing))
+	}
+	w.Flush()
+	link := color.New(color.FgCyan).SprintFunc()
+	for _, stats := range statsList {
+		if len(stats.failing) == 0 && len(stats.failedJobs) == 0 {
+			continue
+		}
+		color.New(color.FgRed, color.Bold).Printf("\n%s\n", stats.team)
+		for _, workflow := range stats.failing {
+			workflowURL := fmt.Sprintf("https://github.com/%s/%s/actions/workflows/%s?query=branch%%3A%s+event%%3A%s++",
+				owner, repo, workflow, branch, event)
+			fmt.Printf("  %s\n", link(getLink(workflowURL, workflow)))
+		}
+		var failedJobs []string
+		for job := range stats.failedJobs {
+			failedJobs = append(failedJobs, job)
+		}
+		slices.SortFunc(failedJobs, func(a, b string) int {
+			return cmp.Or(cmp.Compare(stats.failedJobs[b], stats.failedJobs[a]), cmp.Compare(a, b))
+		})
+		for _, job := range failedJobs {
+			fmt.Printf("  %s failed %d times\n", job, stats.failedJobs[job])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import "testing"
+
+func TestCodeownersMatch(t *testing.T) {
+	for _, tt := range []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		// Unanchored patterns match at any depth.
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/root.go", true},
+		{"*.go", "main.gox", false},
+		{"Makefile", "Makefile", true},
+		{"Makefile", "images/Makefile", true},
+		{"Makefile", "images/Makefile/run.sh", true},
+		// Anchored patterns match relative to the root.
+		{"/Makefile", "Makefile", true},
+		{"/Makefile", "images/Makefile", false},
+		{"cmd/root.go", "cmd/root.go", true},
+		{"cmd/root.go", "pkg/cmd/root.go", false},
+		// Directories match everything in them.
+		{"docs/", "docs/index.md", true},
+		{"docs/", "docs/api/index.md", true},
+		{"docs/", "pkg/docs/index.md", true},
+		{"/docs/", "pkg/docs/index.md", false},
+		{"/docs", "docs/api/index.md", true},
+		{"/docs", "docsite/index.md", false},
+		// dir/* doesn't match nested files.
+		{"docs/*", "docs/index.md", true},
+		{"docs/*", "docs/api/index.md", false},
+		{".github/*", ".github/CODEOWNERS", true},
+		{".github/*", ".github/workflows/tests.yaml", false},
+		{"docs/**", "docs/api/index.md", true},
+		{"**/logs", "logs/app.log", true},
+		{"**/logs", "build/logs/app.log", true},
+		{"docs/**/*.md", "docs/index.md", true},
+		{"docs/**/*.md", "docs/api/v1/index.md", true},
+		{"docs/**/*.md", "docs/api/index.txt", false},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"*", "any/file", true},
+	} {
+		if got := codeownersMatch(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("codeownersMatch(%q, %q) = %t, want %t", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
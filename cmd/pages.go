@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// publishToBranch commits the file to the branch with the Git data API, creating the
+// branch as an orphan branch if it does not exist. It is used to publish reports to the
+// GitHub Pages branch without a local checkout.
+func publishToBranch(ctx context.Context, client *github.Client, owner, repo, branch, filename string, content []byte, message string) error {
+	ref, _, err := client.Git.GetRef(ctx, owner, repo, "heads/"+branch)
+	var errorResponse *github.ErrorResponse
+	if errors.As(err, &errorResponse) && errorResponse.Response.StatusCode == http.StatusNotFound {
+		ref, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+	var parents []*github.Commit
+	baseTree := ""
+	if ref != nil {
+		parent, _, err := client.Git.GetCommit(ctx, owner, repo, ref.GetObject().GetSHA())
+		if err != nil {
+			return err
+		}
+		parents = append(parents, parent)
+		baseTree = parent.GetTree().GetSHA()
+	}
+	blob, _, err := client.Git.CreateBlob(ctx, owner, repo, &github.Blob{
+		Content:  github.String(string(content)),
+		Encoding: github.String("utf-8"),
+	})
+	if err != nil {
+		return err
+	}
+	tree, _, err := client.Git.CreateTree(ctx, owner, repo, baseTree, []*github.TreeEntry{{
+		Path: github.String(filename),
+		Mode: github.String("100644"),
+		Type: github.String("blob"),
+		SHA:  blob.SHA,
+	}})
+	if err != nil {
+		return err
+	}
+	commit, _, err := client.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+		Message: github.String(message),
+		Tree:    tree,
+		Parents: parents,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	newRef := &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: commit.SHA},
+	}
+	if ref == nil {
+		_, _, err = client.Git.CreateRef(ctx, owner, repo, newRef)
+	} else {
+		_, _, err = client.Git.UpdateRef(ctx, owner, repo, newRef, false)
+	}
+	return err
+}
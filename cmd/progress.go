@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// progressWidth is the number of characters of the progress bar.
+const progressWidth = 30
+
+// progress prints a progress bar on stderr during long fetches, so that users know the
+// tool isn't hung. It prints nothing unless stderr is a terminal, so that redirected logs
+// stay clean.
+type progress struct {
+	label   string
+	total   int
+	enabled bool
+	mux     sync.Mutex
+	done    int
+}
+
+func newProgress(label string, total int) *progress {
+	p := &progress{label: label, total: total, enabled: isTerminal(os.Stderr) && total > 0}
+	p.print()
+	return p
+}
+
+// isTerminal returns true if the file is a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// increment marks one more item as done.
+func (p *progress) increment() {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.done++
+	p.print()
+}
+
+func (p *progress) print() {
+	if !p.enabled {
+		return
+	}
+	filled := progressWidth * p.done / p.total
+	fmt.Fprintf(os.Stderr, "\r%s [%s%s] %d/%d", p.label, strings.Repeat("=", filled), strings.Repeat(" ", progressWidth-filled), p.done, p.total)
+}
+
+// finish clears the progress bar.
+func (p *progress) finish() {
+	if p.enabled {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+}
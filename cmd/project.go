@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var projectCmd = &cobra.Command{
+	Use:   "project owner repo",
+	Short: "Add failing workflows and tests to a GitHub Projects board",
+	Long: `Add failing workflows, and failed tests if --workflow is set, to a GitHub Projects (v2)
+board as draft issues. Items whose titles already exist on the board are skipped.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		workflowFlag, err := cmd.Flags().GetString("workflow")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		top, err := cmd.Flags().GetInt("top")
+		if err != nil {
+			return err
+		}
+		failUnder, err := cmd.Flags().GetFloat32("fail-under")
+		if err != nil {
+			return err
+		}
+		projectOwner, err := cmd.Flags().GetString("project-owner")
+		if err != nil {
+			return err
+		}
+		projectNumber, err := cmd.Flags().GetInt("project-number")
+		if err != nil {
+			return err
+		}
+		if projectOwner == "" {
+			projectOwner = owner
+		}
+		if projectNumber == 0 {
+			return fmt.Errorf("--project-number is required")
+		}
+		var workflows []string
+		if workflowFlag != "" {
+			workflows = append(workflows, workflowFlag)
+		} else {
+			workflows, err = dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+			if err != nil {
+				return err
+			}
+		}
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+		type item struct {
+			title string
+			body  string
+		}
+		var items []item
+		for _, workflow := range sortWorkflowsBySuccessRate(result) {
+			runs := result[workflow]
+			if len(runs) == 0 {
+				continue
+			}
+			if len(items) >= top || dashboard.SuccessRate(runs) >= failUnder {
+				break
+			}
+			items = append(items, item{
+				title: fmt.Sprintf("Failing workflow in %s/%s: %s", owner, repo, workflow),
+				body: fmt.Sprintf("Success rate: %0.f%% over %d runs\n\nhttps://github.com/%s/%s/actions/workflows/%s?query=branch%%3A%s+event%%3A%s++",
+					dashboard.SuccessRate(runs), len(runs), owner, repo, workflow, branch, event),
+			})
+		}
+		if workflowFlag != "" {
+			analysis := analyzeLogs(ctx, getFailureDetails(ctx, client, owner, repo, result[workflowFlag]).jobLogs, 0)
+			for i, count := range dashboard.SortByCount(analysis.failedTestCount) {
+				if i >= top {
+					break
+				}
+				items = append(items, item{
+					title: fmt.Sprintf("Failing test in %s/%s %s: %s", owner, repo, workflowFlag, count.Name),
+					body:  fmt.Sprintf("Failed %d times in the last %d runs.\n\n```\n%s\n```", count.Count, len(result[workflowFlag]), analysis.excerpts[count.Name]),
+				})
+			}
+		}
+		projectID, err := getProjectID(ctx, projectOwner, projectNumber)
+		if err != nil {
+			return err
+		}
+		existing, err := getProjectItemTitles(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		for _, i := range items {
+			if _, ok := existing[i.title]; ok {
+				slog.Debug("Item already exists", slog.String("title", i.title))
+				continue
+			}
+			if err := addProjectDraftIssue(ctx, projectID, i.title, i.body); err != nil {
+				return err
+			}
+			slog.Info("Added item", slog.String("title", i.title))
+		}
+		return nil
+	},
+}
+
+// getProjectID returns the node ID of the project owned by the organization or user.
+func getProjectID(ctx context.Context, owner string, number int) (string, error) {
+	for _, ownerType := range []string{"organization", "user"} {
+		var data map[string]*struct {
+			ProjectV2 *struct {
+				ID string `json:"id"`
+			} `json:"projectV2"`
+		}
+		query := fmt.Sprintf(`query($owner: String!, $number: Int!) {
+  %s(login: $owner) { projectV2(number: $number) { id } }
+}`, ownerType)
+		err := graphQL(ctx, query, map[string]any{"owner": owner, "number": number}, &data)
+		if err == nil && data[ownerType] != nil && data[ownerType].ProjectV2 != nil {
+			return data[ownerType].ProjectV2.ID, nil
+		}
+	}
+	return "", fmt.Errorf("project %s/%d not found", owner, number)
+}
+
+// getProjectItemTitles returns the titles of the items in the project.
+func getProjectItemTitles(ctx context.Context, projectID string) (map[string]struct{}, error) {
+	const query = `query($id: ID!, $after: String) {
+  node(id: $id) {
+    ... on ProjectV2 {
+      items(first: 100, after: $after) {
+        nodes {
+          content {
+            ... on DraftIssue { title }
+            ... on Issue { title }
+            ... on PullRequest { title }
+          }
+        }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+	titles := map[string]struct{}{}
+	var after *string
+	for {
+		var data struct {
+			Node struct {
+				Items struct {
+					Nodes []struct {
+						Content struct {
+							Title string `json:"title"`
+						} `json:"content"`
+					} `json:"nodes"`
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+				} `json:"items"`
+			} `json:"node"`
+		}
+		if err := graphQL(ctx, query, map[string]any{"id": projectID, "after": after}, &data); err != nil {
+			return nil, err
+		}
+		for _, node := range data.Node.Items.Nodes {
+			titles[node.Content.Title] = struct{}{}
+		}
+		if !data.Node.Items.PageInfo.HasNextPage {
+			return titles, nil
+		}
+		after = &data.Node.Items.PageInfo.EndCursor
+	}
+}
+
+func addProjectDraftIssue(ctx context.Context, projectID, title, body string) error {
+	const mutation = `mutation($project: ID!, $title: String!, $body: String!) {
+  addProjectV2DraftIssue(input: {projectId: $project, title: $title, body: $body}) { projectItem { id } }
+}`
+	var data any
+	return graphQL(ctx, mutation, map[string]any{"project": projectID, "title": title, "body": body}, &data)
+}
+
+func init() {
+	rootCmd.AddCommand(projectCmd)
+
+	projectCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	projectCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	projectCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	projectCmd.Flags().StringP("workflow", "w", "", "Workflow name (e.g. aks-byocni.yaml)")
+	projectCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	projectCmd.Flags().IntP("top", "t", 10, "Add top n failing workflows and tests")
+	projectCmd.Flags().Float32("fail-under", 80, "Add workflows whose success rate is below this percentage")
+	projectCmd.Flags().String("project-owner", "", "Organization or user that owns the project (default owner)")
+	projectCmd.Flags().Int("project-number", 0, "Project number")
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// provider is a source of workflow runs. Runs of other CI systems are converted to the
+// GitHub types so that the stats work the same for all of them.
+type provider interface {
+	// getWorkflows returns the names of the workflows.
+	getWorkflows(ctx context.Context, owner, repo string) ([]string, error)
+	// getWorkflowRuns returns up to count runs, newest first.
+	getWorkflowRuns(ctx context.Context, owner, repo, branch, workflow, event string, count int, created string) ([]*github.WorkflowRun, error)
+	// workflowURL returns the web page of the runs of the workflow.
+	workflowURL(owner, repo, branch, workflow, event string) string
+}
+
+// githubProvider is the provider for GitHub Actions.
+type githubProvider struct {
+	client *github.Client
+}
+
+func (p githubProvider) getWorkflows(ctx context.Context, owner, repo string) ([]string, error) {
+	return dashboard.GetWorkflows(ctx, p.client.Actions, owner, repo)
+}
+
+func (p githubProvider) getWorkflowRuns(ctx context.Context, owner, repo, branch, workflow, event string, count int, created string) ([]*github.WorkflowRun, error) {
+	return getWorkflowRuns(ctx, p.client, owner, repo, branch, workflow, event, count, created)
+}
+
+func (p githubProvider) workflowURL(owner, repo, branch, workflow, event string) string {
+	return fmt.Sprintf("https://github.com/%s/%s/actions/workflows/%s?query=branch%%3A%s+event%%3A%s++",
+		owner, repo, workflow, branch, event)
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var prsCmd = &cobra.Command{
+	Use:   "prs owner repo",
+	Short: "Show which pull requests consumed the most CI time or had the most failed attempts",
+	Long: `Group pull_request or merge_group runs by pull request number, and show which pull
+requests consumed the most CI time or had the most failed attempts.
+
+Runs of pull requests from forks are grouped by head repository and branch, since GitHub
+does not link them to pull requests.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		base, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		workflowFlag, err := cmd.Flags().GetString("workflow")
+		if err != nil {
+			return err
+		}
+		event, err := cmd.Flags().GetString("event")
+		if err != nil {
+			return err
+		}
+		if event != "pull_request" && event != "merge_group" {
+			return fmt.Errorf("unsupported event %q, expected pull_request or merge_group", event)
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		top, err := cmd.Flags().GetInt("top")
+		if err != nil {
+			return err
+		}
+		var workflows []string
+		if workflowFlag != "" {
+			workflows = append(workflows, workflowFlag)
+		} else {
+			workflows, err = dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+			if err != nil {
+				return err
+			}
+		}
+		// The branch filter of the API matches the head branch, which is the pull request
+		// branch, so runs are filtered by base branch here instead.
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, "", workflows, event, numRuns, daysToTimeRange(days))
+		printPullRequestStats(owner, repo, getPullRequestStats(result, base), top)
+		return nil
+	},
+}
+
+// pullRequestKey returns the pull request number of a pull_request or merge_group run as
+// a string, or the fork and head branch if the run is not linked to a pull request. It
+// returns false if the run is not for a pull request to the base branch.
+func pullRequestKey(run *github.WorkflowRun, base string) (string, bool) {
+	if match := dashboard.MergeQueueBranchRegexp.FindStringSubmatch(run.GetHeadBranch()); match != nil {
+		return match[2], match[1] == base
+	}
+	for _, pr := range run.PullRequests {
+		if pr.GetBase().GetRef() == base {
+			return strconv.Itoa(pr.GetNumber()), true
+		}
+	}
+	if len(run.PullRequests) > 0 || run.GetHeadRepository().GetFullName() == run.GetRepository().GetFullName() {
+		return "", false
+	}
+	return fmt.Sprintf("%s:%s", run.GetHeadRepository().GetOwner().GetLogin(), run.GetHeadBranch()), true
+}
+
+type pullRequestStats struct {
+	key  string
+	runs int
+	// failed is the number of failed runs, and retries the number of re-run attempts.
+	failed  int
+	retries int
+	ciTime  time.Duration
+}
+
+func getPullRequestStats(result map[string][]*github.WorkflowRun, base string) []*pullRequestStats {
+	statsMap := map[string]*pullRequestStats{}
+	for _, runs := range result {
+		for _, run := range runs {
+			key, ok := pullRequestKey(run, base)
+			if !ok {
+				continue
+			}
+			stats, ok := statsMap[key]
+			if !ok {
+				stats = &pullRequestStats{key: key}
+				statsMap[key] = stats
+			}
+			stats.runs++
+			if run.GetConclusion() != "success" {
+				stats.failed++
+			}
+			stats.retries += max(run.GetRunAttempt()-1, 0)
+			stats.ciTime += runDurations.Of(run)
+		}
+	}
+	var statsList []*pullRequestStats
+	for _, stats := range statsMap {
+		statsList = append(statsList, stats)
+	}
+	return statsList
+}
+
+func printPullRequestStats(owner, repo string, statsList []*pullRequestStats, top int) {
+	link := color.New(color.FgCyan).SprintFunc()
+	prLink := func(key string) string {
+		if strings.Contains(key, ":") {
+			return key
+		}
+		return link(getLink(fmt.Sprintf("https://github.com/%s/%s/pull/%s", owner, repo, key), "#"+key))
+	}
+	printTable := func(title string, compare func(a, b *pullRequestStats) int) {
+		slices.SortFunc(statsList, func(a, b *pullRequestStats) int {
+			return cmp.Or(compare(a, b), cmp.Compare(a.key, b.key))
+		})
+		color.New(color.Bold).Printf("\n%s\n", title)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintln(w, "pull request\tCI time\truns\tfailed\tretries")
+		for i, stats := range statsList {
+			if i >= top {
+				break
+			}
+			fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%d\t%d\t%d", prLink(stats.key), stats.ciTime.Round(time.Minute), stats.runs, stats.failed, stats.retries))
+		}
+		w.Flush()
+	}
+	printTable("pull requests by CI time", func(a, b *pullRequestStats) int {
+		return cmp.Compare(b.ciTime, a.ciTime)
+	})
+	printTable("pull requests by failed attempts", func(a, b *pullRequestStats) int {
+		return cmp.Compare(b.failed+b.retries, a.failed+a.retries)
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(prsCmd)
+
+	prsCmd.Flags().StringP("branch", "b", "main", "Base branch of the pull requests")
+	prsCmd.Flags().StringP("event", "e", "pull_request", "Event type that triggered the workflows (pull_request, merge_group)")
+	prsCmd.Flags().IntP("number", "n", 500, "The number of workflow runs to process per workflow")
+	prsCmd.Flags().StringP("workflow", "w", "", "Workflow name (e.g. aks-byocni.yaml)")
+	prsCmd.Flags().Int("days", 7, "Limit workflow runs by the number of days")
+	prsCmd.Flags().IntP("top", "t", 10, "Print top n pull requests")
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish owner repo",
+	Short: "Publish the CI health summary as a commit status, a check run, a discussion, or a page",
+	Long: `Publish the CI health summary as a commit status or a check run on the latest commit
+of the branch. Creating check runs requires a GitHub App installation token.
+
+With --as discussion, the summary is posted as a new discussion in the given category, so
+that the history of reports (e.g. weekly with --days 7) is browsable next to the code.
+
+With --as pages, the HTML report is committed to the GitHub Pages branch, creating the
+branch if it does not exist.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		as, err := cmd.Flags().GetString("as")
+		if err != nil {
+			return err
+		}
+		name, err := cmd.Flags().GetString("name")
+		if err != nil {
+			return err
+		}
+		failUnder, err := cmd.Flags().GetFloat32("fail-under")
+		if err != nil {
+			return err
+		}
+		discussionRepo, err := cmd.Flags().GetString("discussion-repo")
+		if err != nil {
+			return err
+		}
+		category, err := cmd.Flags().GetString("discussion-category")
+		if err != nil {
+			return err
+		}
+		pagesBranch, err := cmd.Flags().GetString("pages-branch")
+		if err != nil {
+			return err
+		}
+		pagesPath, err := cmd.Flags().GetString("pages-path")
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		workflows, err := dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+		if err != nil {
+			return err
+		}
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+		b, _, err := client.Repositories.GetBranch(ctx, owner, repo, branch, 1)
+		if err != nil {
+			return err
+		}
+		sha := b.GetCommit().GetSHA()
+		rate, success, count := overallSuccessRate(result)
+		state := "success"
+		if rate < failUnder {
+			state = "failure"
+		}
+		description := fmt.Sprintf("CI health %s: %0.f%% %d/%d runs succeeded", healthGrade(rate), rate, success, count)
+		targetURL := fmt.Sprintf("https://github.com/%s/%s/actions?query=branch%%3A%s+event%%3A%s", owner, repo, branch, event)
+		switch as {
+		case "status":
+			_, _, err = client.Repositories.CreateStatus(ctx, owner, repo, sha, &github.RepoStatus{
+				State:       &state,
+				Description: &description,
+				Context:     &name,
+				TargetURL:   &targetURL,
+			})
+		case "check":
+			summary := markdownSummary(cfg, owner, repo, branch, event, result)
+			_, _, err = client.Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+				Name:       name,
+				HeadSHA:    sha,
+				DetailsURL: &targetURL,
+				Status:     github.String("completed"),
+				Conclusion: &state,
+				Output: &github.CheckRunOutput{
+					Title:   &description,
+					Summary: &summary,
+				},
+			})
+		case "discussion":
+			discussionOwner, discussionName := owner, repo
+			if discussionRepo != "" {
+				var ok bool
+				discussionOwner, discussionName, ok = strings.Cut(discussionRepo, "/")
+				if !ok {
+					return fmt.Errorf("invalid --discussion-repo %q, expected owner/repo", discussionRepo)
+				}
+			}
+			title := fmt.Sprintf("CI health report for %s/%s: %s", owner, repo, time.Now().UTC().Format(time.DateOnly))
+			body := description + "\n\n" + markdownSummary(cfg, owner, repo, branch, event, result)
+			var url string
+			url, err = createDiscussion(ctx, discussionOwner, discussionName, category, title, body)
+			if err == nil {
+				fmt.Println(url)
+			}
+		case "pages":
+			r := newReport(owner, repo, branch, event, result)
+			var allRuns []*github.WorkflowRun
+			for _, runs := range result {
+				allRuns = append(allRuns, runs...)
+			}
+			r.addJobs(dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, allRuns, numWorkers))
+			var buf bytes.Buffer
+			if err := writeHTMLBundle(&buf, r, cfg); err != nil {
+				return err
+			}
+			err = publishToBranch(ctx, client, owner, repo, pagesBranch, pagesPath, buf.Bytes(), description)
+		default:
+			return fmt.Errorf("unknown publish target %q", as)
+		}
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(publishCmd)
+
+	publishCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	publishCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	publishCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	publishCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	publishCmd.Flags().String("as", "status", "Publish as a commit status, a check run, a discussion, or a GitHub Pages report (status, check, discussion, pages)")
+	publishCmd.Flags().String("name", "ci-dashboard", "Context of the commit status or name of the check run")
+	publishCmd.Flags().String("discussion-repo", "", "Repository to post the discussion in as owner/repo. Defaults to the dashboard repository")
+	publishCmd.Flags().String("discussion-category", "General", "Discussion category to post the discussion in")
+	publishCmd.Flags().String("pages-branch", "gh-pages", "Branch to commit the HTML report to")
+	publishCmd.Flags().String("pages-path", "index.html", "Path of the HTML report in the pages branch")
+	publishCmd.Flags().Float32("fail-under", 80, "Mark the status as failed if the overall success rate is below this percentage")
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// A test is suggested for quarantine if it failed more than quarantineMinFailures
+	// times across more than quarantineMinDays distinct days, and at least one of the
+	// failures was followed by a successful run on the same commit.
+	quarantineMinFailures = 5
+	quarantineMinDays     = 3
+)
+
+// quarantineSuggestion is an entry of the quarantine file, which test harnesses can read
+// to skip or retry flaky tests.
+type quarantineSuggestion struct {
+	Test     string `json:"test" yaml:"test"`
+	Failures int    `json:"failures" yaml:"failures"`
+	// FailureRate is the percentage of the analyzed runs the test failed in.
+	FailureRate float32  `json:"failureRate" yaml:"failureRate"`
+	Days        int      `json:"days" yaml:"days"`
+	FlakySHAs   []string `json:"flakySHAs" yaml:"flakySHAs"`
+}
+
+func getQuarantineSuggestions(failedTestRuns map[string][]*github.WorkflowRun, runs []*github.WorkflowRun) []quarantineSuggestion {
+	var suggestions []quarantineSuggestion
+	for test, failedRuns := range failedTestRuns {
+		if len(failedRuns) <= quarantineMinFailures {
+			continue
+		}
+		days := map[string]struct{}{}
+		var flakySHAs []string
+		for _, failedRun := range failedRuns {
+			days[failedRun.GetRunStartedAt().Format(time.DateOnly)] = struct{}{}
+			if passedLater(failedRun, runs) && !slices.Contains(flakySHAs, failedRun.GetHeadSHA()) {
+				flakySHAs = append(flakySHAs, failedRun.GetHeadSHA())
+			}
+		}
+		if len(days) <= quarantineMinDays || len(flakySHAs) == 0 {
+			continue
+		}
+		slices.Sort(flakySHAs)
+		suggestions = append(suggestions, quarantineSuggestion{
+			Test:        test,
+			Failures:    len(failedRuns),
+			FailureRate: 100 * float32(len(failedRuns)) / float32(len(runs)),
+			Days:        len(days),
+			FlakySHAs:   flakySHAs,
+		})
+	}
+	slices.SortFunc(suggestions, func(a, b quarantineSuggestion) int {
+		return b.Failures - a.Failures
+	})
+	return suggestions
+}
+
+// passedLater returns true if there is a successful run on the same commit that
+// started after the failed run.
+func passedLater(failedRun *github.WorkflowRun, runs []*github.WorkflowRun) bool {
+	for _, run := range runs {
+		if run.GetHeadSHA() == failedRun.GetHeadSHA() &&
+			run.GetConclusion() == "success" &&
+			run.GetRunStartedAt().After(failedRun.GetRunStartedAt().Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneQuarantine merges the suggestions into the entries of an existing quarantine file.
+// Entries of tests that did not fail in the analyzed runs are removed as recovered, and
+// the other entries are kept with updated failure counts even if they are no longer
+// suggested. If the analysis is incomplete, a missing failure does not mean that the
+// test recovered, so such entries are kept unchanged instead.
+func pruneQuarantine(existing, suggestions []quarantineSuggestion, failedTestRuns map[string][]*github.WorkflowRun, runs []*github.WorkflowRun, incomplete bool) []quarantineSuggestion {
+	result := slices.Clone(suggestions)
+	for _, entry := range existing {
+		if slices.ContainsFunc(result, func(s quarantineSuggestion) bool { return s.Test == entry.Test }) {
+			continue
+		}
+		failedRuns, ok := failedTestRuns[entry.Test]
+		if !ok && incomplete {
+			slog.Warn("Keeping test in quarantine because some job logs were not analyzed", slog.String("test", entry.Test))
+			result = append(result, entry)
+			continue
+		}
+		if !ok {
+			slog.Info("Removing recovered test from quarantine", slog.String("test", entry.Test))
+			continue
+		}
+		days := map[string]struct{}{}
+		for _, failedRun := range failedRuns {
+			days[failedRun.GetRunStartedAt().Format(time.DateOnly)] = struct{}{}
+		}
+		entry.Failures = len(failedRuns)
+		entry.FailureRate = 100 * float32(len(failedRuns)) / float32(len(runs))
+		entry.Days = len(days)
+		result = append(result, entry)
+	}
+	slices.SortFunc(result, func(a, b quarantineSuggestion) int {
+		return b.Failures - a.Failures
+	})
+	return result
+}
+
+// readQuarantineFile reads a quarantine file written by writeQuarantineSuggestions. It
+// returns no entries if the file does not exist.
+func readQuarantineFile(filename string) ([]quarantineSuggestion, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []quarantineSuggestion
+	if isYAMLFile(filename) {
+		err = yaml.Unmarshal(data, &entries)
+	} else {
+		err = json.Unmarshal(data, &entries)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	return entries, nil
+}
+
+// writeQuarantineSuggestions writes the quarantine suggestions to a YAML file if the file
+// name ends with .yaml or .yml, or to a JSON file otherwise. With prune, the existing
+// entries of the file are merged as described in pruneQuarantine, and incomplete tells
+// whether some of the job logs were not analyzed.
+func writeQuarantineSuggestions(filename string, failedTestRuns map[string][]*github.WorkflowRun, runs []*github.WorkflowRun, prune, incomplete bool) error {
+	suggestions := getQuarantineSuggestions(failedTestRuns, runs)
+	if prune {
+		existing, err := readQuarantineFile(filename)
+		if err != nil {
+			return err
+		}
+		suggestions = pruneQuarantine(existing, suggestions, failedTestRuns, runs, incomplete)
+	}
+	if suggestions == nil {
+		suggestions = []quarantineSuggestion{}
+	}
+	var data []byte
+	var err error
+	if isYAMLFile(filename) {
+		data, err = yaml.Marshal(suggestions)
+	} else {
+		data, err = json.MarshalIndent(suggestions, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+func isYAMLFile(filename string) bool {
+	ext := filepath.Ext(filename)
+	return ext == ".yaml" || ext == ".yml"
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v59/github"
+)
+
+func TestPruneQuarantine(t *testing.T) {
+	existing := []quarantineSuggestion{{Test: "recovered", Failures: 6}, {Test: "flaky", Failures: 8}}
+	run := &github.WorkflowRun{ID: github.Int64(1)}
+	failedTestRuns := map[string][]*github.WorkflowRun{"flaky": {run}}
+	runs := []*github.WorkflowRun{run, {ID: github.Int64(2)}}
+	for _, tt := range []struct {
+		name       string
+		incomplete bool
+		want       []string
+	}{
+		{"complete", false, []string{"flaky"}},
+		{"incomplete", true, []string{"recovered", "flaky"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pruneQuarantine(existing, nil, failedTestRuns, runs, tt.incomplete)
+			var got []string
+			for _, entry := range result {
+				got = append(got, entry.Test)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("pruneQuarantine() kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+type queueStats struct {
+	name    string
+	count   int
+	average time.Duration
+	p90     time.Duration
+	max     time.Duration
+}
+
+func getQueueStats(name string, durations []time.Duration) queueStats {
+	return queueStats{
+		name:    name,
+		count:   len(durations),
+		average: dashboard.Average(durations).Round(time.Second),
+		p90:     dashboard.Percentile(durations, 90).Round(time.Second),
+		max:     dashboard.Percentile(durations, 100).Round(time.Second),
+	}
+}
+
+// printQueueTime prints how long runs and jobs waited before they started, per workflow
+// and per runner label.
+func printQueueTime(ctx context.Context, client *github.Client, owner, repo string, result map[string][]*github.WorkflowRun) {
+	var workflowStats []queueStats
+	labelQueueTimes := map[string][]time.Duration{}
+	for workflow, runs := range result {
+		var queueTimes []time.Duration
+		for _, run := range runs {
+			// run_started_at is reset on re-runs, so only the first attempt reflects queueing.
+			if run.GetRunAttempt() > 1 {
+				continue
+			}
+			queueTimes = append(queueTimes, run.GetRunStartedAt().Sub(run.GetCreatedAt().Time))
+		}
+		if len(queueTimes) > 0 {
+			workflowStats = append(workflowStats, getQueueStats(workflow, queueTimes))
+		}
+		for _, jobs := range dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, runs, numWorkers) {
+			for _, job := range jobs {
+				if job.StartedAt == nil || job.CreatedAt == nil {
+					continue
+				}
+				label := strings.Join(job.Labels, ",")
+				labelQueueTimes[label] = append(labelQueueTimes[label], job.GetStartedAt().Sub(job.GetCreatedAt().Time))
+			}
+		}
+	}
+	var labelStats []queueStats
+	for label, queueTimes := range labelQueueTimes {
+		labelStats = append(labelStats, getQueueStats(label, queueTimes))
+	}
+	sortByAverage := func(a, b queueStats) int {
+		return int(b.average - a.average)
+	}
+	slices.SortFunc(workflowStats, sortByAverage)
+	slices.SortFunc(labelStats, sortByAverage)
+	bold := color.New(color.Bold)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	bold.Println("\nrun queue time")
+	fmt.Fprintln(w, "average\tp90\tmax\truns\tworkflow")
+	for _, stats := range workflowStats {
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%d\t%s", stats.average, stats.p90, stats.max, stats.count, stats.name))
+	}
+	w.Flush()
+	bold.Println("\njob queue time")
+	fmt.Fprintln(w, "average\tp90\tmax\tjobs\trunner label")
+	for _, stats := range labelStats {
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%d\t%s", stats.average, stats.p90, stats.max, stats.count, stats.name))
+	}
+	w.Flush()
+}
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// quotaPollInterval is how often a process retries to acquire a lock in the quota
+	// directory.
+	quotaPollInterval = 100 * time.Millisecond
+	// quotaStaleLock is the age after which a lock file is considered left behind by a
+	// process that crashed, and is removed.
+	quotaStaleLock = 5 * time.Minute
+	// quotaWindow is the period of the request budget.
+	quotaWindow = time.Hour
+	// quotaDirMode and quotaFileMode let the other users in the group of the quota
+	// directory create, replace, and remove its files.
+	quotaDirMode  = 0775
+	quotaFileMode = 0664
+)
+
+// quotaTransport limits the combined concurrency and hourly request budget of all the
+// processes that share the same quota directory, so that teammates sharing a service
+// token do not starve each other. Locks are files created exclusively in the directory.
+// The directory must be writable by all the users that share it, for example owned by a
+// shared group with mode 2775, and must not have the sticky bit set.
+type quotaTransport struct {
+	dir string
+	// concurrency is the maximum number of requests in flight across all processes.
+	concurrency int
+	// budget is the maximum number of requests per hour across all processes. 0 means
+	// no limit.
+	budget int
+	base   http.RoundTripper
+}
+
+// quotaUsage is the content of the budget file in the quota directory.
+type quotaUsage struct {
+	WindowStart time.Time `json:"windowStart"`
+	Count       int       `json:"count"`
+}
+
+func newQuotaTransport(dir string, concurrency, budget int) (*quotaTransport, error) {
+	if concurrency < 1 {
+		return nil, fmt.Errorf("--quota-concurrency must be at least 1")
+	}
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(dir, quotaDirMode); err != nil {
+			return nil, err
+		}
+		// MkdirAll applies the umask, which usually removes the group write permission.
+		if err := os.Chmod(dir, quotaDirMode); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return &quotaTransport{dir: dir, concurrency: concurrency, budget: budget, base: http.DefaultTransport}, nil
+}
+
+func (t *quotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if err := t.spend(ctx); err != nil {
+		return nil, err
+	}
+	slot, err := t.acquireSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		os.Remove(slot)
+		return nil, err
+	}
+	// Keep the slot until the body is read, so that large downloads such as logs count
+	// against the concurrency limit.
+	resp.Body = &slotBody{ReadCloser: resp.Body, slot: slot}
+	return resp, nil
+}
+
+// slotBody removes the lock file of the concurrency slot when the response body is
+// closed.
+type slotBody struct {
+	io.ReadCloser
+	slot string
+	once sync.Once
+}
+
+func (b *slotBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		os.Remove(b.slot)
+	})
+	return err
+}
+
+// acquireSlot waits until one of the concurrency slots is free, and returns the path of
+// the lock file to remove once the request is done.
+func (t *quotaTransport) acquireSlot(ctx context.Context) (string, error) {
+	for {
+		for i := 0; i < t.concurrency; i++ {
+			name := filepath.Join(t.dir, fmt.Sprintf("slot-%d.lock", i))
+			ok, err := tryLock(name)
+			if err != nil {
+				return "", err
+			}
+			if ok {
+				return name, nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(quotaPollInterval):
+		}
+	}
+}
+
+// spend counts the request against the shared budget, waiting for the next window if
+// the budget is exhausted.
+func (t *quotaTransport) spend(ctx context.Context) error {
+	if t.budget == 0 {
+		return nil
+	}
+	for {
+		wait, err := t.updateUsage(ctx)
+		if err != nil {
+			return err
+		}
+		if wait == 0 {
+			return nil
+		}
+		slog.Info("Shared API budget exhausted, waiting", slog.Duration("wait", wait))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// updateUsage increments the request count in the budget file under the budget lock. It
+// returns how long to wait if the budget of the current window is exhausted.
+func (t *quotaTransport) updateUsage(ctx context.Context) (time.Duration, error) {
+	lock := filepath.Join(t.dir, "budget.lock")
+	for {
+		ok, err := tryLock(lock)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(quotaPollInterval):
+		}
+	}
+	defer os.Remove(lock)
+	filename := filepath.Join(t.dir, "budget.json")
+	var usage quotaUsage
+	data, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &usage); err != nil {
+			slog.Warn("Resetting corrupted budget file", slog.String("file", filename), slog.Any("error", err))
+			usage = quotaUsage{}
+		}
+	}
+	now := time.Now()
+	if now.Sub(usage.WindowStart) >= quotaWindow {
+		usage = quotaUsage{WindowStart: now}
+	}
+	if usage.Count >= t.budget {
+		return usage.WindowStart.Add(quotaWindow).Sub(now), nil
+	}
+	usage.Count++
+	data, err = json.Marshal(usage)
+	if err != nil {
+		return 0, err
+	}
+	return 0, writeFileAtomic(filename, data)
+}
+
+// writeFileAtomic writes the data to a temporary file in the same directory and renames
+// it into place, which only requires write permission on the directory and not on a file
+// created by another user.
+func writeFileAtomic(filename string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	// CreateTemp creates the file readable only by its owner.
+	if err := f.Chmod(quotaFileMode); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+// tryLock creates the lock file exclusively. It returns false if another process holds
+// the lock. Stale lock files are removed.
+func tryLock(name string) (bool, error) {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, quotaFileMode)
+	if err == nil {
+		fmt.Fprintf(f, "%d\n", os.Getpid())
+		return true, f.Close()
+	}
+	if !errors.Is(err, fs.ErrExist) {
+		return false, err
+	}
+	if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > quotaStaleLock {
+		slog.Warn("Removing stale lock file", slog.String("file", name))
+		os.Remove(name)
+	}
+	return false, nil
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var releaseCheckCmd = &cobra.Command{
+	Use:   "release-check owner repo",
+	Short: "Validate that the required workflows succeeded on the commit of a release tag",
+	Long: `Validate that the required workflows succeeded on the commit of a release tag.
+
+Runs are matched by commit SHA, so runs triggered by the tag and by the release branch at
+the same commit are both taken into account. The latest run of each workflow is used.
+Exits with a non-zero code if the verdict is no-go.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		tag, err := cmd.Flags().GetString("tag")
+		if err != nil {
+			return err
+		}
+		if tag == "" {
+			return fmt.Errorf("--tag is required")
+		}
+		required, err := cmd.Flags().GetStringSlice("require")
+		if err != nil {
+			return err
+		}
+		sha, _, err := client.Repositories.GetCommitSHA1(ctx, owner, repo, tag, "")
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", tag, err)
+		}
+		latest, err := getLatestRunsForSHA(ctx, client, owner, repo, sha)
+		if err != nil {
+			return err
+		}
+		if len(required) == 0 {
+			for workflow := range latest {
+				required = append(required, workflow)
+			}
+		}
+		fmt.Printf("%s %s\n", tag, sha)
+		if !printReleaseCheck(required, latest) {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+// getLatestRunsForSHA returns the latest run of each workflow on the commit, keyed by
+// workflow file name.
+func getLatestRunsForSHA(ctx context.Context, client *github.Client, owner, repo, sha string) (map[string]*github.WorkflowRun, error) {
+	workflows, err := dashboard.ListWorkflows(ctx, client.Actions, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	names := map[int64]string{}
+	for _, workflow := range workflows {
+		names[workflow.GetID()] = path.Base(workflow.GetPath())
+	}
+	listOptions := github.ListWorkflowRunsOptions{HeadSHA: sha}
+	latest := map[string]*github.WorkflowRun{}
+	for {
+		runs, res, err := client.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, &listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range runs.WorkflowRuns {
+			name, ok := names[run.GetWorkflowID()]
+			if !ok {
+				name = run.GetName()
+			}
+			if prev, ok := latest[name]; !ok || run.GetCreatedAt().After(prev.GetCreatedAt().Time) {
+				latest[name] = run
+			}
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		listOptions.Page = res.NextPage
+	}
+	return latest, nil
+}
+
+// printReleaseCheck prints the status of each required workflow and the verdict. It
+// returns true if all the required workflows succeeded.
+func printReleaseCheck(required []string, latest map[string]*github.WorkflowRun) bool {
+	slices.Sort(required)
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	link := color.New(color.FgCyan).SprintFunc()
+	var missing, failed, pending int
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "workflow\tstatus\tevent\tlink")
+	for _, workflow := range required {
+		run, ok := latest[workflow]
+		switch {
+		case !ok:
+			missing++
+			fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t\t", workflow, red("missing")))
+		case run.GetStatus() != "completed":
+			pending++
+			fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%s", workflow, yellow(run.GetStatus()), run.GetEvent(), link(run.GetHTMLURL())))
+		case run.GetConclusion() != "success":
+			failed++
+			fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%s", workflow, red(run.GetConclusion()), run.GetEvent(), link(run.GetHTMLURL())))
+		default:
+			fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%s", workflow, green(run.GetConclusion()), run.GetEvent(), link(run.GetHTMLURL())))
+		}
+	}
+	w.Flush()
+	if missing+failed+pending == 0 && len(required) > 0 {
+		color.New(color.FgGreen, color.Bold).Println("\nGO")
+		return true
+	}
+	color.New(color.FgRed, color.Bold).Printf("\nNO-GO: %d missing, %d failed, %d pending\n", missing, failed, pending)
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(releaseCheckCmd)
+
+	releaseCheckCmd.Flags().String("tag", "", "Release tag (e.g. v1.16.0)")
+	releaseCheckCmd.Flags().StringSlice("require", nil, "Workflows that must succeed (e.g. conformance-kind.yaml). Defaults to all the workflows that ran on the commit")
+}
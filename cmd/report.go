@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"cmp"
+	_ "embed"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// reportSchemaVersion is the version of the report schema. Fields are only added within a
+// version, so that consumers can rely on them. Renaming or removing a field requires a new
+// version and schema.
+const reportSchemaVersion = 1
+
+// reportSchema is the JSON schema of reports, printed by report --schema.
+//
+//go:embed schemas/report-v1.json
+var reportSchema string
+
+// report is the machine-readable representation of the dashboard.
+type report struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Owner         string           `json:"owner"`
+	Repo          string           `json:"repo"`
+	Branch        string           `json:"branch"`
+	Event         string           `json:"event"`
+	GeneratedAt   time.Time        `json:"generatedAt"`
+	Workflows     []reportWorkflow `json:"workflows"`
+	// ErrorClusters are the number of occurrences of each error message in the logs of
+	// failed jobs. Only populated by snapshot save --errors.
+	ErrorClusters map[string]int `json:"errorClusters,omitempty"`
+	// FailedTests are the number of failures of each test in the logs of failed jobs.
+	// Only populated by snapshot save --errors.
+	FailedTests map[string]int `json:"failedTests,omitempty"`
+}
+
+type reportWorkflow struct {
+	Workflow string  `json:"workflow"`
+	URL      string  `json:"url"`
+	Success  int     `json:"success"`
+	Count    int     `json:"count"`
+	Rate     float32 `json:"successRate"`
+	// AverageDuration is the average duration of successful runs in seconds.
+	AverageDuration float64 `json:"averageDuration"`
+	// AverageFailureDuration is the average duration of unsuccessful runs in seconds.
+	AverageFailureDuration float64 `json:"averageFailureDuration"`
+	// FailureStreak is the number of consecutive failed runs counting from the latest.
+	FailureStreak int `json:"failureStreak"`
+	// LastSuccess is the latest successful run, or nil if none of the runs succeeded.
+	LastSuccess *reportRun  `json:"lastSuccess"`
+	Runs        []reportRun `json:"runs"`
+}
+
+type reportRun struct {
+	ID         int64     `json:"id"`
+	SHA        string    `json:"sha"`
+	Conclusion string    `json:"conclusion"`
+	StartedAt  time.Time `json:"startedAt"`
+	// Duration is the duration of the run in seconds.
+	Duration float64 `json:"duration"`
+	Attempt  int     `json:"attempt"`
+	Actor    string  `json:"actor"`
+	URL      string  `json:"url"`
+	// Jobs are only populated by addJobs.
+	Jobs []reportJob `json:"jobs,omitempty"`
+}
+
+type reportJob struct {
+	Name       string `json:"name"`
+	Conclusion string `json:"conclusion"`
+	URL        string `json:"url"`
+}
+
+func newReport(owner, repo, branch, event string, result map[string][]*github.WorkflowRun) report {
+	r := report{
+		SchemaVersion: reportSchemaVersion,
+		Owner:         owner,
+		Repo:          repo,
+		Branch:        branch,
+		Event:         event,
+		GeneratedAt:   time.Now().UTC(),
+		Workflows:     []reportWorkflow{},
+	}
+	for workflow, runs := range result {
+		rw := reportWorkflow{
+			Workflow: workflow,
+			URL: fmt.Sprintf("https://github.com/%s/%s/actions/workflows/%s?query=branch%%3A%s+event%%3A%s++",
+				owner, repo, workflow, branch, event),
+			Count:                  len(runs),
+			Rate:                   dashboard.SuccessRate(runs),
+			AverageDuration:        dashboard.AverageSuccessDuration(runs, runDurations).Seconds(),
+			AverageFailureDuration: dashboard.AverageFailureDuration(runs, runDurations).Seconds(),
+			FailureStreak:          dashboard.FailureStreak(runs),
+			Runs:                   []reportRun{},
+		}
+		for _, run := range runs {
+			if run.GetConclusion() == "success" {
+				rw.Success++
+			}
+			rw.Runs = append(rw.Runs, reportRun{
+				ID:         run.GetID(),
+				SHA:        run.GetHeadSHA(),
+				Conclusion: run.GetConclusion(),
+				StartedAt:  run.GetRunStartedAt().Time,
+				Duration:   runDurations.Of(run).Seconds(),
+				Attempt:    run.GetRunAttempt(),
+				Actor:      run.GetActor().GetLogin(),
+				URL:        run.GetHTMLURL(),
+			})
+		}
+		if last := dashboard.LastSuccess(runs); last != nil {
+			i := slices.Index(runs, last)
+			lastRun := rw.Runs[i]
+			rw.LastSuccess = &lastRun
+		}
+		r.Workflows = append(r.Workflows, rw)
+	}
+	slices.SortFunc(r.Workflows, func(a, b reportWorkflow) int {
+		return cmp.Compare(a.Workflow, b.Workflow)
+	})
+	return r
+}
+
+// addJobs adds the jobs of each run to the report, keyed by run ID.
+func (r *report) addJobs(jobs map[int64][]*github.WorkflowJob) {
+	for i := range r.Workflows {
+		for j := range r.Workflows[i].Runs {
+			run := &r.Workflows[i].Runs[j]
+			for _, job := range jobs[run.ID] {
+				run.Jobs = append(run.Jobs, reportJob{
+					Name:       job.GetName(),
+					Conclusion: job.GetConclusion(),
+					URL:        job.GetHTMLURL(),
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report owner repo",
+	Short: "Write the dashboard as a standalone report",
+	Long: `Write the dashboard as a standalone report.
+
+The html format is a self-contained file with embedded CSS, JavaScript, and charts,
+suitable for uploading as a CI artifact or publishing to GitHub Pages. The json format is
+the same data as used by snapshots, and follows the versioned JSON schema printed by
+--schema.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := cmd.Flags().GetBool("schema")
+		if err != nil {
+			return err
+		}
+		if schema {
+			fmt.Print(reportSchema)
+			return nil
+		}
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		workflows, err := dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+		if err != nil {
+			return err
+		}
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+		r := newReport(owner, repo, branch, event, result)
+		if format == "html" {
+			var allRuns []*github.WorkflowRun
+			for _, runs := range result {
+				allRuns = append(allRuns, runs...)
+			}
+			r.addJobs(dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, allRuns, numWorkers))
+		}
+		var out io.Writer = os.Stdout
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		return writeReport(out, r, cfg, format)
+	},
+}
+
+func writeReport(out io.Writer, r report, cfg *config, format string) error {
+	switch format {
+	case "html":
+		return writeHTMLBundle(out, r, cfg)
+	case "json":
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(append(data, '\n'))
+		return err
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	reportCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	reportCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	reportCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	reportCmd.Flags().StringP("format", "f", "html", "Report format (html, json)")
+	reportCmd.Flags().StringP("output", "o", "", "Output file (default stdout)")
+	reportCmd.Flags().Bool("schema", false, "Print the JSON schema of the json format and snapshots, and exit")
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// getRequiredChecks returns the names of the status checks required by the branch
+// protection and the rulesets of the branch. Reading the branch protection requires
+// admin access, so its errors are only logged.
+func getRequiredChecks(ctx context.Context, client *github.Client, owner, repo, branch string) ([]string, error) {
+	var checks []string
+	add := func(check string) {
+		if !slices.Contains(checks, check) {
+			checks = append(checks, check)
+		}
+	}
+	protection, _, err := client.Repositories.GetRequiredStatusChecks(ctx, owner, repo, branch)
+	if err != nil {
+		slog.Debug("Failed to get branch protection", slog.String("branch", branch), slog.Any("error", err))
+	} else {
+		for _, check := range protection.Contexts {
+			add(check)
+		}
+		for _, check := range protection.Checks {
+			add(check.Context)
+		}
+	}
+	rules, _, err := client.Repositories.GetRulesForBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return checks, err
+	}
+	for _, rule := range rules {
+		if rule.Type != "required_status_checks" || rule.Parameters == nil {
+			continue
+		}
+		var params github.RequiredStatusChecksRuleParameters
+		if err := json.Unmarshal(*rule.Parameters, &params); err != nil {
+			return checks, err
+		}
+		for _, check := range params.RequiredStatusChecks {
+			add(check.Context)
+		}
+	}
+	slices.Sort(checks)
+	return checks, nil
+}
+
+type requiredCheckStats struct {
+	check     string
+	workflows []string
+	success   int
+	count     int
+	// failing is true if the latest job of the check failed.
+	failing bool
+	// flakySHAs are the commits the check both failed and passed on.
+	flakySHAs int
+}
+
+// getRequiredCheckStats matches the required checks with the jobs of the runs by name.
+func getRequiredCheckStats(checks []string, result map[string][]*github.WorkflowRun, jobs map[int64][]*github.WorkflowJob) []requiredCheckStats {
+	var statsList []requiredCheckStats
+	for _, check := range checks {
+		stats := requiredCheckStats{check: check}
+		var latest *github.WorkflowJob
+		conclusions := map[string][]string{}
+		for workflow, runs := range result {
+			for _, run := range runs {
+				for _, job := range jobs[run.GetID()] {
+					if job.GetName() != check || (job.GetConclusion() != "success" && job.GetConclusion() != "failure") {
+						continue
+					}
+					if !slices.Contains(stats.workflows, workflow) {
+						stats.workflows = append(stats.workflows, workflow)
+					}
+					stats.count++
+					if job.GetConclusion() == "success" {
+						stats.success++
+					}
+					conclusions[job.GetHeadSHA()] = append(conclusions[job.GetHeadSHA()], job.GetConclusion())
+					if latest == nil || job.GetCompletedAt().After(latest.GetCompletedAt().Time) {
+						latest = job
+					}
+				}
+			}
+		}
+		stats.failing = latest != nil && latest.GetConclusion() == "failure"
+		for _, c := range conclusions {
+			if slices.Contains(c, "success") && slices.Contains(c, "failure") {
+				stats.flakySHAs++
+			}
+		}
+		slices.Sort(stats.workflows)
+		statsList = append(statsList, stats)
+	}
+	slices.SortFunc(statsList, func(a, b requiredCheckStats) int {
+		rate := func(s requiredCheckStats) float32 {
+			if s.count == 0 {
+				return 100
+			}
+			return float32(s.success) / float32(s.count)
+		}
+		return cmp.Or(cmp.Compare(rate(a), rate(b)), cmp.Compare(a.check, b.check))
+	})
+	return statsList
+}
+
+// printRequiredChecks prints the health of the checks required to merge into the branch,
+// highlighting failing and flaky ones since they block merges.
+func printRequiredChecks(ctx context.Context, client *github.Client, owner, repo, branch string, result map[string][]*github.WorkflowRun) error {
+	checks, err := getRequiredChecks(ctx, client, owner, repo, branch)
+	if err != nil {
+		return fmt.Errorf("failed to get required checks: %w", err)
+	}
+	color.New(color.Bold).Printf("\nrequired checks for %s\n", branch)
+	if len(checks) == 0 {
+		fmt.Println("no required checks found, reading branch protection requires admin access")
+		return nil
+	}
+	var allRuns []*github.WorkflowRun
+	for _, runs := range result {
+		allRuns = append(allRuns, runs...)
+	}
+	jobs := dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, allRuns, numWorkers)
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "status\tsuccess rate\tflaky commits\tcheck\tworkflows")
+	for _, stats := range getRequiredCheckStats(checks, result, jobs) {
+		status := green("passing")
+		switch {
+		case stats.count == 0:
+			status = "no runs"
+		case stats.failing:
+			status = red("failing")
+		case stats.flakySHAs > 0:
+			status = yellow("flaky")
+		}
+		rate := "N/A"
+		if stats.count > 0 {
+			rate = fmt.Sprintf("%.0f%% %d/%d", 100*float32(stats.success)/float32(stats.count), stats.success, stats.count)
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%d\t%s\t%s", status, rate, stats.flakySHAs, stats.check, strings.Join(stats.workflows, ", ")))
+	}
+	w.Flush()
+	return nil
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var rerunCmd = &cobra.Command{
+	Use:   "rerun owner repo",
+	Short: "Re-run the failed jobs of workflows whose latest run failed",
+	Long: `Re-run the failed jobs of the latest run of each workflow if it failed, or trigger a new
+run with workflow_dispatch with --dispatch. Use --below to only retry workflows whose success
+rate is below a threshold, and --dry-run to list the workflows without retrying them. The
+workflows are only retried after confirmation on a terminal, or with --yes.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		workflowFlag, err := cmd.Flags().GetString("workflow")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		below, err := cmd.Flags().GetFloat32("below")
+		if err != nil {
+			return err
+		}
+		dispatch, err := cmd.Flags().GetBool("dispatch")
+		if err != nil {
+			return err
+		}
+		maxAttempts, err := cmd.Flags().GetInt("max-attempts")
+		if err != nil {
+			return err
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+		yes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			return err
+		}
+		var workflows []string
+		if workflowFlag != "" {
+			workflows = append(workflows, workflowFlag)
+		} else {
+			workflows, err = dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+			if err != nil {
+				return err
+			}
+		}
+		printEventWarning(event)
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+		type candidate struct {
+			workflow string
+			run      *github.WorkflowRun
+			rate     float32
+		}
+		var candidates []candidate
+		retries := 0
+		for _, workflow := range sortWorkflowsBySuccessRate(result) {
+			runs := result[workflow]
+			rate := dashboard.SuccessRate(runs)
+			if len(runs) == 0 || runs[0].GetConclusion() == "success" || (below > 0 && rate >= below) {
+				continue
+			}
+			candidates = append(candidates, candidate{workflow: workflow, run: runs[0], rate: rate})
+			if runs[0].GetRunAttempt() < maxAttempts {
+				retries++
+			}
+		}
+		if !dryRun && !yes && retries > 0 {
+			ok, err := confirm(fmt.Sprintf("%d workflows will be retried", retries), "retry them")
+			if err != nil {
+				return err
+			}
+			if !ok {
+				slog.Info("Skipping retries")
+				return nil
+			}
+		}
+		green := color.New(color.FgGreen).SprintFunc()
+		red := color.New(color.FgRed).SprintFunc()
+		link := color.New(color.FgCyan).SprintFunc()
+		failed := 0
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintln(w, "success rate\tattempt\tresult\tworkflow\tlatest run")
+		for _, c := range candidates {
+			run := c.run
+			var status string
+			switch {
+			case run.GetRunAttempt() >= maxAttempts:
+				status = fmt.Sprintf("skipped after %d attempts", run.GetRunAttempt())
+			case dryRun:
+				status = "dry run"
+			case dispatch:
+				_, err = client.Actions.CreateWorkflowDispatchEventByFileName(ctx, owner, repo, c.workflow, github.CreateWorkflowDispatchEventRequest{Ref: branch})
+				status = green("dispatched")
+			default:
+				_, err = client.Actions.RerunFailedJobsByID(ctx, owner, repo, run.GetID())
+				status = green("re-run failed jobs")
+			}
+			if err != nil {
+				failed++
+				status = red(err.Error())
+				err = nil
+			}
+			fmt.Fprintln(w, fmt.Sprintf("%.0f%%\t%d\t%s\t%s\t%s", c.rate, run.GetRunAttempt(), status, c.workflow, link(run.GetHTMLURL())))
+		}
+		w.Flush()
+		if failed > 0 {
+			return fmt.Errorf("failed to retry %d workflows", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rerunCmd)
+
+	rerunCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	rerunCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	rerunCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to compute success rates from")
+	rerunCmd.Flags().StringP("workflow", "w", "", "Workflow name (e.g. aks-byocni.yaml)")
+	rerunCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	rerunCmd.Flags().Float32("below", 0, "Only retry workflows whose success rate is below this percentage. 0 means all the workflows whose latest run failed")
+	rerunCmd.Flags().Bool("dispatch", false, "Trigger a new run with workflow_dispatch on the branch instead of re-running the failed jobs")
+	rerunCmd.Flags().Int("max-attempts", 3, "Do not re-run runs that already reached this number of attempts")
+	rerunCmd.Flags().Bool("dry-run", false, "List the workflows that would be retried without retrying them")
+	rerunCmd.Flags().BoolP("yes", "y", false, "Retry the workflows without asking for confirmation")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+)
+
+type retryStats struct {
+	workflow string
+	// rerun is the number of runs that were attempted more than once.
+	rerun int
+	// rerunSuccess is the number of re-run runs whose latest attempt succeeded.
+	rerunSuccess    int
+	averageAttempts float32
+	count           int
+}
+
+func getRetryStats(workflow string, runs []*github.WorkflowRun) retryStats {
+	stats := retryStats{workflow: workflow, count: len(runs)}
+	attempts := 0
+	for _, run := range runs {
+		attempts += max(run.GetRunAttempt(), 1)
+		if run.GetRunAttempt() > 1 {
+			stats.rerun++
+			if run.GetConclusion() == "success" {
+				stats.rerunSuccess++
+			}
+		}
+	}
+	if len(runs) > 0 {
+		stats.averageAttempts = float32(attempts) / float32(len(runs))
+	}
+	return stats
+}
+
+func printRetryStats(result map[string][]*github.WorkflowRun) {
+	var statsList []retryStats
+	for workflow, runs := range result {
+		if len(runs) == 0 {
+			continue
+		}
+		statsList = append(statsList, getRetryStats(workflow, runs))
+	}
+	slices.SortFunc(statsList, func(a, b retryStats) int {
+		return b.rerun - a.rerun
+	})
+	color.New(color.FgYellow, color.Bold).Println("\nre-runs")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "re-run\taverage attempts\tsuccess on retry\tworkflow")
+	for _, stats := range statsList {
+		successOnRetry := "N/A"
+		if stats.rerun > 0 {
+			successOnRetry = fmt.Sprintf("%0.f%% %d/%d",
+				100*float32(stats.rerunSuccess)/float32(stats.rerun), stats.rerunSuccess, stats.rerun)
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%d/%d\t%.2f\t%s\t%s",
+			stats.rerun, stats.count, stats.averageAttempts, successOnRetry, stats.workflow))
+	}
+	w.Flush()
+}
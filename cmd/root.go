@@ -1,18 +1,205 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
+// logLevel is the level of the JSON log handler. The text handler uses the level set by
+// slog.SetLogLoggerLevel.
+var logLevel = &slog.LevelVar{}
+
+// numWorkers is the number of concurrent API requests.
+var numWorkers = 30
+
+// plainOutput disables colors, emoji, and hyperlinks, so that output pasted into issues
+// or redirected to files stays clean.
+var plainOutput bool
+
+// asciiOutput replaces emoji and other non-ASCII symbols with ASCII, for terminals and
+// ticketing systems that mangle them.
+var asciiOutput bool
+
+// httpClient is used for all the requests to GitHub and the notifiers, so that the
+// request timeout applies to all of them.
+var httpClient = &http.Client{}
+
 var rootCmd = &cobra.Command{
 	Use:   "ci-dashboard",
 	Short: "Amazing CI dashboard",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		logFormat, err := cmd.Flags().GetString("log-format")
+		if err != nil {
+			return err
+		}
+		switch logFormat {
+		case "text":
+		case "json":
+			slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
+		default:
+			return fmt.Errorf("unknown log format %q", logFormat)
+		}
+		plain, err := cmd.Flags().GetBool("plain")
+		if err != nil {
+			return err
+		}
+		noColor, err := cmd.Flags().GetBool("no-color")
+		if err != nil {
+			return err
+		}
+		if plain || noColor || os.Getenv("NO_COLOR") != "" {
+			plainOutput = true
+			color.NoColor = true
+		}
+		asciiOutput, err = cmd.Flags().GetBool("ascii")
+		if err != nil {
+			return err
+		}
+		timezone, err := cmd.Flags().GetString("timezone")
+		if err != nil {
+			return err
+		}
+		displayLocation, err = time.LoadLocation(timezone)
+		if err != nil {
+			return fmt.Errorf("invalid --timezone: %w", err)
+		}
+		timeFormat, err = cmd.Flags().GetString("time-format")
+		if err != nil {
+			return err
+		}
+		dateFormat, err = cmd.Flags().GetString("date-format")
+		if err != nil {
+			return err
+		}
+		since, err := cmd.Flags().GetString("since")
+		if err != nil {
+			return err
+		}
+		until, err := cmd.Flags().GetString("until")
+		if err != nil {
+			return err
+		}
+		if err := parseTimeRange(since, until); err != nil {
+			return err
+		}
+		numWorkers, err = cmd.Flags().GetInt("workers")
+		if err != nil {
+			return err
+		}
+		if numWorkers < 1 {
+			return fmt.Errorf("--workers must be at least 1")
+		}
+		limitMode, err = cmd.Flags().GetString("limit-mode")
+		if err != nil {
+			return err
+		}
+		if limitMode != "and" && limitMode != "or" {
+			return fmt.Errorf("unknown limit mode %q", limitMode)
+		}
+		successExprFlag, err := cmd.Flags().GetString("success-expr")
+		if err != nil {
+			return err
+		}
+		if successExprFlag != "" {
+			expr, err := parseSuccessExpr(successExprFlag)
+			if err != nil {
+				return fmt.Errorf("invalid --success-expr: %w", err)
+			}
+			SetSuccessCriteria(expr)
+		}
+		httpClient.Timeout, err = cmd.Flags().GetDuration("request-timeout")
+		if err != nil {
+			return err
+		}
+		quotaDir, err := cmd.Flags().GetString("quota-dir")
+		if err != nil {
+			return err
+		}
+		if quotaDir != "" {
+			quotaConcurrency, err := cmd.Flags().GetInt("quota-concurrency")
+			if err != nil {
+				return err
+			}
+			quotaBudget, err := cmd.Flags().GetInt("quota-budget")
+			if err != nil {
+				return err
+			}
+			transport, err := newQuotaTransport(quotaDir, quotaConcurrency, quotaBudget)
+			if err != nil {
+				return err
+			}
+			httpClient.Transport = transport
+		}
+		fromBundle, err := cmd.Flags().GetString("from-bundle")
+		if err != nil {
+			return err
+		}
+		if fromBundle != "" {
+			transport, err := newReplayTransport(fromBundle)
+			if err != nil {
+				return err
+			}
+			httpClient.Transport = transport
+		}
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return err
+		}
+		if timeout > 0 {
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			cmd.SetContext(ctx)
+			cobra.OnFinalize(cancel)
+		}
+		return nil
+	},
+}
+
+// setDebug enables debug logs for both the text and JSON log handlers.
+func setDebug() {
+	slog.SetLogLoggerLevel(slog.LevelDebug)
+	logLevel.Set(slog.LevelDebug)
+}
+
+func init() {
+	rootCmd.PersistentFlags().String("config", "", "Path to the configuration file")
+	rootCmd.PersistentFlags().Bool("plain", false, "Disable colors, emoji, and hyperlinks. Also enabled by the NO_COLOR environment variable")
+	rootCmd.PersistentFlags().Bool("no-color", false, "Same as --plain")
+	rootCmd.PersistentFlags().Bool("ascii", false, "Print textual grades (OK, WARN, FAIL) instead of status emoji, and ASCII sparklines and heatmaps")
+	rootCmd.PersistentFlags().String("timezone", "UTC", "Time zone of the timestamps in the output, such as Local or Europe/Zurich")
+	rootCmd.PersistentFlags().String("time-format", time.DateTime, "Go layout of the timestamps in the output (e.g. 'Jan 2 15:04')")
+	rootCmd.PersistentFlags().String("date-format", time.DateOnly, "Go layout of the dates in the output (e.g. '02.01.2006')")
+	rootCmd.PersistentFlags().String("since", "", "Only include runs created since this date (2024-01-01), timestamp, or age (2w, 3d, 12h). Overrides --days")
+	rootCmd.PersistentFlags().String("until", "", "Only include runs created until this date (inclusive), timestamp, or age (e.g. 1w)")
+	rootCmd.PersistentFlags().String("log-format", "text", "Log format on stderr (text, json)")
+	rootCmd.PersistentFlags().String("limit-mode", "and", "How --number and --days limit the runs: and fetches up to --number runs within --days, or fetches the latest --number runs plus any other runs within --days")
+	rootCmd.PersistentFlags().String("success-expr", "", `Expression for runs that count as a success in all the stats (e.g. 'conclusion == "success" || onlyFailed("flaky")'). Not supported for GitLab`)
+	rootCmd.PersistentFlags().Int("workers", 30, "Number of concurrent API requests")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Timeout for the whole command (e.g. 5m). 0 means no timeout")
+	rootCmd.PersistentFlags().String("quota-dir", "", "Directory shared by the users of the same token to limit their combined API usage (e.g. /var/lib/ci-dashboard)")
+	rootCmd.PersistentFlags().Int("quota-concurrency", 10, "Maximum number of concurrent API requests across all the users of --quota-dir")
+	rootCmd.PersistentFlags().Int("quota-budget", 0, "Maximum number of API requests per hour across all the users of --quota-dir. 0 means no limit")
+	rootCmd.PersistentFlags().String("from-bundle", "", "Read API responses from a bundle written by the fetch command instead of the network")
+	rootCmd.PersistentFlags().Duration("request-timeout", 0, "Timeout for each HTTP request (e.g. 30s). 0 means no timeout")
 }
 
 func Execute() {
-	err := rootCmd.Execute()
+	// Ctrl-C cancels the context so that workers stop and partial results are printed.
+	// A second Ctrl-C terminates the process immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	registerCompletions(rootCmd)
+	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
 		os.Exit(1)
 	}
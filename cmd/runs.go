@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var runsCmd = &cobra.Command{
+	Use:   "runs owner repo workflow",
+	Short: "List workflow runs",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 3 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		workflow := args[2]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		printEventWarning(event)
+		runs, err := getWorkflowRuns(ctx, client, owner, repo, branch, workflow, event, numRuns, daysToTimeRange(days))
+		if err != nil {
+			return err
+		}
+		link := color.New(color.FgCyan, color.Underline).SprintFunc()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintln(w, "id\tstarted\tsha\tconclusion\tduration\tactor\tlink")
+		for _, run := range runs {
+			conclusion := color.New(color.FgGreen).SprintFunc()
+			if run.GetConclusion() != "success" {
+				conclusion = color.New(color.FgRed).SprintFunc()
+			}
+			fmt.Fprintln(w, fmt.Sprintf("%d\t%s\t%s\t%s\t%s\t%s\t%s",
+				run.GetID(),
+				formatTime(run.GetRunStartedAt().Time),
+				run.GetHeadSHA()[:min(len(run.GetHeadSHA()), 7)],
+				conclusion(run.GetConclusion()),
+				runDurations.Of(run),
+				run.GetActor().GetLogin(),
+				link(run.GetHTMLURL()),
+			))
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runsCmd)
+
+	runsCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	runsCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	runsCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to list")
+	runsCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+}
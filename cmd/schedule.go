@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"gopkg.in/yaml.v3"
+)
+
+// scheduleDelayTolerance is how long a scheduled run may be delayed before it is
+// considered missed. GitHub does not guarantee that scheduled runs start on time.
+const scheduleDelayTolerance = time.Hour
+
+// getWorkflowSchedules returns the on.schedule cron expressions of the workflow file on
+// the given branch.
+func getWorkflowSchedules(ctx context.Context, client *github.Client, owner, repo, branch, workflow string) ([]string, error) {
+	content, err := getWorkflowFile(ctx, client, owner, repo, branch, workflow)
+	if err != nil {
+		return nil, err
+	}
+	return parseWorkflowSchedules(content)
+}
+
+// parseWorkflowSchedules returns the on.schedule cron expressions of the workflow file.
+func parseWorkflowSchedules(content string) ([]string, error) {
+	var wf struct {
+		On yaml.Node `yaml:"on"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &wf); err != nil {
+		return nil, err
+	}
+	// on can also be a string or a list of events, which have no schedule.
+	var on struct {
+		Schedule []struct {
+			Cron string `yaml:"cron"`
+		} `yaml:"schedule"`
+	}
+	if wf.On.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	if err := wf.On.Decode(&on); err != nil {
+		return nil, err
+	}
+	var crons []string
+	for _, s := range on.Schedule {
+		crons = append(crons, s.Cron)
+	}
+	return crons, nil
+}
+
+// workflowSchedule is the schedule of a workflow and its latest scheduled run.
+type workflowSchedule struct {
+	crons []string
+	// latestRun is the latest scheduled run regardless of its conclusion and the time
+	// range, or nil if there is none.
+	latestRun *github.WorkflowRun
+}
+
+// getSchedulesForWorkflows fetches the cron expressions and the latest scheduled runs of
+// the workflows in parallel, and returns them keyed by workflow file name.
+func getSchedulesForWorkflows(ctx context.Context, client *github.Client, owner, repo, branch string, workflows []string) map[string]workflowSchedule {
+	result := map[string]workflowSchedule{}
+	tasks := make(chan string)
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for workflow := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				crons, err := getWorkflowSchedules(ctx, client, owner, repo, branch, workflow)
+				if err != nil {
+					slog.Debug("Failed to get workflow schedule", slog.String("workflow", workflow), slog.Any("error", err))
+					continue
+				}
+				schedule := workflowSchedule{crons: crons}
+				if len(crons) > 0 {
+					runs, _, err := client.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, workflow, &github.ListWorkflowRunsOptions{
+						Branch:      branch,
+						Event:       "schedule",
+						ListOptions: github.ListOptions{PerPage: 1},
+					})
+					if err != nil {
+						slog.Debug("Failed to get the latest scheduled run", slog.String("workflow", workflow), slog.Any("error", err))
+					} else if len(runs.WorkflowRuns) > 0 {
+						schedule.latestRun = runs.WorkflowRuns[0]
+					}
+				}
+				mux.Lock()
+				result[workflow] = schedule
+				mux.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	for _, workflow := range workflows {
+		tasks <- workflow
+	}
+	close(tasks)
+	wg.Wait()
+	return result
+}
+
+// scheduleLabel returns the cron expressions with the next expected run time. It also
+// flags the schedule as missed if the latest scheduled run was created before the previous
+// expected run.
+func scheduleLabel(schedule workflowSchedule, now time.Time) string {
+	crons := schedule.crons
+	if len(crons) == 0 {
+		return ""
+	}
+	var next, prev time.Time
+	for _, expr := range crons {
+		c, err := parseCron(expr)
+		if err != nil {
+			slog.Debug("Failed to parse cron expression", slog.String("cron", expr), slog.Any("error", err))
+			continue
+		}
+		if n := c.next(now); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+		if p := c.prev(now.Add(-scheduleDelayTolerance)); p.After(prev) {
+			prev = p
+		}
+	}
+	label := fmt.Sprintf("schedule: %s", strings.Join(crons, ", "))
+	if !next.IsZero() {
+		label += fmt.Sprintf(" (next run: %s %s)", formatTime(next), timeZoneName(next))
+	}
+	if latest := schedule.latestRun; !prev.IsZero() && latest != nil && latest.GetCreatedAt().Before(prev) {
+		label += color.New(color.FgRed).Sprintf(" missed run expected at %s %s", formatTime(prev), timeZoneName(prev))
+	}
+	return label
+}
+
+// cronSchedule is a parsed POSIX cron expression as used by GitHub Actions schedules.
+// Times are in UTC.
+type cronSchedule struct {
+	minute, hour, dom, month, dow []bool
+	// domStar and dowStar are true if the field starts with "*", such as "*" or "*/2". If
+	// both day fields are restricted, a time matches if either of them matches.
+	domStar, dowStar bool
+}
+
+func parseCron(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+	var c cronSchedule
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	// Both 0 and 7 are Sunday.
+	c.dow[0] = c.dow[0] || c.dow[7]
+	c.domStar = strings.HasPrefix(fields[2], "*")
+	c.dowStar = strings.HasPrefix(fields[4], "*")
+	return &c, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges, and steps (e.g.
+// "1,5-10,*/15") into a slice indexed by value.
+func parseCronField(field string, lo, hi int) ([]bool, error) {
+	values := make([]bool, hi+1)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+		start, end := lo, hi
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("invalid value in %q", part)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(to); err != nil {
+					return nil, fmt.Errorf("invalid range in %q", part)
+				}
+			} else if hasStep {
+				end = hi
+			}
+		}
+		if start < lo || end > hi || start > end {
+			return nil, fmt.Errorf("%q is out of range %d-%d", part, lo, hi)
+		}
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+func (c *cronSchedule) matches(t time.Time) bool {
+	if !c.minute[t.Minute()] || !c.hour[t.Hour()] || !c.month[t.Month()] {
+		return false
+	}
+	dom := c.dom[t.Day()]
+	dow := c.dow[t.Weekday()]
+	// As in cron, the values of a day field starting with * still apply, such as every
+	// other day for */2, but only restricted fields are combined with or.
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// next returns the first matching time after t, or the zero time if there is none
+// within a year (e.g. February 30).
+func (c *cronSchedule) next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	for end := t.AddDate(1, 0, 0); t.Before(end); t = t.Add(time.Minute) {
+		if c.matches(t) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// prev returns the last matching time at or before t, or the zero time if there is none
+// within a year.
+func (c *cronSchedule) prev(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute)
+	for end := t.AddDate(-1, 0, 0); t.After(end); t = t.Add(-time.Minute) {
+		if c.matches(t) {
+			return t
+		}
+	}
+	return time.Time{}
+}
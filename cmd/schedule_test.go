@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+)
+
+func TestParseCronField(t *testing.T) {
+	for _, tt := range []struct {
+		field   string
+		lo, hi  int
+		want    []int
+		wantErr bool
+	}{
+		{field: "*", lo: 0, hi: 5, want: []int{0, 1, 2, 3, 4, 5}},
+		{field: "3", lo: 0, hi: 5, want: []int{3}},
+		{field: "1,4", lo: 0, hi: 5, want: []int{1, 4}},
+		{field: "2-4", lo: 0, hi: 5, want: []int{2, 3, 4}},
+		{field: "*/2", lo: 0, hi: 5, want: []int{0, 2, 4}},
+		{field: "1-5/2", lo: 0, hi: 5, want: []int{1, 3, 5}},
+		{field: "3/2", lo: 0, hi: 9, want: []int{3, 5, 7, 9}},
+		{field: "1,3-4,*/5", lo: 0, hi: 9, want: []int{0, 1, 3, 4, 5}},
+		{field: "6", lo: 0, hi: 5, wantErr: true},
+		{field: "0", lo: 1, hi: 5, wantErr: true},
+		{field: "4-2", lo: 0, hi: 5, wantErr: true},
+		{field: "*/0", lo: 0, hi: 5, wantErr: true},
+		{field: "a", lo: 0, hi: 5, wantErr: true},
+	} {
+		values, err := parseCronField(tt.field, tt.lo, tt.hi)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCronField(%q) returned %v, want error %t", tt.field, err, tt.wantErr)
+			continue
+		}
+		var got []int
+		for v, ok := range values {
+			if ok {
+				got = append(got, v)
+			}
+		}
+		if !tt.wantErr && !slices.Equal(got, tt.want) {
+			t.Errorf("parseCronField(%q) = %v, want %v", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestCronNextAndPrev(t *testing.T) {
+	// 2024-03-01 is a Friday.
+	now := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name     string
+		expr     string
+		wantNext time.Time
+		wantPrev time.Time
+	}{
+		{"hourly", "0 * * * *",
+			time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{"range of hours", "15 8-9 * * *",
+			time.Date(2024, 3, 2, 8, 15, 0, 0, time.UTC), time.Date(2024, 3, 1, 9, 15, 0, 0, time.UTC)},
+		{"step of minutes", "*/20 10 * * *",
+			time.Date(2024, 3, 1, 10, 40, 0, 0, time.UTC), time.Date(2024, 3, 1, 10, 20, 0, 0, time.UTC)},
+		{"day of week", "0 0 * * 1",
+			time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC)},
+		{"Sunday as 7", "0 0 * * 7",
+			time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 25, 0, 0, 0, 0, time.UTC)},
+		// If both day fields are restricted, either of them matches: the 15th or Mondays.
+		{"day of month or day of week", "0 0 15 * 1",
+			time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC)},
+		// A day of month starting with * is not combined with or, so both day fields must
+		// match: Mondays on odd days.
+		{"stepped day of month and day of week", "0 0 */2 * 1",
+			time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 19, 0, 0, 0, 0, time.UTC)},
+		{"stepped day of month", "0 0 */10 * *",
+			time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		// The next leap day is more than a year away.
+		{"leap day", "0 0 29 2 *", time.Time{}, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"February 30", "0 0 30 2 *", time.Time{}, time.Time{}},
+	} {
+		c, err := parseCron(tt.expr)
+		if err != nil {
+			t.Errorf("%s: parseCron(%q) returned %v", tt.name, tt.expr, err)
+			continue
+		}
+		if got := c.next(now); !got.Equal(tt.wantNext) {
+			t.Errorf("%s: next = %s, want %s", tt.name, got, tt.wantNext)
+		}
+		if got := c.prev(now); !got.Equal(tt.wantPrev) {
+			t.Errorf("%s: prev = %s, want %s", tt.name, got, tt.wantPrev)
+		}
+	}
+}
+
+func TestScheduleLabelMissedRun(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	run := func(created time.Time) *github.WorkflowRun {
+		return &github.WorkflowRun{CreatedAt: &github.Timestamp{Time: created}}
+	}
+	for _, tt := range []struct {
+		name       string
+		latestRun  *github.WorkflowRun
+		wantMissed bool
+	}{
+		{"run after the expected time", run(time.Date(2024, 3, 1, 0, 1, 0, 0, time.UTC)), false},
+		{"run before the expected time", run(time.Date(2024, 2, 29, 0, 1, 0, 0, time.UTC)), true},
+		{"no runs", nil, false},
+	} {
+		label := scheduleLabel(workflowSchedule{crons: []string{"0 0 * * *"}, latestRun: tt.latestRun}, now)
+		if missed := strings.Contains(label, "missed run"); missed != tt.wantMissed {
+			t.Errorf("%s: got label %q, want missed %t", tt.name, label, tt.wantMissed)
+		}
+	}
+}
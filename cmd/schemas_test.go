@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type jsonSchema struct {
+	Required   []string                  `json:"required"`
+	Properties map[string]jsonSchemaType `json:"properties"`
+	Defs       map[string]jsonSchema     `json:"$defs"`
+}
+
+type jsonSchemaType struct {
+	Type any `json:"type"`
+}
+
+func parseSchema(t *testing.T, name, schema string) jsonSchema {
+	t.Helper()
+	var s jsonSchema
+	if err := json.Unmarshal([]byte(schema), &s); err != nil {
+		t.Fatalf("failed to parse the %s schema: %v", name, err)
+	}
+	return s
+}
+
+// propertyNames returns the sorted names of the properties of the schema.
+func (s jsonSchema) propertyNames() []string {
+	var names []string
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestSchemasMatchStructTags(t *testing.T) {
+	reportSchema := parseSchema(t, "report", reportSchema)
+	listSchema := parseSchema(t, "list", workflowInfoSchema)
+	for _, tt := range []struct {
+		name   string
+		schema jsonSchema
+		value  any
+	}{
+		{"report", reportSchema, report{}},
+		{"report workflow", reportSchema.Defs["workflow"], reportWorkflow{}},
+		{"report run", reportSchema.Defs["run"], reportRun{}},
+		{"report job", reportSchema.Defs["job"], reportJob{}},
+		{"list workflow", listSchema.Defs["workflow"], workflowInfo{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields, omitted []string
+			typ := reflect.TypeOf(tt.value)
+			for i := 0; i < typ.NumField(); i++ {
+				name, opts, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+				fields = append(fields, name)
+				if strings.Contains(opts, "omitempty") {
+					omitted = append(omitted, name)
+				}
+			}
+			properties := tt.schema.propertyNames()
+			if slices.Sort(fields); !slices.Equal(fields, properties) {
+				t.Errorf("got json tags %v, want schema properties %v", fields, properties)
+			}
+			for _, name := range tt.schema.Required {
+				if slices.Contains(omitted, name) {
+					t.Errorf("%s is required by the schema but omitted if empty", name)
+				}
+			}
+		})
+	}
+}
+
+func TestExportSchemaMatchesColumns(t *testing.T) {
+	schema := parseSchema(t, "export", exportSchema)
+	runs, jobs, steps := newExportTables()
+	for _, table := range []*exportTable{runs, jobs, steps} {
+		t.Run(table.name, func(t *testing.T) {
+			tableSchema, ok := schema.Defs[table.name]
+			if !ok {
+				t.Fatalf("no schema for table %s", table.name)
+			}
+			if !slices.Equal(table.columns, tableSchema.Required) {
+				t.Errorf("got columns %v, want required columns %v", table.columns, tableSchema.Required)
+			}
+			columns := slices.Clone(table.columns)
+			slices.Sort(columns)
+			if properties := tableSchema.propertyNames(); !slices.Equal(columns, properties) {
+				t.Errorf("got columns %v, want schema properties %v", table.columns, properties)
+			}
+			for i, column := range table.columns {
+				want := "string"
+				if strings.HasPrefix(table.types[i], "INTEGER") {
+					want = "integer"
+				}
+				if got := tableSchema.Properties[column].Type; got != want {
+					t.Errorf("got type %v for column %s, want %s", got, column, want)
+				}
+			}
+		})
+	}
+}
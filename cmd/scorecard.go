@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// timesToRecovery returns the time from the first failure of each failure streak to the
+// completion of the successful run that ended it. Runs are expected to be sorted from
+// newest to oldest.
+func timesToRecovery(runs []*github.WorkflowRun) []time.Duration {
+	var result []time.Duration
+	var recoveredAt time.Time
+	var firstFailure *github.WorkflowRun
+	for _, run := range runs {
+		if run.GetConclusion() == "success" {
+			if firstFailure != nil && !recoveredAt.IsZero() {
+				result = append(result, recoveredAt.Sub(firstFailure.GetRunStartedAt().Time))
+			}
+			recoveredAt = run.GetUpdatedAt().Time
+			firstFailure = nil
+		} else {
+			firstFailure = run
+		}
+	}
+	return result
+}
+
+// printScorecard prints repository-level indicators across all the workflows.
+func printScorecard(result map[string][]*github.WorkflowRun) {
+	var durations, recoveries []time.Duration
+	var failedDuration time.Duration
+	for _, runs := range result {
+		for _, run := range runs {
+			if run.GetConclusion() == "success" {
+				durations = append(durations, runDurations.Of(run))
+			} else {
+				failedDuration += runDurations.Of(run)
+			}
+		}
+		recoveries = append(recoveries, timesToRecovery(runs)...)
+	}
+	rate, success, count := overallSuccessRate(result)
+	mttr := "N/A"
+	if len(recoveries) > 0 {
+		mttr = dashboard.Average(recoveries).Round(time.Minute).String()
+	}
+	color.New(color.Bold).Println("scorecard")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, fmt.Sprintf("success rate\t%0.f%% %d/%d", rate, success, count))
+	fmt.Fprintln(w, fmt.Sprintf("p90 duration\t%s", dashboard.Percentile(durations, 90).Round(time.Second)))
+	fmt.Fprintln(w, fmt.Sprintf("mean time to recovery\t%s", mttr))
+	fmt.Fprintln(w, fmt.Sprintf("failed run minutes\t%.0f", failedDuration.Minutes()))
+	w.Flush()
+	fmt.Println()
+}
@@ -1,29 +1,33 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"io"
 	"log/slog"
-	"net/http"
-	"net/url"
+	"math"
 	"os"
 	"regexp"
 	"slices"
-	"sync"
+	"strconv"
+	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
 	"github.com/spf13/cobra"
 )
 
-var numWorkers = 30
+// exitCodeFetchFailure is the exit code of the show command when some workflows failed to
+// fetch. It is distinct from the exit code for threshold violations.
+const exitCodeFetchFailure = 2
 
 // showCmd represents the show command
 var showCmd = &cobra.Command{
-	Use:   "show",
+	Use:   "show owner repo",
 	Short: "Show CI dashboard",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		debug, err := cmd.Flags().GetBool("debug")
@@ -31,21 +35,18 @@ var showCmd = &cobra.Command{
 			return err
 		}
 		if debug {
-			slog.SetLogLoggerLevel(slog.LevelDebug)
+			setDebug()
 		}
-		if len(args) != 2 {
-			cmd.Usage()
-			os.Exit(1)
+		allRepos, err := cmd.Flags().GetBool("all-repos")
+		if err != nil {
+			return err
 		}
-		token := os.Getenv("GITHUB_TOKEN")
-		if token == "" {
-			slog.Error("Set GITHUB_TOKEN environment variable")
+		if (allRepos && len(args) != 1) || (!allRepos && len(args) != 2) {
+			cmd.Usage()
 			os.Exit(1)
 		}
-		client := github.NewClient(nil).WithAuthToken(token)
 		owner := args[0]
-		repo := args[1]
-		ctx := context.Background()
+		ctx := cmd.Context()
 		branch, err := cmd.Flags().GetString("branch")
 		if err != nil {
 			return err
@@ -54,11 +55,26 @@ var showCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		warnBelow, err = cmd.Flags().GetFloat32("warn-below")
+		if err != nil {
+			return err
+		}
+		criticalBelow, err = cmd.Flags().GetFloat32("critical-below")
+		if err != nil {
+			return err
+		}
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			return err
+		}
+		if all {
+			numRuns = dashboard.AllRuns
+		}
 		workflowFlag, err := cmd.Flags().GetString("workflow")
 		if err != nil {
 			return err
 		}
-		event, err := cmd.Flags().GetString("event")
+		event, err := getEvent(cmd)
 		if err != nil {
 			return err
 		}
@@ -70,270 +86,955 @@ var showCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		sortBy, err := cmd.Flags().GetString("sort")
+		if err != nil {
+			return err
+		}
+		if sortBy != "" && !slices.Contains(summarySortKeys, sortBy) {
+			return fmt.Errorf("unknown sort key %q, expected one of %s", sortBy, strings.Join(summarySortKeys, ", "))
+		}
+		columns, err := cmd.Flags().GetStringSlice("columns")
+		if err != nil {
+			return err
+		}
+		for _, column := range columns {
+			if !slices.Contains(summaryColumnNames, column) {
+				return fmt.Errorf("unknown column %q, expected one of %s", column, strings.Join(summaryColumnNames, ", "))
+			}
+		}
+		below, err := cmd.Flags().GetFloat32("below")
+		if err != nil {
+			return err
+		}
+		summarySlowerThan, err := cmd.Flags().GetDuration("slower-than")
+		if err != nil {
+			return err
+		}
+		halfLife, err := cmd.Flags().GetDuration("half-life")
+		if err != nil {
+			return err
+		}
+		failureDurations, err := cmd.Flags().GetBool("failure-durations")
+		if err != nil {
+			return err
+		}
+		groupBy, err := cmd.Flags().GetString("group-by")
+		if err != nil {
+			return err
+		}
+		if groupBy != "" && !slices.Contains(summaryGroupings, groupBy) {
+			return fmt.Errorf("unknown grouping %q, expected one of %s", groupBy, strings.Join(summaryGroupings, ", "))
+		}
+		summaryOpts := summaryOptions{sortBy: sortBy, columns: columns, below: below, slowerThan: summarySlowerThan, halfLife: halfLife, groupBy: groupBy, failureDurations: failureDurations}
+		summaryTop := top
+		if (below > 0 || summarySlowerThan > 0) && !cmd.Flags().Changed("top") {
+			// Print all the workflows that pass the filters.
+			summaryTop = math.MaxInt
+		}
 		days, err := cmd.Flags().GetInt("days")
 		if err != nil {
 			return err
 		}
+		quarantineFile, err := cmd.Flags().GetString("quarantine-output")
+		if err != nil {
+			return err
+		}
+		quarantinePrune, err := cmd.Flags().GetBool("quarantine-prune")
+		if err != nil {
+			return err
+		}
+		logContext, err := cmd.Flags().GetInt("log-context")
+		if err != nil {
+			return err
+		}
+		if logContext < 0 {
+			return fmt.Errorf("--log-context must be at least 0")
+		}
+		baselineFile, err := cmd.Flags().GetString("baseline")
+		if err != nil {
+			return err
+		}
+		var baseline *report
+		if baselineFile != "" {
+			r, err := readSnapshot(baselineFile)
+			if err != nil {
+				return err
+			}
+			if r.FailedTests == nil && r.ErrorClusters == nil {
+				return fmt.Errorf("baseline %s has no failures, save it with snapshot save --errors", baselineFile)
+			}
+			baseline = &r
+		}
+		fixes, err := cmd.Flags().GetBool("fixes")
+		if err != nil {
+			return err
+		}
+		failureArtifacts, err := cmd.Flags().GetString("failure-artifacts")
+		if err != nil {
+			return err
+		}
+		var artifactRegexp *regexp.Regexp
+		if failureArtifacts != "" {
+			artifactRegexp, err = regexp.Compile(failureArtifacts)
+			if err != nil {
+				return fmt.Errorf("invalid --failure-artifacts regex: %w", err)
+			}
+		}
+		retries, err := cmd.Flags().GetBool("retries")
+		if err != nil {
+			return err
+		}
+		timeToFailure, err := cmd.Flags().GetBool("time-to-failure")
+		if err != nil {
+			return err
+		}
+		queueTime, err := cmd.Flags().GetBool("queue-time")
+		if err != nil {
+			return err
+		}
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		failUnder, err := cmd.Flags().GetFloat32("fail-under")
+		if err != nil {
+			return err
+		}
+		slowerThan, err := cmd.Flags().GetDuration("fail-if-slower-than")
+		if err != nil {
+			return err
+		}
+		groupByMetadata, err := cmd.Flags().GetString("group-by-metadata")
+		if err != nil {
+			return err
+		}
+		scorecard, err := cmd.Flags().GetBool("scorecard")
+		if err != nil {
+			return err
+		}
+		stepRetries, err := cmd.Flags().GetString("step-retries")
+		if err != nil {
+			return err
+		}
+		correlationWindow, err := cmd.Flags().GetDuration("correlation-window")
+		if err != nil {
+			return err
+		}
+		correlationMinWorkflows, err := cmd.Flags().GetInt("correlation-min-workflows")
+		if err != nil {
+			return err
+		}
+		api, err := cmd.Flags().GetString("api")
+		if err != nil {
+			return err
+		}
+		matrix, err := cmd.Flags().GetBool("matrix")
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if err := cfg.checkHealthThresholds(); err != nil {
+			return err
+		}
+		var metadataRules []metadataRule
+		if groupByMetadata != "" {
+			metadataRules, err = cfg.metadataRules(groupByMetadata)
+			if err != nil {
+				return err
+			}
+		}
+		maxLogSize, err := cmd.Flags().GetInt64("max-log-size")
+		if err != nil {
+			return err
+		}
+		maxLogs, err := cmd.Flags().GetInt("max-logs")
+		if err != nil {
+			return err
+		}
+		yes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			return err
+		}
+		durationMethod, err := cmd.Flags().GetString("duration-method")
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(durationMethods, durationMethod) {
+			return fmt.Errorf("unknown duration method %q, expected one of %s", durationMethod, strings.Join(durationMethods, ", "))
+		}
+		includeConclusions, err := cmd.Flags().GetStringSlice("include-conclusions")
+		if err != nil {
+			return err
+		}
+		for _, conclusion := range includeConclusions {
+			if !slices.Contains(optionalConclusions, conclusion) {
+				return fmt.Errorf("unknown conclusion %q, expected one of %s", conclusion, strings.Join(optionalConclusions, ", "))
+			}
+			countedConclusions = append(countedConclusions, conclusion)
+		}
+		includeRepos, err := cmd.Flags().GetStringSlice("include-repos")
+		if err != nil {
+			return err
+		}
+		excludeRepos, err := cmd.Flags().GetStringSlice("exclude-repos")
+		if err != nil {
+			return err
+		}
+		requiredChecks, err := cmd.Flags().GetString("required-checks")
+		if err != nil {
+			return err
+		}
+		actors, err := cmd.Flags().GetBool("actors")
+		if err != nil {
+			return err
+		}
+		branches, err := cmd.Flags().GetStringSlice("branches")
+		if err != nil {
+			return err
+		}
+		staleWorkflows, err := cmd.Flags().GetBool("stale-workflows")
+		if err != nil {
+			return err
+		}
+		timeoutRatio, err := cmd.Flags().GetFloat64("timeout-ratio")
+		if err != nil {
+			return err
+		}
+		anomalies, err := cmd.Flags().GetFloat64("anomalies")
+		if err != nil {
+			return err
+		}
+		histogram, err := cmd.Flags().GetBool("histogram")
+		if err != nil {
+			return err
+		}
+		stepCategories, err := cmd.Flags().GetBool("step-categories")
+		if err != nil {
+			return err
+		}
+		topTests, err := cmd.Flags().GetBool("top-tests")
+		if err != nil {
+			return err
+		}
+		byTeam, err := cmd.Flags().GetBool("by-team")
+		if err != nil {
+			return err
+		}
+		providerName, err := cmd.Flags().GetString("provider")
+		if err != nil {
+			return err
+		}
+		gitlabURL, err := cmd.Flags().GetString("gitlab-url")
+		if err != nil {
+			return err
+		}
 		created := daysToTimeRange(days)
+		printEventWarning(event)
+		switch providerName {
+		case "github":
+		case "gitlab":
+			if allRepos || api != "rest" || output != "text" {
+				return fmt.Errorf("--all-repos, --api graphql, and --output html-bundle are not supported for GitLab")
+			}
+			for _, name := range gitlabUnsupportedShowFlags {
+				if cmd.Flags().Changed(name) {
+					return fmt.Errorf("--%s is not supported for GitLab", name)
+				}
+			}
+			p, err := newGitLabProvider(gitlabURL)
+			if err != nil {
+				return err
+			}
+			workflows := []string{gitlabDefaultWorkflow}
+			if workflowFlag != "" {
+				workflows = []string{workflowFlag}
+			}
+			result, failures := fetchWorkflowRuns(ctx, p, owner, args[1], branch, workflows, event, numRuns, created)
+			if summary {
+				printSummary(cfg, p, owner, args[1], branch, event, result, summaryTop, numRuns, summaryOpts)
+				printGroups(cfg.Groups, result)
+			} else {
+				for _, workflow := range sortWorkflowsBySuccessRate(result) {
+					printDashboard(cfg, p, owner, args[1], branch, workflow, event, result[workflow], workflowSchedule{}, numRuns, failureDurations)
+				}
+			}
+			printFetchFailures(os.Stdout, failures)
+			exitOnFailures(cfg, result, failures, failUnder, slowerThan)
+			return nil
+		default:
+			return fmt.Errorf("unknown provider %q", providerName)
+		}
+		client := newClient()
+		if allRepos {
+			repos, err := getOrgRepos(ctx, client, owner, includeRepos, excludeRepos)
+			if err != nil {
+				return err
+			}
+			printOrgSummary(owner, getOrgWorkflowRuns(ctx, client, owner, repos, branch, event, numRuns, created), top)
+			return nil
+		}
+		repo := args[1]
 		var workflows []string
 		details := false
 		if workflowFlag != "" {
 			workflows = append(workflows, workflowFlag)
 			details = true
 		} else {
-			wf, err := getWorkflows(ctx, client, owner, repo)
+			wf, err := dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
 			if err != nil {
 				return err
 			}
 			workflows = append(workflows, wf...)
 		}
-		tasks := make(chan string)
-		result := map[string][]*github.WorkflowRun{}
-		wg := sync.WaitGroup{}
-		mux := sync.Mutex{}
-		for i := 0; i < numWorkers; i++ {
-			wg.Add(1)
-			go func() {
-				for workflow := range tasks {
-					runs, err := getWorkflowRuns(ctx, client, owner, repo, branch, workflow, event, numRuns, created)
-					if err != nil {
-						slog.Error("Failed to get workflow runs", slog.Any("error", err))
-						continue
+		if len(branches) > 0 {
+			eventFlag, err := cmd.Flags().GetString("event")
+			if err != nil {
+				return err
+			}
+			branchResult := map[string]map[string][]*github.WorkflowRun{}
+			failures := map[string]error{}
+			for _, b := range branches {
+				branchEvent := eventFlag
+				if eventFlag == "auto" {
+					if branchEvent, err = selectEvent(cfg, b); err != nil {
+						return err
 					}
-					mux.Lock()
-					result[workflow] = runs
-					mux.Unlock()
 				}
-				wg.Done()
-			}()
-
+				var branchFailures map[string]error
+				branchResult[b], branchFailures = fetchWorkflowRuns(ctx, githubProvider{client}, owner, repo, b, workflows, branchEvent, numRuns, created)
+				for workflow, err := range branchFailures {
+					failures[fmt.Sprintf("%s (%s)", workflow, b)] = err
+				}
+			}
+			printBranchMatrix(cfg, branches, branchResult)
+			printFetchFailures(os.Stdout, failures)
+			if len(failures) > 0 {
+				os.Exit(exitCodeFetchFailure)
+			}
+			return nil
+		}
+		var result map[string][]*github.WorkflowRun
+		var failures map[string]error
+		switch api {
+		case "rest":
+			result, failures = fetchWorkflowRuns(ctx, githubProvider{client}, owner, repo, branch, workflows, event, numRuns, created)
+		case "graphql":
+			since, until := timeRangeBounds(days)
+			if !until.IsZero() {
+				return fmt.Errorf("--until is not supported with --api graphql")
+			}
+			result, err = getWorkflowRunsGraphQL(ctx, client, owner, repo, branch, workflows, event, numRuns, since)
+			if err != nil {
+				return err
+			}
+			// getWorkflowRuns applies the success criteria to the runs fetched with the REST
+			// API.
+			if successCriteria != nil {
+				for _, runs := range result {
+					dashboard.ApplySuccessCriteria(ctx, client.Actions, owner, repo, runs, successCriteria, numWorkers)
+				}
+			}
+		default:
+			return fmt.Errorf("unknown API %q", api)
+		}
+		if durationMethod != "updated" {
+			applyDurationMethod(ctx, client, owner, repo, durationMethod, result)
+		}
+		if ctx.Err() != nil {
+			slog.Warn("Interrupted, showing partial results", slog.Any("error", ctx.Err()))
+		}
+		if output == "html-bundle" {
+			r := newReport(owner, repo, branch, event, result)
+			var allRuns []*github.WorkflowRun
+			for _, runs := range result {
+				allRuns = append(allRuns, runs...)
+			}
+			r.addJobs(dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, allRuns, numWorkers))
+			if err := writeHTMLBundle(os.Stdout, r, cfg); err != nil {
+				return err
+			}
+			// The bundle is written to stdout, so report the failures on stderr.
+			printFetchFailures(os.Stderr, failures)
+			exitOnFailures(cfg, result, failures, failUnder, slowerThan)
+			return nil
+		} else if output != "text" {
+			return fmt.Errorf("unknown output format %q", output)
 		}
-		for _, workflow := range workflows {
-			tasks <- workflow
+		if scorecard {
+			printScorecard(result)
 		}
-		close(tasks)
-		wg.Wait()
 		if summary {
-			printSummary(owner, repo, branch, event, result, top)
+			printSummary(cfg, githubProvider{client}, owner, repo, branch, event, result, summaryTop, numRuns, summaryOpts)
+			printGroups(cfg.Groups, result)
+			if requiredChecks != "" {
+				if err := printRequiredChecks(ctx, client, owner, repo, requiredChecks, result); err != nil {
+					return err
+				}
+			}
+			if err := printDimensions(cfg.Dimensions, result); err != nil {
+				return err
+			}
+			printCorrelatedFailures(result, correlationWindow, correlationMinWorkflows)
 
 		} else {
-			for workflow, runs := range result {
-				printDashboard(owner, repo, branch, workflow, event, runs)
+			var schedules map[string]workflowSchedule
+			if event == "schedule" {
+				schedules = getSchedulesForWorkflows(ctx, client, owner, repo, branch, workflows)
+			}
+			for _, workflow := range sortWorkflowsBySuccessRate(result) {
+				runs := result[workflow]
+				printDashboard(cfg, githubProvider{client}, owner, repo, branch, workflow, event, runs, schedules[workflow], numRuns, failureDurations)
 				if details {
-					printDetailedDashboard(ctx, client, owner, repo, runs)
+					if err := printDetailedDashboard(ctx, client, cfg, owner, repo, runs, quarantineFile, quarantinePrune, logContext, baseline, maxLogSize, yes); err != nil {
+						return err
+					}
+					if artifactRegexp != nil {
+						printFailureArtifacts(ctx, client, owner, repo, runs, artifactRegexp)
+					}
+					if fixes {
+						printProbableFixes(ctx, client, owner, repo, runs)
+					}
 				}
 			}
 		}
+		if !details {
+			removed, err := getRemovedWorkflowRuns(ctx, client, owner, repo, branch, event, numRuns, created)
+			if err != nil {
+				slog.Error("Failed to get removed workflows", slog.Any("error", err))
+			} else {
+				printRemovedWorkflows(removed)
+			}
+			if staleWorkflows {
+				printStaleWorkflowSuggestions(owner, repo, branch, getStaleWorkflows(ctx, client, owner, repo, result, created), days)
+			}
+		}
+		if anomalies > 0 {
+			printAnomalies(result, anomalies)
+		}
+		if histogram {
+			printHistograms(result)
+		}
+		if timeoutRatio > 0 {
+			printTimeoutProneJobs(ctx, client, owner, repo, branch, result, timeoutRatio)
+		}
+		if stepCategories {
+			if err := printStepCategories(ctx, client, cfg, owner, repo, result); err != nil {
+				return err
+			}
+		}
+		if topTests {
+			if err := printTopFailingTests(ctx, client, owner, repo, result, top, maxLogs, maxLogSize, yes); err != nil {
+				return err
+			}
+		}
+		if byTeam {
+			if err := printTeams(ctx, client, cfg.Owners, owner, repo, branch, event, result); err != nil {
+				return err
+			}
+		}
+		if actors {
+			printActorStats(result, top)
+		}
+		if retries {
+			printRetryStats(result)
+		}
+		if timeToFailure {
+			printTimeToFirstFailure(ctx, client, owner, repo, result)
+		}
+		if queueTime {
+			printQueueTime(ctx, client, owner, repo, result)
+		}
+		if matrix {
+			printMatrixStats(ctx, client, owner, repo, result)
+		}
+		if stepRetries != "" {
+			stepRegexp, err := regexp.Compile(stepRetries)
+			if err != nil {
+				return fmt.Errorf("invalid --step-retries regex: %w", err)
+			}
+			printStepRetries(ctx, client, owner, repo, result, stepRegexp)
+		}
+		if groupByMetadata != "" {
+			var allRuns []*github.WorkflowRun
+			for _, runs := range result {
+				allRuns = append(allRuns, runs...)
+			}
+			metadata, err := getRunMetadata(ctx, client, owner, repo, allRuns, metadataRules)
+			if err != nil {
+				return err
+			}
+			printMetadataGroups(groupByMetadata, result, metadata)
+		}
+		printFetchFailures(os.Stdout, failures)
+		exitOnFailures(cfg, result, failures, failUnder, slowerThan)
 		return nil
 	},
 }
 
-func daysToTimeRange(days int) string {
-	now := time.Now()
-	d := time.Duration(days) * 24 * time.Hour
-	from := now.Add(-d)
-	return fmt.Sprintf(">=%s", from.Format(time.RFC3339))
+// exitOnFailures exits with 1 if a workflow exceeds one of the thresholds, or with
+// exitCodeFetchFailure if some workflows failed to fetch.
+func exitOnFailures(cfg *config, result map[string][]*github.WorkflowRun, failures map[string]error, failUnder float32, slowerThan time.Duration) {
+	if checkThresholds(cfg, result, failUnder, slowerThan) {
+		os.Exit(1)
+	}
+	if len(failures) > 0 {
+		os.Exit(exitCodeFetchFailure)
+	}
 }
 
 type workflowStats struct {
-	workflow        string
-	from            string
-	to              string
-	averageDuration time.Duration
-	successRate     float32
-	success         int
-	count           int
+	dashboard.WorkflowStats
+	limitedBy     string
+	rateTrend     string
+	durationTrend string
+}
+
+// summaryOptions customize the summary. The summary consists of the top n workflows by
+// success rate and by average duration unless a sort key or columns are selected, in which
+// case a single table is printed.
+type summaryOptions struct {
+	// sortBy is one of summarySortKeys. Defaults to rate.
+	sortBy string
+	// columns are the names of summaryColumns to print. Defaults to
+	// defaultSummaryColumns.
+	columns []string
+	// below only includes workflows with a success rate below this percentage if set.
+	below float32
+	// slowerThan only includes workflows with a longer average duration if set.
+	slowerThan time.Duration
+	// halfLife weights the success rate by recency if set. See dashboard.WeightedSuccessRate.
+	halfLife time.Duration
+	// failureDurations adds the average duration of the unsuccessful runs.
+	failureDurations bool
+	// groupBy is one of summaryGroupings if set, in which case a table per workflow with a
+	// row per period is printed instead.
+	groupBy string
+}
+
+// rateHeader returns the header of the success rate column.
+func (o summaryOptions) rateHeader() string {
+	if o.halfLife > 0 {
+		return "weighted success rate"
+	}
+	return "success rate"
 }
 
-func printSummary(owner, repo, branch, event string, result map[string][]*github.WorkflowRun, top int) {
+// formatRate formats the success rate followed by the number of successful and total
+// runs. The counts are labeled as unweighted if the rate is weighted by recency.
+func (o summaryOptions) formatRate(status string, stats workflowStats) string {
+	if o.halfLife > 0 {
+		return fmt.Sprintf("%s (%d/%d unweighted)", status, stats.Success, stats.Runs)
+	}
+	return fmt.Sprintf("%s %d/%d", status, stats.Success, stats.Runs)
+}
+
+// include returns true if the workflow passes the --below and --slower-than filters.
+func (o summaryOptions) include(stats workflowStats) bool {
+	return (o.below == 0 || stats.SuccessRate < o.below) && (o.slowerThan == 0 || stats.AverageDuration > o.slowerThan)
+}
+
+var (
+	summarySortKeys       = []string{"rate", "duration", "failures", "name"}
+	summaryColumnNames    = []string{"from", "to", "rate", "conclusions", "limited-by", "trend", "streak", "last-green", "duration", "failure-duration", "duration-trend", "failures", "workflow"}
+	defaultSummaryColumns = []string{"from", "to", "rate", "conclusions", "duration", "failures", "workflow"}
+)
+
+// getSummaryStats returns the stats of the workflows that pass the filters of the
+// options, in no particular order.
+func getSummaryStats(result map[string][]*github.WorkflowRun, numRuns int, opts summaryOptions) []workflowStats {
 	var statsList []workflowStats
 	for workflow, runs := range result {
 		if len(runs) == 0 {
 			continue
 		}
-		count := len(runs)
-		from := runs[count-1].GetRunStartedAt().Format(time.DateOnly)
-		to := runs[0].GetRunStartedAt().Format(time.DateOnly)
-		success := 0
-		var totalSeconds float64
-		for i := 0; i < count; i++ {
-			if runs[i].GetConclusion() == "success" {
-				success++
-				totalSeconds += runs[i].GetUpdatedAt().Time.Sub(runs[i].GetRunStartedAt().Time).Seconds()
-			}
-		}
 		stats := workflowStats{
-			workflow:        workflow,
-			from:            from,
-			to:              to,
-			averageDuration: time.Second * time.Duration(totalSeconds/float64(success)),
-			successRate:     100 * float32(success) / float32(count),
-			success:         success,
-			count:           count,
+			WorkflowStats: dashboard.NewWorkflowStats(workflow, runs, runDurations),
+			limitedBy:     limitedBy(workflow, runs, numRuns),
+			rateTrend:     successRateSparkline(runs),
+			durationTrend: durationSparkline(runs),
+		}
+		if opts.halfLife > 0 {
+			stats.SuccessRate = dashboard.WeightedSuccessRate(runs, opts.halfLife)
+		}
+		if !opts.include(stats) {
+			continue
 		}
 		statsList = append(statsList, stats)
 	}
+	return statsList
+}
+
+func printSummary(cfg *config, p provider, owner, repo, branch, event string, result map[string][]*github.WorkflowRun, top, numRuns int, opts summaryOptions) {
+	statsList := getSummaryStats(result, numRuns, opts)
+	if opts.groupBy != "" {
+		slices.SortFunc(statsList, func(a, b workflowStats) int {
+			return cmp.Or(cmp.Compare(a.SuccessRate, b.SuccessRate), cmp.Compare(a.Workflow, b.Workflow))
+		})
+		printGroupedSummary(result, statsList, top, opts)
+		return
+	}
+	if opts.sortBy != "" || len(opts.columns) > 0 {
+		printSummaryTable(cfg, p, owner, repo, branch, event, statsList, top, opts)
+		return
+	}
 	slices.SortFunc(statsList, func(a, b workflowStats) int {
-		return int(a.successRate - b.successRate)
+		return int(a.SuccessRate - b.SuccessRate)
 	})
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
-	fmt.Fprintln(w, "from\tto\tsuccess rate\tworkflow")
+	fmt.Fprintln(w, fmt.Sprintf("from\tto\t%s\tconclusions\tlimited by\ttrend\tstreak\tlast green\tworkflow", opts.rateHeader()))
 	for i, stats := range statsList {
 		if i >= top {
 			break
 		}
 		link := color.New(color.FgCyan, color.Bold).SprintFunc()
-		workflowURL := fmt.Sprintf("https://github.com/%s/%s/actions/workflows/%s?query=branch%%3A%s+event%%3A%s++",
-			owner, repo, stats.workflow, branch, event)
-		status := fmt.Sprintf("%0.f%%", stats.successRate)
-		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s %d/%d\t%s",
-			stats.from, stats.to, status, stats.success, stats.count, link(getLink(workflowURL, stats.workflow)),
+		workflowURL := p.workflowURL(owner, repo, branch, stats.Workflow, event)
+		status := fmt.Sprintf("%0.f%%", stats.SuccessRate)
+		// A failure streak since the last green run tells a broken workflow apart from a
+		// flaky one with a similar success rate.
+		lastGreen := "never"
+		if stats.LastSuccess != nil {
+			lastGreen = getLink(stats.LastSuccess.GetHTMLURL(), formatTime(stats.LastSuccess.GetRunStartedAt().Time))
+		}
+		streak := ""
+		if stats.FailureStreak > 0 {
+			streak = color.New(color.FgRed).Sprintf("%d failures", stats.FailureStreak)
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s %s",
+			formatDate(stats.From), formatDate(stats.To), opts.formatRate(status, stats), conclusionBreakdown(stats.Workflow, stats.Conclusions, stats.From), stats.limitedBy, stats.rateTrend, streak, lastGreen, link(getLink(workflowURL, stats.Workflow)),
+			cfg.knownIssueLabel(stats.Workflow),
 		))
 	}
 	w.Flush()
 	slices.SortFunc(statsList, func(a, b workflowStats) int {
-		return int(b.averageDuration - a.averageDuration)
+		return int(b.AverageDuration - a.AverageDuration)
 	})
-	fmt.Fprintln(w, "from\tto\taverage duration\tworkflow")
+	if opts.failureDurations {
+		fmt.Fprintln(w, "from\tto\taverage duration\tfailure duration\ttrend\tworkflow")
+	} else {
+		fmt.Fprintln(w, "from\tto\taverage duration\ttrend\tworkflow")
+	}
 	for i, stats := range statsList {
 		if i >= top {
 			break
 		}
 		link := color.New(color.FgCyan, color.Bold).SprintFunc()
-		workflowURL := fmt.Sprintf("https://github.com/%s/%s/actions/workflows/%s?query=branch%%3A%s+event%%3A%s++",
-			owner, repo, stats.workflow, branch, event)
-		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s %d/%d\t%s",
-			stats.from, stats.to, stats.averageDuration, stats.success, stats.count, link(getLink(workflowURL, stats.workflow)),
+		workflowURL := p.workflowURL(owner, repo, branch, stats.Workflow, event)
+		duration := fmt.Sprintf("%s %d/%d", formatAverageDuration(stats.AverageDuration, stats.Success), stats.Success, stats.Runs)
+		if opts.failureDurations {
+			failures := stats.Runs - stats.Success
+			duration += fmt.Sprintf("\t%s %d/%d", formatAverageDuration(stats.AverageFailureDuration, failures), failures, stats.Runs)
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%s\t%s",
+			formatDate(stats.From), formatDate(stats.To), duration, stats.durationTrend, link(getLink(workflowURL, stats.Workflow)),
 		))
 	}
 	w.Flush()
 }
 
+// printSummaryTable prints the top n workflows sorted by the sort key with the selected
+// columns.
+func printSummaryTable(cfg *config, p provider, owner, repo, branch, event string, statsList []workflowStats, top int, opts summaryOptions) {
+	sortBy := cmp.Or(opts.sortBy, "rate")
+	slices.SortFunc(statsList, func(a, b workflowStats) int {
+		var c int
+		switch sortBy {
+		case "rate":
+			c = cmp.Compare(a.SuccessRate, b.SuccessRate)
+		case "duration":
+			c = cmp.Compare(b.AverageDuration, a.AverageDuration)
+		case "failures":
+			c = cmp.Compare(b.Runs-b.Success, a.Runs-a.Success)
+		}
+		return cmp.Or(c, cmp.Compare(a.Workflow, b.Workflow))
+	})
+	columns := opts.columns
+	if len(columns) == 0 {
+		columns = defaultSummaryColumns
+		if opts.failureDurations {
+			i := slices.Index(columns, "duration") + 1
+			columns = slices.Insert(slices.Clone(columns), i, "failure-duration")
+		}
+	}
+	link := color.New(color.FgCyan, color.Bold).SprintFunc()
+	headers := map[string]string{
+		"from":             "from",
+		"to":               "to",
+		"rate":             opts.rateHeader(),
+		"conclusions":      "conclusions",
+		"limited-by":       "limited by",
+		"trend":            "trend",
+		"streak":           "streak",
+		"last-green":       "last green",
+		"duration":         "average duration",
+		"failure-duration": "failure duration",
+		"duration-trend":   "duration trend",
+		"failures":         "failures",
+		"workflow":         "workflow",
+	}
+	value := func(column string, stats workflowStats) string {
+		switch column {
+		case "from":
+			return formatDate(stats.From)
+		case "to":
+			return formatDate(stats.To)
+		case "rate":
+			return opts.formatRate(fmt.Sprintf("%0.f%%", stats.SuccessRate), stats)
+		case "conclusions":
+			return conclusionBreakdown(stats.Workflow, stats.Conclusions, stats.From)
+		case "limited-by":
+			return stats.limitedBy
+		case "trend":
+			return stats.rateTrend
+		case "streak":
+			if stats.FailureStreak > 0 {
+				return color.New(color.FgRed).Sprintf("%d failures", stats.FailureStreak)
+			}
+			return ""
+		case "last-green":
+			if stats.LastSuccess != nil {
+				return getLink(stats.LastSuccess.GetHTMLURL(), formatTime(stats.LastSuccess.GetRunStartedAt().Time))
+			}
+			return "never"
+		case "duration":
+			return formatAverageDuration(stats.AverageDuration, stats.Success)
+		case "failure-duration":
+			return formatAverageDuration(stats.AverageFailureDuration, stats.Runs-stats.Success)
+		case "duration-trend":
+			return stats.durationTrend
+		case "failures":
+			return strconv.Itoa(stats.Runs - stats.Success)
+		default:
+			workflowURL := p.workflowURL(owner, repo, branch, stats.Workflow, event)
+			return fmt.Sprintf("%s %s", link(getLink(workflowURL, stats.Workflow)), cfg.knownIssueLabel(stats.Workflow))
+		}
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	var header []string
+	for _, column := range columns {
+		header = append(header, headers[column])
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for i, stats := range statsList {
+		if i >= top {
+			break
+		}
+		var values []string
+		for _, column := range columns {
+			values = append(values, value(column, stats))
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	w.Flush()
+}
+
+// sortWorkflowsBySuccessRate returns workflow names sorted by success rate, lowest first.
+func sortWorkflowsBySuccessRate(result map[string][]*github.WorkflowRun) []string {
+	var workflows []string
+	for workflow := range result {
+		workflows = append(workflows, workflow)
+	}
+	slices.SortFunc(workflows, func(a, b string) int {
+		return cmp.Or(cmp.Compare(dashboard.SuccessRate(result[a]), dashboard.SuccessRate(result[b])), cmp.Compare(a, b))
+	})
+	return workflows
+}
+
 func getLink(url, text string) string {
+	if plainOutput {
+		return text
+	}
 	return fmt.Sprintf("\033]8;;%s\033\\%s\033]8;;\033\\", url, text)
 }
 
-func printDashboard(owner, repo, branch, workflow, event string, runs []*github.WorkflowRun) {
-	count := min(len(runs), 4)
+func printDashboard(cfg *config, p provider, owner, repo, branch, workflow, event string, runs []*github.WorkflowRun, schedule workflowSchedule, numRuns int, failureDurations bool) {
 	bold := color.New(color.Bold).SprintFunc()
 	link := color.New(color.FgCyan, color.Underline).SprintFunc()
 	fmt.Println(bold(workflow),
-		link(fmt.Sprintf("https://github.com/%s/%s/actions/workflows/%s?query=branch%%3A%s+event%%3A%s++",
-			owner, repo, workflow, branch, event)))
+		link(p.workflowURL(owner, repo, branch, workflow, event)),
+		cfg.knownIssueLabel(workflow))
+	if label := scheduleLabel(schedule, time.Now()); label != "" {
+		fmt.Println(label)
+	}
 	if len(runs) == 0 {
 		return
 	}
+	fmt.Printf("%d runs, limited by %s\n", len(runs), limitedBy(workflow, runs, numRuns))
+	warn, critical := cfg.healthThresholds(workflow)
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
-	fmt.Fprintln(w, "from\tto\tduration\tsuccess rate\t")
-	for ; len(runs) >= count; count *= 2 {
-		from := runs[count-1].GetRunStartedAt().Format(time.DateTime)
-		to := runs[0].GetRunStartedAt().Format(time.DateTime)
-		success := 0
-		var totalSeconds float64
-		for i := 0; i < count; i++ {
-			if runs[i].GetConclusion() == "success" {
-				success++
-				totalSeconds += runs[i].GetUpdatedAt().Time.Sub(runs[i].GetRunStartedAt().Time).Seconds()
-			}
-		}
-		avgDuration := "N/A"
-		if totalSeconds != 0 {
-			avgDuration = (time.Second * time.Duration(totalSeconds/float64(success))).String()
-		}
-		successRate := 100 * float32(success) / float32(count)
-		statusColor := color.New(color.FgGreen).SprintFunc()
-		emoji := "🥰"
-		if successRate < 50 {
-			statusColor = color.New(color.FgRed).SprintFunc()
-			emoji = "🙀"
-		} else if successRate < 80 {
-			statusColor = color.New(color.FgYellow).SprintFunc()
-			emoji = "🤨"
-		}
-		status := fmt.Sprintf("%s %0.f%%", emoji, successRate)
-		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%s\t%d/%d", from, to, avgDuration, statusColor(status), success, count))
+	if failureDurations {
+		fmt.Fprintln(w, "from\tto\tduration\tfailure duration\tsuccess rate\t\tconclusions")
+	} else {
+		fmt.Fprintln(w, "from\tto\tduration\tsuccess rate\t\tconclusions")
+	}
+	for _, stats := range dashboard.WindowStats(workflow, runs, runDurations) {
+		avgDuration := formatAverageDuration(stats.AverageDuration, stats.Success)
+		if failureDurations {
+			avgDuration += "\t" + formatAverageDuration(stats.AverageFailureDuration, stats.Runs-stats.Success)
+		}
+		statusColor, symbol := successRateStatus(stats.SuccessRate, warn, critical)
+		status := fmt.Sprintf("%s %0.f%%", symbol, stats.SuccessRate)
+		if plainOutput && !asciiOutput {
+			status = fmt.Sprintf("%0.f%%", stats.SuccessRate)
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%s\t%d/%d\t%s", formatTime(stats.From), formatTime(stats.To), avgDuration, statusColor.Sprint(status), stats.Success, stats.Runs, conclusionBreakdown(stats.Workflow, stats.Conclusions, stats.From)))
 	}
 	w.Flush()
+}
 
+// successRateStatus returns the color and the symbol for the success rate given the WARN
+// and FAIL thresholds. The symbol is an emoji, or a textual grade with --ascii.
+func successRateStatus(successRate, warn, critical float32) (*color.Color, string) {
+	switch {
+	case successRate < critical:
+		return color.New(color.FgRed), statusSymbol("🙀", "FAIL")
+	case successRate < warn:
+		return color.New(color.FgYellow), statusSymbol("🤨", "WARN")
+	default:
+		return color.New(color.FgGreen), statusSymbol("🥰", "OK")
+	}
 }
 
-func printDetailedDashboard(ctx context.Context, client *github.Client, owner, repo string, runs []*github.WorkflowRun) {
-	failedJobCount := make(map[string]int)
-	failedStepCount := make(map[string]int)
-	cancelledStepCount := make(map[string]int)
-	var logsURLs []*url.URL
-	tasks := make(chan int64)
-	wg := sync.WaitGroup{}
-	mux := sync.Mutex{}
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			for runID := range tasks {
-				jobs, err := getJobs(ctx, client, owner, repo, runID)
-				if err != nil {
-					slog.Error("Failed to get workflow runs", slog.Any("error", err))
-					continue
-				}
-				for _, job := range jobs {
-					if job.GetConclusion() == "failure" {
-						logsURL, _, err := client.Actions.GetWorkflowJobLogs(ctx, owner, repo, job.GetID(), 10)
-						mux.Lock()
-						if err == nil {
-							logsURLs = append(logsURLs, logsURL)
-						}
-						count, ok := failedJobCount[job.GetName()]
-						if ok {
-							failedJobCount[job.GetName()] = count + 1
-						} else {
-							failedJobCount[job.GetName()] = 1
-						}
-						for _, step := range job.Steps {
-							if step.GetConclusion() == "failure" {
-								count, ok := failedStepCount[step.GetName()]
-								if ok {
-									failedStepCount[step.GetName()] = count + 1
-								} else {
-									failedStepCount[step.GetName()] = 1
-								}
-							} else if step.GetConclusion() == "cancelled" {
-								count, ok := cancelledStepCount[step.GetName()]
-								if ok {
-									cancelledStepCount[step.GetName()] = count + 1
-								} else {
-									cancelledStepCount[step.GetName()] = 1
-								}
-							}
-						}
-						mux.Unlock()
-					}
-				}
-			}
-			wg.Done()
-		}()
+func statusSymbol(emoji, grade string) string {
+	if asciiOutput {
+		return grade
+	}
+	return emoji
+}
+
+// printFetchFailures prints the workflows that failed to fetch to out so that they are
+// not mistaken for workflows without runs.
+func printFetchFailures(out io.Writer, failures map[string]error) {
+	if len(failures) == 0 {
+		return
+	}
+	var workflows []string
+	for workflow := range failures {
+		workflows = append(workflows, workflow)
+	}
+	slices.Sort(workflows)
+	color.New(color.FgRed, color.Bold).Fprintln(out, "\nfailed to fetch")
+	w := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "workflow\treason")
+	for _, workflow := range workflows {
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s", workflow, failures[workflow]))
+	}
+	w.Flush()
+}
+
+// printRemovedWorkflows prints workflows whose files were deleted but still have runs
+// in the time range.
+func printRemovedWorkflows(result map[string][]*github.WorkflowRun) {
+	if len(result) == 0 {
+		return
+	}
+	color.New(color.FgYellow, color.Bold).Println("\nremoved workflows with recent runs")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "workflow\tlast run\tsuccess rate\truns")
+	for _, workflow := range sortWorkflowsBySuccessRate(result) {
+		runs := result[workflow]
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%.0f%%\t%d",
+			workflow, formatTime(runs[0].GetRunStartedAt().Time), dashboard.SuccessRate(runs), len(runs)))
+	}
+	w.Flush()
+}
+
+// formatAverageDuration formats the average duration of count runs, or returns N/A if
+// there are no runs to average.
+func formatAverageDuration(d time.Duration, count int) string {
+	if count == 0 {
+		return "N/A"
+	}
+	return d.String()
+}
+
+// limitedBy returns which of the --number and --days flags limited the runs of a workflow,
+// given the --number flag, or "all runs" if neither did.
+func limitedBy(workflow string, runs []*github.WorkflowRun, count int) string {
+	allRunsFetchedMu.Lock()
+	all := allRunsFetched[workflow]
+	allRunsFetchedMu.Unlock()
+	switch {
+	case limitMode == "and" && len(runs) >= count:
+		return "--number"
+	case limitMode == "and" && all:
+		return "all runs"
+	case limitMode == "and":
+		return "--days"
+	case len(runs) > count:
+		return "--days"
+	case len(runs) == count:
+		return "--number"
+	default:
+		return "all runs"
 	}
-	for _, run := range runs {
-		if run.GetConclusion() == "failure" {
-			tasks <- run.GetID()
+}
+
+// conclusionBreakdown formats the number of runs of the workflow since from for each
+// counted conclusion, followed by the cancelled and timed out runs if they are not
+// counted, e.g. "success 10, failure 3, not counted: cancelled 2".
+func conclusionBreakdown(workflow string, counts map[string]int, from time.Time) string {
+	var parts []string
+	for _, conclusion := range countedConclusions {
+		if counts[conclusion] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", conclusion, counts[conclusion]))
+		}
+	}
+	excludedRunsMu.Lock()
+	excluded := excludedRuns[workflow]
+	excludedRunsMu.Unlock()
+	var notCounted []string
+	for _, conclusion := range []string{"cancelled", "timed_out"} {
+		if slices.Contains(countedConclusions, conclusion) {
+			continue
+		}
+		count := 0
+		for _, run := range excluded {
+			if run.GetConclusion() == conclusion && !run.GetRunStartedAt().Before(from) {
+				count++
+			}
+		}
+		if count > 0 {
+			notCounted = append(notCounted, fmt.Sprintf("%s %d", conclusion, count))
 		}
 	}
-	close(tasks)
-	wg.Wait()
+	breakdown := strings.Join(parts, ", ")
+	if len(notCounted) > 0 {
+		breakdown += ", not counted: " + strings.Join(notCounted, ", ")
+	}
+	return breakdown
+}
 
-	failedJobs := sortMapByValue(failedJobCount)
-	failedSteps := sortMapByValue(failedStepCount)
-	cancelledSteps := sortMapByValue(cancelledStepCount)
+func printDetailedDashboard(ctx context.Context, client *github.Client, cfg *config, owner, repo string, runs []*github.WorkflowRun, quarantineFile string, quarantinePrune bool, logContext int, baseline *report, maxLogSize int64, yes bool) error {
+	normalizer, err := newNameNormalizer(cfg.NameRules)
+	if err != nil {
+		return err
+	}
+	known, err := newKnownIssueMatcher(cfg.KnownIssues)
+	if err != nil {
+		return err
+	}
+	details := getFailureDetails(ctx, client, owner, repo, runs)
+	failedJobs := dashboard.SortByCount(normalizer.normalizeCounts(details.FailedJobs))
+	failedSteps := dashboard.SortByCount(normalizer.normalizeCounts(details.FailedSteps))
+	cancelledSteps := dashboard.SortByCount(normalizer.normalizeCounts(details.CancelledSteps))
+	jobURLs := normalizer.normalizeURLs(details.JobURLs)
+	stepURLs := normalizer.normalizeURLs(details.StepURLs)
+	link := color.New(color.FgCyan).SprintFunc()
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
 	red := color.New(color.FgRed, color.Bold)
 	red.Println("\nfailed jobs")
 	fmt.Fprintln(w, "job name\tfailure count")
 	for _, count := range failedJobs {
-		fmt.Fprintln(w, fmt.Sprintf("%s\t%d", count.Name, count.Count))
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%d", link(getLink(jobURLs[count.Name], count.Name)), count.Count))
 	}
 	w.Flush()
 	red.Println("\nfailed steps")
 	fmt.Fprintln(w, "step name\tfailure count")
 	for _, count := range failedSteps {
-		fmt.Fprintln(w, fmt.Sprintf("%s\t%d", count.Name, count.Count))
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%d", link(getLink(stepURLs[count.Name], count.Name)), count.Count))
 	}
 	w.Flush()
 	red.Println("\ncancelled steps")
@@ -342,125 +1043,88 @@ func printDetailedDashboard(ctx context.Context, client *github.Client, owner, r
 		fmt.Fprintln(w, fmt.Sprintf("%s\t%d", count.Name, count.Count))
 	}
 	w.Flush()
-	analyzeLogs(logsURLs)
-}
-
-type failureCount struct {
-	Name  string
-	Count int
-}
-
-func sortMapByValue(m map[string]int) []failureCount {
-	var failureCounts []failureCount
-	for name, count := range m {
-		failureCounts = append(failureCounts, failureCount{Name: name, Count: count})
+	ok, asked, err := confirmLogDownload(ctx, details.jobLogs, maxLogSize, yes)
+	if err != nil {
+		return err
 	}
-	slices.SortFunc(failureCounts, func(a, b failureCount) int {
-		return b.Count - a.Count
-	})
-	return failureCounts
-}
-func analyzeLogs(logsURLs []*url.URL) {
-	failedTestCount := make(map[string]int)
-	var errors []string
-	tasks := make(chan string)
-	wg := sync.WaitGroup{}
-	mux := sync.Mutex{}
-	var errorURLs []string
-
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			for logsURL := range tasks {
-				resp, err := http.Get(logsURL)
-				if err != nil {
-					slog.Error("Failed to get logs", slog.String("url", logsURL), slog.Any("error", err))
-					continue
-				}
-				body, err := io.ReadAll(resp.Body)
-				if err != nil {
-					if err != nil {
-						slog.Error("Failed to read response body", slog.String("url", logsURL), slog.Any("error", err))
-						continue
-					}
-				}
-				r := regexp.MustCompile(`Test \[(.*)]:`)
-				matches := r.FindAllStringSubmatch(string(body), 10000)
-				mux.Lock()
-				for _, match := range matches {
-					if len(match) == 2 {
-						count, ok := failedTestCount[match[1]]
-						if ok {
-							failedTestCount[match[1]] = count + 1
-						} else {
-							failedTestCount[match[1]] = 1
-						}
-						if match[1] == "check-log-errors" {
-							errorURLs = append(errorURLs, logsURL)
-
-						}
-					}
-				}
-				r = regexp.MustCompile(` level=error.*`)
-				matches = r.FindAllStringSubmatch(string(body), 10000)
-				for _, match := range matches {
-					for _, errorMessage := range match {
-						errors = append(errors, errorMessage)
-					}
-				}
-				mux.Unlock()
-			}
-			wg.Done()
-		}()
+	if !ok {
+		slog.Info("Skipping log analysis")
+		return nil
 	}
-	for _, logsURL := range logsURLs {
-		tasks <- logsURL.String()
+	if asked {
+		resolveLogURLs(ctx, client, owner, repo, details.jobLogs)
 	}
-	close(tasks)
-	wg.Wait()
-	failedTests := sortMapByValue(failedTestCount)
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
-	red := color.New(color.FgRed, color.Bold)
-	red.Println("\nfailed tests")
-	fmt.Fprintln(w, "test name\tfailure count")
-	for _, count := range failedTests {
-		fmt.Fprintln(w, fmt.Sprintf("%s\t%d", count.Name, count.Count))
+	analysis := analyzeLogs(ctx, details.jobLogs, logContext)
+	printLogAnalysis(analysis, known, baseline)
+	if logContext > 0 {
+		printLogExcerpts(analysis)
 	}
-	w.Flush()
-	errorLogCount := make(map[string]int)
-	for _, errorMessage := range errors {
-		r := regexp.MustCompile(`msg="([^"]+)"`)
-		matches := r.FindStringSubmatch(errorMessage)
-		if len(matches) == 2 {
-			count, ok := errorLogCount[matches[1]]
-			if ok {
-				errorLogCount[matches[1]] = count + 1
-			} else {
-				errorLogCount[matches[1]] = 1
-			}
+	if quarantineFile != "" {
+		if err := writeQuarantineSuggestions(quarantineFile, analysis.failedTestRuns, runs, quarantinePrune, details.incomplete || analysis.incomplete); err != nil {
+			slog.Error("Failed to write quarantine suggestions", slog.String("file", quarantineFile), slog.Any("error", err))
 		}
 	}
-	errorLogs := sortMapByValue(errorLogCount)
-	red.Println("\nerror logs")
-	fmt.Fprintln(w, "error message\tcount")
-	for _, count := range errorLogs {
-		fmt.Fprintln(w, fmt.Sprintf("%s\t%d", count.Name, count.Count))
-	}
-	w.Flush()
-	for _, errorLogsURL := range errorURLs {
-		slog.Debug("Jobs log URL with check-log-errors test failure", slog.String("logs-url", errorLogsURL))
-	}
+	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(showCmd)
 
 	showCmd.Flags().StringP("branch", "b", "main", "Branch name")
-	showCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows")
+	showCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
 	showCmd.Flags().BoolP("debug", "d", false, "Print debug logs")
 	showCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	showCmd.Flags().Float32("warn-below", warnBelow, "Show workflows with a success rate below this percentage in yellow")
+	showCmd.Flags().Float32("critical-below", criticalBelow, "Show workflows with a success rate below this percentage in red")
+	showCmd.Flags().Bool("all", false, "Process every workflow run within --days instead of --number runs, for accurate monthly or quarterly reports")
 	showCmd.Flags().StringP("workflow", "w", "", "Workflow name (e.g. aks-byocni.yaml)")
 	showCmd.Flags().BoolP("summary", "s", false, "Print summary")
 	showCmd.Flags().IntP("top", "t", 10, "Print top n. Use with --summary flag")
+	showCmd.Flags().Float32("below", 0, "Only include workflows with a success rate below this percentage in the summary, all of them unless --top is set")
+	showCmd.Flags().Duration("slower-than", 0, "Only include workflows with an average duration longer than this in the summary (e.g. 1h), all of them unless --top is set")
+	showCmd.Flags().Duration("half-life", 0, "Weight the success rates in the summary by recency, halving the weight of runs with every this much of their age (e.g. 168h)")
+	showCmd.Flags().String("sort", "", fmt.Sprintf("Print a single summary table sorted by this key (%s). Use with --summary flag", strings.Join(summarySortKeys, ", ")))
+	showCmd.Flags().StringSlice("columns", nil, fmt.Sprintf("Print a single summary table with these columns (%s). Use with --summary flag", strings.Join(summaryColumnNames, ", ")))
+	showCmd.Flags().Bool("failure-durations", false, "Also print the average duration of unsuccessful runs in the dashboard and the summary. Only failed runs are unsuccessful unless more conclusions such as cancelled are counted with --include-conclusions")
+	showCmd.Flags().String("group-by", "", fmt.Sprintf("Print the success rate and the average duration of each workflow per period (%s). Use with --summary flag", strings.Join(summaryGroupings, ", ")))
+	showCmd.Flags().Duration("correlation-window", time.Hour, "Time window for detecting failures across many workflows. Use with --summary flag")
+	showCmd.Flags().Int("correlation-min-workflows", 5, "Minimum number of workflows failing within --correlation-window to report a correlated failure event")
+	showCmd.Flags().Bool("scorecard", false, "Print repository-level success rate, p90 duration, mean time to recovery, and failed run minutes")
 	showCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	showCmd.Flags().Float32("fail-under", 0, "Exit with a non-zero code if any workflow's success rate is below this percentage")
+	showCmd.Flags().Duration("fail-if-slower-than", 0, "Exit with a non-zero code if any workflow's average duration is longer than this")
+	showCmd.Flags().String("api", "rest", "API used to fetch workflow runs (rest, graphql). The graphql API fetches multiple workflows per request and filters the runs on the client")
+	showCmd.Flags().String("required-checks", "", "Highlight the failing and flaky status checks required to merge into this branch (e.g. main). Use with --summary flag")
+	showCmd.Flags().Bool("actors", false, "Print the top n users and bots by CI time consumed, with their run counts and failure rates")
+	showCmd.Flags().StringSlice("branches", nil, "Print a matrix of the success rates of each workflow on each of these branches (e.g. main,v1.16,v1.15). Use --event auto to select the event for each branch")
+	showCmd.Flags().Bool("stale-workflows", false, "List workflows without runs other than cancelled ones in --days on any branch, as candidates for removal")
+	showCmd.Flags().Bool("histogram", false, "Print a histogram of the durations of the successful runs of each workflow")
+	showCmd.Flags().Float64("anomalies", 0, fmt.Sprintf("Print days whose success rate deviates from the mean of the preceding %d days with runs by at least this many standard deviations (e.g. 2). 0 disables the check", anomalyWindow))
+	showCmd.Flags().Float64("timeout-ratio", 0, "Print jobs whose p90 duration is at least this ratio of their timeout-minutes (e.g. 0.8). 0 disables the check")
+	showCmd.Flags().Bool("step-categories", false, "Print the share of step time spent in checkout, setup, build, test, and teardown per workflow")
+	showCmd.Flags().Bool("top-tests", false, "Analyze the logs of the failed runs of all the workflows, and print the top n failing tests with the workflows they failed in")
+	showCmd.Flags().Bool("by-team", false, "Show the health of the workflows each team owns, based on the owners in the config and CODEOWNERS")
+	showCmd.Flags().String("provider", "github", "CI system to fetch runs from (github, gitlab). Only pipeline-level stats are supported for GitLab, without jobs or logs. GitLab pipelines are read with the GITLAB_TOKEN environment variable or the token stored with auth login, and owner/repo is the project path")
+	showCmd.Flags().String("gitlab-url", "https://gitlab.com", "Base URL of the GitLab instance")
+	showCmd.Flags().StringP("output", "o", "text", "Output format (text, html-bundle)")
+	showCmd.Flags().Bool("all-repos", false, "Show the dashboard for all the repositories in the organization given as the only argument")
+	showCmd.Flags().Int("max-logs", 0, "Analyze at most this many job logs for --top-tests, starting with the workflows with the lowest success rates. 0 means no limit")
+	showCmd.Flags().Int64("max-log-size", 500, "Ask for confirmation before downloading job logs larger than this many megabytes in total. Use with --workflow flag")
+	showCmd.Flags().BoolP("yes", "y", false, "Download job logs without confirmation")
+	showCmd.Flags().String("duration-method", "updated", fmt.Sprintf("How to measure the duration of runs (%s). updated overestimates runs that were re-run or updated later", strings.Join(durationMethods, ", ")))
+	showCmd.Flags().StringSlice("include-conclusions", nil, "Also count runs with these conclusions as failures (cancelled, timed_out, action_required, skipped)")
+	showCmd.Flags().StringSlice("include-repos", nil, "Only include repositories matching these glob patterns. Use with --all-repos flag")
+	showCmd.Flags().StringSlice("exclude-repos", nil, "Exclude repositories matching these glob patterns. Use with --all-repos flag")
+	showCmd.Flags().Bool("retries", false, "Print re-run statistics")
+	showCmd.Flags().Bool("time-to-failure", false, "Print how long failed runs take until the first job fails")
+	showCmd.Flags().Bool("queue-time", false, "Print how long runs and jobs waited for a runner")
+	showCmd.Flags().Bool("matrix", false, "Print failure rates of matrix jobs per matrix value")
+	showCmd.Flags().String("step-retries", "", "Print how often retries were exercised in steps whose names match this regex (e.g. '(?i)retry')")
+	showCmd.Flags().String("group-by-metadata", "", "Print success rates grouped by the value of this metadata key in the configuration file")
+	showCmd.Flags().String("failure-artifacts", "", "List the artifacts of failed runs whose names match this regex next to the failed jobs, with download links (e.g. 'sysdump'). Use with --workflow flag")
+	showCmd.Flags().Bool("fixes", false, "Print pull requests merged between a failure and the following recovery. Use with --workflow flag")
+	showCmd.Flags().String("quarantine-output", "", "Write quarantine suggestions for flaky tests with their failure rates to this JSON or YAML file (.yaml, .yml). Use with --workflow flag")
+	showCmd.Flags().String("baseline", "", "Mark failed tests and error messages that are not in this snapshot, saved with snapshot save --errors, as appearing for the first time. Use with --workflow flag")
+	showCmd.Flags().Int("log-context", 0, "Print an excerpt of the logs with this many lines of context around each failed test and error message. Use with --workflow flag")
+	showCmd.Flags().Bool("quarantine-prune", false, "Keep the tests of the existing --quarantine-output file that still fail, and remove the tests that recovered")
 }
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// slo is a service level objective for the success rate of a workflow.
+type slo struct {
+	Workflow string `yaml:"workflow"`
+	// SuccessRate is the target success rate in percent.
+	SuccessRate float32 `yaml:"successRate"`
+	// Days is the window the success rate is computed over. Defaults to 30.
+	Days int `yaml:"days"`
+}
+
+type sloStatus struct {
+	slo         slo
+	success     int
+	count       int
+	successRate float32
+	// budgetRemaining is the percentage of the error budget that is not consumed yet.
+	budgetRemaining float32
+	// burnRate is the ratio of the actual failure rate to the failure rate allowed by the
+	// SLO. The budget runs out at the end of the window if the burn rate is 1.
+	burnRate float32
+}
+
+func (s sloStatus) violated() bool {
+	return s.count > 0 && s.successRate < s.slo.SuccessRate
+}
+
+func getSLOStatus(o slo, success, count int) sloStatus {
+	status := sloStatus{slo: o, success: success, count: count, budgetRemaining: 100}
+	if count == 0 {
+		return status
+	}
+	failures := float32(count - success)
+	allowedFailureRate := (100 - o.SuccessRate) / 100
+	status.successRate = 100 * float32(success) / float32(count)
+	if allowedFailureRate > 0 {
+		status.burnRate = failures / float32(count) / allowedFailureRate
+		status.budgetRemaining = 100 * (1 - failures/(allowedFailureRate*float32(count)))
+	} else if failures > 0 {
+		status.budgetRemaining = -100 * failures
+	}
+	return status
+}
+
+var sloCmd = &cobra.Command{
+	Use:   "slo owner repo",
+	Short: "Report compliance with the SLOs in the configuration file",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if len(cfg.SLOs) == 0 {
+			return fmt.Errorf("no SLOs defined in the configuration file")
+		}
+		green := color.New(color.FgGreen).SprintFunc()
+		red := color.New(color.FgRed).SprintFunc()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintln(w, "status\tsuccess rate\ttarget\tbudget remaining\tburn rate\tworkflow")
+		violated := false
+		for _, o := range cfg.SLOs {
+			if o.Days == 0 {
+				o.Days = 30
+			}
+			runs, err := getWorkflowRuns(ctx, client, owner, repo, branch, o.Workflow, event, numRuns, daysToTimeRange(o.Days))
+			if err != nil {
+				return err
+			}
+			success := 0
+			for _, run := range runs {
+				if run.GetConclusion() == "success" {
+					success++
+				}
+			}
+			status := getSLOStatus(o, success, len(runs))
+			result := green("OK")
+			if status.count == 0 {
+				result = "N/A"
+			} else if status.violated() {
+				if cfg.getKnownIssue(o.Workflow) != nil {
+					result = cfg.knownIssueLabel(o.Workflow)
+				} else {
+					result = red("VIOLATED")
+					violated = true
+				}
+			}
+			fmt.Fprintln(w, fmt.Sprintf("%s\t%0.f%% %d/%d\t%g%% over %dd\t%0.f%%\t%.2f\t%s",
+				result, status.successRate, status.success, status.count, o.SuccessRate, o.Days,
+				status.budgetRemaining, status.burnRate, o.Workflow))
+		}
+		w.Flush()
+		if violated {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sloCmd)
+
+	sloCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	sloCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	sloCmd.Flags().IntP("number", "n", 1000, "The maximum number of workflow runs to process per workflow")
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save and compare snapshots of workflow stats",
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save owner repo file",
+	Short: "Save a snapshot of workflow stats to a file",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 3 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		withErrors, err := cmd.Flags().GetBool("errors")
+		if err != nil {
+			return err
+		}
+		workflows, err := dashboard.GetWorkflows(ctx, client.Actions, owner, repo)
+		if err != nil {
+			return err
+		}
+		result := getWorkflowRunsForWorkflows(ctx, client, owner, repo, branch, workflows, event, numRuns, daysToTimeRange(days))
+		r := newReport(owner, repo, branch, event, result)
+		if withErrors {
+			var jobLogs []jobLog
+			for _, runs := range result {
+				jobLogs = append(jobLogs, getFailureDetails(ctx, client, owner, repo, runs).jobLogs...)
+			}
+			analysis := analyzeLogs(ctx, jobLogs, 0)
+			r.ErrorClusters = analysis.errorLogCount
+			r.FailedTests = analysis.failedTestCount
+		}
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(args[2], append(data, '\n'), 0644)
+	},
+}
+
+var snapshotDiffCmd = &cobra.Command{
+	Use:   "diff old-file new-file",
+	Short: "Show the change in workflow stats between two snapshots",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		before, err := readSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+		after, err := readSnapshot(args[1])
+		if err != nil {
+			return err
+		}
+		printSnapshotDiff(before, after)
+		printRisingErrors(before, after)
+		return nil
+	},
+}
+
+func readSnapshot(filename string) (report, error) {
+	var r report
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	// Snapshots saved before versioning have no schema version.
+	if r.SchemaVersion > reportSchemaVersion {
+		return r, fmt.Errorf("%s has schema version %d, newer than the supported version %d", filename, r.SchemaVersion, reportSchemaVersion)
+	}
+	return r, nil
+}
+
+func printSnapshotDiff(before, after report) {
+	beforeWorkflows := map[string]reportWorkflow{}
+	for _, w := range before.Workflows {
+		beforeWorkflows[w.Workflow] = w
+	}
+	type workflowDiff struct {
+		workflow string
+		before   *reportWorkflow
+		after    reportWorkflow
+		rate     float32
+		duration time.Duration
+	}
+	var diffs []workflowDiff
+	for _, w := range after.Workflows {
+		if w.Count == 0 {
+			continue
+		}
+		diff := workflowDiff{workflow: w.Workflow, after: w}
+		if b, ok := beforeWorkflows[w.Workflow]; ok && b.Count > 0 {
+			diff.before = &b
+			diff.rate = w.Rate - b.Rate
+			diff.duration = time.Duration(w.AverageDuration-b.AverageDuration) * time.Second
+		}
+		diffs = append(diffs, diff)
+	}
+	slices.SortFunc(diffs, func(a, b workflowDiff) int {
+		return cmp.Or(cmp.Compare(a.rate, b.rate), cmp.Compare(a.workflow, b.workflow))
+	})
+	fmt.Printf("%s -> %s\n", formatTime(before.GeneratedAt), formatTime(after.GeneratedAt))
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "success rate\tchange\taverage duration\tchange\tworkflow")
+	for _, diff := range diffs {
+		rate := fmt.Sprintf("%0.f%%", diff.after.Rate)
+		duration := (time.Duration(diff.after.AverageDuration) * time.Second).String()
+		rateChange, durationChange := "new", "new"
+		if diff.before != nil {
+			rate = fmt.Sprintf("%0.f%% -> %0.f%%", diff.before.Rate, diff.after.Rate)
+			duration = fmt.Sprintf("%s -> %s",
+				time.Duration(diff.before.AverageDuration)*time.Second, time.Duration(diff.after.AverageDuration)*time.Second)
+			rateChange = fmt.Sprintf("%+.0f%%", diff.rate)
+			if diff.rate > 0 {
+				rateChange = green(rateChange)
+			} else if diff.rate < 0 {
+				rateChange = red(rateChange)
+			}
+			durationChange = diff.duration.String()
+			if diff.duration > 0 {
+				durationChange = red("+" + durationChange)
+			} else if diff.duration < 0 {
+				durationChange = green(durationChange)
+			}
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%s\t%s", rate, rateChange, duration, durationChange, diff.workflow))
+	}
+	w.Flush()
+}
+
+// printRisingErrors prints error messages that occurred more often in the newer snapshot,
+// ordered by the increase rather than by volume, so that emerging issues stand out from
+// long-standing noise.
+func printRisingErrors(before, after report) {
+	if after.ErrorClusters == nil {
+		return
+	}
+	// Without the errors of the older snapshot, every error would be reported as new.
+	if before.ErrorClusters == nil {
+		slog.Warn("Not comparing errors, since the older snapshot was saved without --errors")
+		return
+	}
+	type errorDiff struct {
+		message string
+		before  int
+		after   int
+	}
+	var diffs []errorDiff
+	for message, count := range after.ErrorClusters {
+		if count > before.ErrorClusters[message] {
+			diffs = append(diffs, errorDiff{message: message, before: before.ErrorClusters[message], after: count})
+		}
+	}
+	slices.SortFunc(diffs, func(a, b errorDiff) int {
+		return cmp.Or(cmp.Compare(b.after-b.before, a.after-a.before), cmp.Compare(a.message, b.message))
+	})
+	color.New(color.FgRed, color.Bold).Println("\nrising errors")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "count\tchange\terror message")
+	for _, diff := range diffs {
+		change := fmt.Sprintf("+%d", diff.after-diff.before)
+		if diff.before == 0 {
+			change = "new"
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%d -> %d\t%s\t%s", diff.before, diff.after, change, diff.message))
+	}
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotSaveCmd)
+	snapshotCmd.AddCommand(snapshotDiffCmd)
+
+	snapshotSaveCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	snapshotSaveCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	snapshotSaveCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	snapshotSaveCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	snapshotSaveCmd.Flags().Bool("errors", false, "Also count error messages and failed tests in the logs of failed jobs, to report rising errors with snapshot diff and new failures with show --baseline")
+}
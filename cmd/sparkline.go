@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// sparkBlocks are the levels of a sparkline from lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// asciiSparkBlocks are the levels of a sparkline with --ascii.
+var asciiSparkBlocks = []rune("_.-~=+*#")
+
+// sparklineWidth is the maximum number of points in a sparkline.
+const sparklineWidth = 16
+
+// sparkline renders the values scaled between lo and hi as a unicode sparkline.
+func sparkline(values []float64, lo, hi float64) string {
+	blocks := sparkBlocks
+	if asciiOutput {
+		blocks = asciiSparkBlocks
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(blocks)-1))
+		}
+		sb.WriteRune(blocks[max(0, min(i, len(blocks)-1))])
+	}
+	return sb.String()
+}
+
+// successRateSparkline splits the runs into chronological buckets, and renders the success
+// rate of each bucket from oldest to newest. Runs are expected to be sorted from newest to
+// oldest.
+func successRateSparkline(runs []*github.WorkflowRun) string {
+	if len(runs) == 0 {
+		return ""
+	}
+	buckets := min(len(runs), sparklineWidth)
+	var rates []float64
+	for i := buckets - 1; i >= 0; i-- {
+		bucket := runs[i*len(runs)/buckets : (i+1)*len(runs)/buckets]
+		rates = append(rates, float64(dashboard.SuccessRate(bucket)))
+	}
+	return sparkline(rates, 0, 100)
+}
+
+// durationSparkline renders the durations of the latest successful runs from oldest to
+// newest, scaled between the shortest and the longest.
+func durationSparkline(runs []*github.WorkflowRun) string {
+	var durations []float64
+	for _, run := range runs {
+		if run.GetConclusion() == "success" {
+			durations = append(durations, runDurations.Of(run).Seconds())
+		}
+		if len(durations) == sparklineWidth {
+			break
+		}
+	}
+	if len(durations) == 0 {
+		return ""
+	}
+	slices.Reverse(durations)
+	return sparkline(durations, slices.Min(durations), slices.Max(durations))
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+)
+
+// staleWorkflow is a workflow with no runs other than cancelled ones in the window, on
+// any branch and for any event.
+type staleWorkflow struct {
+	workflow  string
+	cancelled int
+}
+
+// getStaleWorkflows checks the workflows without counted runs in the result for runs on
+// any branch and for any event, and returns those that had none or only cancelled ones.
+func getStaleWorkflows(ctx context.Context, client *github.Client, owner, repo string, result map[string][]*github.WorkflowRun, created string) []staleWorkflow {
+	var stale []staleWorkflow
+	tasks := make(chan string)
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for workflow := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				listOptions := github.ListWorkflowRunsOptions{Status: "completed", Created: created, ListOptions: github.ListOptions{PerPage: 100}}
+				cancelled := 0
+				active := false
+				var err error
+				for !active {
+					var runs *github.WorkflowRuns
+					var res *github.Response
+					runs, res, err = client.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, workflow, &listOptions)
+					if err != nil {
+						slog.Error("Failed to get workflow runs", slog.String("workflow", workflow), slog.Any("error", err))
+						break
+					}
+					for _, run := range runs.WorkflowRuns {
+						if run.GetConclusion() != "cancelled" {
+							active = true
+							break
+						}
+						cancelled++
+					}
+					if res.NextPage == 0 {
+						break
+					}
+					listOptions.Page = res.NextPage
+				}
+				if active || err != nil {
+					continue
+				}
+				mux.Lock()
+				stale = append(stale, staleWorkflow{workflow: workflow, cancelled: cancelled})
+				mux.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	for workflow, runs := range result {
+		if len(runs) == 0 {
+			tasks <- workflow
+		}
+	}
+	close(tasks)
+	wg.Wait()
+	slices.SortFunc(stale, func(a, b staleWorkflow) int {
+		return cmp.Compare(a.workflow, b.workflow)
+	})
+	return stale
+}
+
+// printStaleWorkflowSuggestions prints workflows that can probably be removed, with links to their
+// runs and files.
+func printStaleWorkflowSuggestions(owner, repo, branch string, stale []staleWorkflow, days int) {
+	if len(stale) == 0 {
+		return
+	}
+	color.New(color.FgYellow, color.Bold).Printf("\nworkflows without completed runs in the last %d days, candidates for removal\n", days)
+	link := color.New(color.FgCyan).SprintFunc()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "workflow\truns\tfile")
+	for _, s := range stale {
+		runs := "none"
+		if s.cancelled > 0 {
+			runs = fmt.Sprintf("%d cancelled", s.cancelled)
+		}
+		workflowURL := fmt.Sprintf("https://github.com/%s/%s/actions/workflows/%s", owner, repo, s.workflow)
+		fileURL := fmt.Sprintf("https://github.com/%s/%s/blob/%s/.github/workflows/%s", owner, repo, branch, s.workflow)
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s", link(getLink(workflowURL, s.workflow)), runs, link(fileURL)))
+	}
+	w.Flush()
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// retryAttemptRegexp matches log lines of retry wrappers like nick-invision/retry when an
+// attempt fails.
+var retryAttemptRegexp = regexp.MustCompile(`Attempt \d+ failed`)
+
+type stepRetryStats struct {
+	step string
+	// jobs is the number of jobs that ran the step.
+	jobs int
+	// retried is the number of jobs where the step needed more than one attempt.
+	retried int
+	// attempts is the total number of failed attempts.
+	attempts int
+}
+
+// stepHeaderRegexp matches the timestamped "##[group]Run" log lines that start the sections
+// of the steps in a job log.
+var stepHeaderRegexp = regexp.MustCompile(`^(\S+) ##\[group\]Run `)
+
+// countStepAttempts counts the failed attempts in the log of a job for each of its steps,
+// keyed by step number. The log is split in sections at the step headers, and each section
+// is attributed to the step that started last before its header, so that retries of other
+// steps in the same job are not counted.
+func countStepAttempts(logs []byte, steps []*github.TaskStep) map[int64]int {
+	attempts := map[int64]int{}
+	var step *github.TaskStep
+	for _, line := range bytes.Split(logs, []byte("\n")) {
+		if match := stepHeaderRegexp.FindSubmatch(line); match != nil {
+			if started, err := time.Parse(time.RFC3339Nano, string(match[1])); err == nil {
+				step = stepStartedBefore(steps, started)
+			}
+		}
+		if step != nil && retryAttemptRegexp.Match(line) {
+			attempts[step.GetNumber()]++
+		}
+	}
+	return attempts
+}
+
+// stepStartedBefore returns the step that started last at or before t, or nil if none did.
+// Step timestamps only have a precision of seconds.
+func stepStartedBefore(steps []*github.TaskStep, t time.Time) *github.TaskStep {
+	var result *github.TaskStep
+	for _, step := range steps {
+		if step.StartedAt == nil || step.GetStartedAt().After(t) {
+			continue
+		}
+		if result == nil || !step.GetStartedAt().Before(result.GetStartedAt().Time) {
+			result = step
+		}
+	}
+	return result
+}
+
+// getStepRetryStats finds steps whose names match stepRegexp in the jobs of the runs, and
+// counts failed attempts in the sections of the job logs that belong to those steps.
+func getStepRetryStats(ctx context.Context, client *github.Client, owner, repo string, runs []*github.WorkflowRun, stepRegexp *regexp.Regexp) []stepRetryStats {
+	type task struct {
+		job   *github.WorkflowJob
+		steps []*github.TaskStep
+	}
+	statsMap := map[string]*stepRetryStats{}
+	var tasks []task
+	for _, jobs := range dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, runs, numWorkers) {
+		for _, job := range jobs {
+			var matched []*github.TaskStep
+			for _, step := range job.Steps {
+				if !stepRegexp.MatchString(step.GetName()) || step.GetConclusion() == "skipped" {
+					continue
+				}
+				if _, ok := statsMap[step.GetName()]; !ok {
+					statsMap[step.GetName()] = &stepRetryStats{step: step.GetName()}
+				}
+				statsMap[step.GetName()].jobs++
+				matched = append(matched, step)
+			}
+			if len(matched) > 0 {
+				tasks = append(tasks, task{job: job, steps: matched})
+			}
+		}
+	}
+	taskCh := make(chan task)
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for t := range taskCh {
+				logs, err := getJobLogs(ctx, client, owner, repo, t.job.GetID())
+				if err != nil {
+					slog.Error("Failed to get job logs", slog.Int64("job-id", t.job.GetID()), slog.Any("error", err))
+					continue
+				}
+				attempts := countStepAttempts(logs, t.job.Steps)
+				mux.Lock()
+				for _, step := range t.steps {
+					if attempts[step.GetNumber()] > 0 {
+						statsMap[step.GetName()].retried++
+						statsMap[step.GetName()].attempts += attempts[step.GetNumber()]
+					}
+				}
+				mux.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	for _, t := range tasks {
+		taskCh <- t
+	}
+	close(taskCh)
+	wg.Wait()
+	var statsList []stepRetryStats
+	for _, stats := range statsMap {
+		statsList = append(statsList, *stats)
+	}
+	slices.SortFunc(statsList, func(a, b stepRetryStats) int {
+		return b.retried - a.retried
+	})
+	return statsList
+}
+
+func printStepRetries(ctx context.Context, client *github.Client, owner, repo string, result map[string][]*github.WorkflowRun, stepRegexp *regexp.Regexp) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	color.New(color.FgYellow, color.Bold).Println("\nstep retries")
+	fmt.Fprintln(w, "retried jobs\tfailed attempts\tstep name\tworkflow")
+	for _, workflow := range sortWorkflowsBySuccessRate(result) {
+		for _, stats := range getStepRetryStats(ctx, client, owner, repo, result[workflow], stepRegexp) {
+			fmt.Fprintln(w, fmt.Sprintf("%d/%d\t%d\t%s\t%s", stats.retried, stats.jobs, stats.attempts, stats.step, workflow))
+		}
+	}
+	w.Flush()
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+)
+
+func TestCountStepAttempts(t *testing.T) {
+	started := func(s string) *github.Timestamp {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &github.Timestamp{Time: ts}
+	}
+	steps := []*github.TaskStep{
+		{Number: github.Int64(1), Name: github.String("Set up job"), StartedAt: started("2024-03-01T10:00:00Z")},
+		{Number: github.Int64(2), Name: github.String("Install"), StartedAt: started("2024-03-01T10:00:05Z")},
+		{Number: github.Int64(3), Name: github.String("Test"), StartedAt: started("2024-03-01T10:01:00Z")},
+	}
+	logs := []byte(`2024-03-01T10:00:01.0000000Z Attempt 1 failed, not in a step section
+2024-03-01T10:00:05.5000000Z ##[group]Run nick-invision/retry@v2
+2024-03-01T10:00:05.6000000Z ##[endgroup]
+2024-03-01T10:00:10.0000000Z Attempt 1 failed
+2024-03-01T10:01:00.2000000Z ##[group]Run nick-invision/retry@v2
+2024-03-01T10:01:00.3000000Z ##[endgroup]
+2024-03-01T10:01:10.0000000Z Attempt 1 failed
+2024-03-01T10:01:20.0000000Z Attempt 2 failed
+`)
+	got := countStepAttempts(logs, steps)
+	want := map[int64]int{2: 1, 3: 2}
+	if !maps.Equal(got, want) {
+		t.Errorf("countStepAttempts() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,482 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// successCriteria is set by SetSuccessCriteria or the --success-expr flag, and applied to
+// the runs fetched by getWorkflowRuns.
+var successCriteria dashboard.SuccessCriteria
+
+// SetSuccessCriteria sets the criteria for runs to count as a success in all the commands.
+// Programs embedding the dashboard can encode their own policies with it before calling
+// Execute.
+func SetSuccessCriteria(c dashboard.SuccessCriteria) {
+	successCriteria = c
+}
+
+// successExpr is a success criteria expression such as
+//
+//	conclusion == "success" || onlyFailed("flaky")
+//
+// Expressions support &&, ||, !, parentheses, the comparison operators ==, !=, <, <=, >,
+// >=, and =~ for regular expressions, string and number literals, and these variables
+// and functions:
+//
+//	conclusion, event, branch, actor  the fields of the run
+//	attempt                           the run attempt number
+//	failedJobs                        the number of failed jobs
+//	onlyFailed("regex")               all the failed jobs match the regex
+//	anyFailed("regex")                any of the failed jobs matches the regex
+type successExpr struct {
+	root exprNode
+}
+
+func parseSuccessExpr(s string) (*successExpr, error) {
+	tokens, err := tokenizeExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	// Type errors are reported here, since they would otherwise make every run fail.
+	typ, err := root.typ()
+	if err != nil {
+		return nil, err
+	}
+	if typ != "bool" {
+		return nil, fmt.Errorf("expression is a %s, not a bool", typ)
+	}
+	return &successExpr{root: root}, nil
+}
+
+// Success evaluates the expression for the run. Expressions are type-checked when they are
+// parsed, so evaluation doesn't fail.
+func (e *successExpr) Success(run *github.WorkflowRun, jobs []*github.WorkflowJob) bool {
+	v, err := e.root.eval(exprEnv{run: run, jobs: jobs})
+	if err != nil {
+		return false
+	}
+	b, ok := v.(bool)
+	return ok && b
+}
+
+type exprEnv struct {
+	run  *github.WorkflowRun
+	jobs []*github.WorkflowJob
+}
+
+func (env exprEnv) failedJobs() []string {
+	var names []string
+	for _, job := range env.jobs {
+		if job.GetConclusion() == "failure" {
+			names = append(names, job.GetName())
+		}
+	}
+	return names
+}
+
+type exprToken struct {
+	kind string // "ident", "string", "number", or the operator itself
+	text string
+}
+
+func tokenizeExpr(s string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, exprToken{kind: "string", text: s[i+1 : i+1+end]})
+			i += end + 2
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(s) && (unicode.IsDigit(rune(s[j])) || s[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, exprToken{kind: "number", text: s[i:j]})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(s) && (unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j])) || s[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, exprToken{kind: "ident", text: s[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "<", ">", "!", "(", ")", ","} {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			tokens = append(tokens, exprToken{kind: op, text: op})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) accept(kind string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == kind {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "=~", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			if op == "=~" {
+				lit, ok := right.(literalNode)
+				pattern, isString := lit.value.(string)
+				if !ok || !isString {
+					return nil, fmt.Errorf("=~ requires a string literal")
+				}
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return nil, err
+				}
+				return matchNode{value: left, re: re}, nil
+			}
+			return binaryNode{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	if p.accept("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		return node, nil
+	}
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case "string":
+		return literalNode{value: t.text}, nil
+	case "number":
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, err
+		}
+		return literalNode{value: n}, nil
+	case "ident":
+		if !p.accept("(") {
+			switch t.text {
+			case "conclusion", "event", "branch", "actor", "attempt", "failedJobs":
+				return variableNode{name: t.text}, nil
+			case "true", "false":
+				return literalNode{value: t.text == "true"}, nil
+			}
+			return nil, fmt.Errorf("unknown variable %q", t.text)
+		}
+		if t.text != "onlyFailed" && t.text != "anyFailed" {
+			return nil, fmt.Errorf("unknown function %q", t.text)
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != "string" {
+			return nil, fmt.Errorf("%s requires a string argument", t.text)
+		}
+		re, err := regexp.Compile(p.tokens[p.pos].text)
+		if err != nil {
+			return nil, err
+		}
+		p.pos++
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing ) after %s argument", t.text)
+		}
+		return failedJobsNode{all: t.text == "onlyFailed", re: re}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+type exprNode interface {
+	eval(env exprEnv) (any, error)
+	// typ returns the type of the node's value, one of bool, string, and number, or an
+	// error if the operands have the wrong types.
+	typ() (string, error)
+}
+
+type literalNode struct{ value any }
+
+func (n literalNode) eval(exprEnv) (any, error) { return n.value, nil }
+
+func (n literalNode) typ() (string, error) {
+	switch n.value.(type) {
+	case bool:
+		return "bool", nil
+	case string:
+		return "string", nil
+	default:
+		return "number", nil
+	}
+}
+
+type variableNode struct{ name string }
+
+func (n variableNode) eval(env exprEnv) (any, error) {
+	switch n.name {
+	case "conclusion":
+		return env.run.GetConclusion(), nil
+	case "event":
+		return env.run.GetEvent(), nil
+	case "branch":
+		return env.run.GetHeadBranch(), nil
+	case "actor":
+		return env.run.GetActor().GetLogin(), nil
+	case "attempt":
+		return float64(env.run.GetRunAttempt()), nil
+	default:
+		return float64(len(env.failedJobs())), nil
+	}
+}
+
+func (n variableNode) typ() (string, error) {
+	if n.name == "attempt" || n.name == "failedJobs" {
+		return "number", nil
+	}
+	return "string", nil
+}
+
+type notNode struct{ operand exprNode }
+
+func (n notNode) typ() (string, error) {
+	if err := expectType(n.operand, "bool", "!"); err != nil {
+		return "", err
+	}
+	return "bool", nil
+}
+
+// expectType returns an error if the node's value is not of the type the operator
+// requires.
+func expectType(node exprNode, want, op string) error {
+	typ, err := node.typ()
+	if err != nil {
+		return err
+	}
+	if typ != want {
+		return fmt.Errorf("%s requires a %s, got a %s", op, want, typ)
+	}
+	return nil
+}
+
+func (n notNode) eval(env exprEnv) (any, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("! requires a boolean")
+	}
+	return !b, nil
+}
+
+type matchNode struct {
+	value exprNode
+	re    *regexp.Regexp
+}
+
+func (n matchNode) eval(env exprEnv) (any, error) {
+	v, err := n.value.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("=~ requires a string")
+	}
+	return n.re.MatchString(s), nil
+}
+
+func (n matchNode) typ() (string, error) {
+	if err := expectType(n.value, "string", "=~"); err != nil {
+		return "", err
+	}
+	return "bool", nil
+}
+
+// failedJobsNode implements onlyFailed and anyFailed.
+type failedJobsNode struct {
+	all bool
+	re  *regexp.Regexp
+}
+
+func (n failedJobsNode) eval(env exprEnv) (any, error) {
+	failed := env.failedJobs()
+	for _, name := range failed {
+		if n.re.MatchString(name) != n.all {
+			return !n.all, nil
+		}
+	}
+	return n.all && len(failed) > 0, nil
+}
+
+func (n failedJobsNode) typ() (string, error) { return "bool", nil }
+
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n binaryNode) typ() (string, error) {
+	switch n.op {
+	case "&&", "||":
+		if err := expectType(n.left, "bool", n.op); err != nil {
+			return "", err
+		}
+		if err := expectType(n.right, "bool", n.op); err != nil {
+			return "", err
+		}
+	case "==", "!=":
+		left, err := n.left.typ()
+		if err != nil {
+			return "", err
+		}
+		if err := expectType(n.right, left, n.op); err != nil {
+			return "", err
+		}
+	default:
+		if err := expectType(n.left, "number", n.op); err != nil {
+			return "", err
+		}
+		if err := expectType(n.right, "number", n.op); err != nil {
+			return "", err
+		}
+	}
+	return "bool", nil
+}
+
+func (n binaryNode) eval(env exprEnv) (any, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s requires booleans", n.op)
+		}
+		// Short-circuit evaluation.
+		if l == (n.op == "||") {
+			return l, nil
+		}
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s requires booleans", n.op)
+		}
+		return r, nil
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s requires numbers", n.op)
+	}
+	switch n.op {
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	default:
+		return l >= r, nil
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v59/github"
+)
+
+func TestSuccessExpr(t *testing.T) {
+	run := &github.WorkflowRun{
+		Conclusion: github.String("failure"),
+		Event:      github.String("schedule"),
+		HeadBranch: github.String("main"),
+		Actor:      &github.User{Login: github.String("bot")},
+		RunAttempt: github.Int(2),
+	}
+	jobs := []*github.WorkflowJob{
+		{Name: github.String("flaky-e2e"), Conclusion: github.String("failure")},
+		{Name: github.String("flaky-unit"), Conclusion: github.String("failure")},
+		{Name: github.String("build"), Conclusion: github.String("success")},
+	}
+	for _, tt := range []struct {
+		expr string
+		want bool
+	}{
+		{`conclusion == "success"`, false},
+		{`conclusion != "success"`, true},
+		{`conclusion == "success" || onlyFailed("flaky")`, true},
+		{`onlyFailed("e2e")`, false},
+		{`anyFailed("e2e")`, true},
+		{`anyFailed("build")`, false},
+		{`failedJobs == 2 && attempt >= 2`, true},
+		{`attempt > 2`, false},
+		{`attempt < 2.5`, true},
+		{`branch =~ "^ma" && event == "schedule"`, true},
+		{`actor == "bot" && !(event == "push")`, true},
+		{`!true || false`, false},
+		{`conclusion == "failure" && (attempt <= 1 || failedJobs != 2)`, false},
+	} {
+		expr, err := parseSuccessExpr(tt.expr)
+		if err != nil {
+			t.Errorf("parseSuccessExpr(%s) returned error: %v", tt.expr, err)
+			continue
+		}
+		if got := expr.Success(run, jobs); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestSuccessExprErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`conclusion ==`,
+		`(conclusion == "success"`,
+		`conclusion == "success")`,
+		`conclusion == "success`,
+		`conclusion # "success"`,
+		`status == "success"`,
+		`retried("flaky")`,
+		`onlyFailed(flaky)`,
+		`onlyFailed("[")`,
+		`branch =~ main`,
+		// Type errors.
+		`conclusion`,
+		`attempt`,
+		`attempt > "2"`,
+		`conclusion == 1`,
+		`conclusion < "z"`,
+		`!conclusion`,
+		`attempt =~ "1"`,
+		`conclusion && true`,
+		`true || attempt`,
+	} {
+		if _, err := parseSuccessExpr(expr); err == nil {
+			t.Errorf("parseSuccessExpr(%s) returned no error", expr)
+		}
+	}
+}
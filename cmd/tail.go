@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var tailCmd = &cobra.Command{
+	Use:   "tail owner repo run-id [job-name]",
+	Short: "Stream logs of a running job",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 3 && len(args) != 4 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		runID, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid run ID %q: %w", args[2], err)
+		}
+		jobName := ""
+		if len(args) == 4 {
+			jobName = args[3]
+		}
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			return err
+		}
+		ctx := cmd.Context()
+		jobs, err := dashboard.GetJobs(ctx, client.Actions, owner, repo, runID)
+		if err != nil {
+			return err
+		}
+		job := findJobToTail(jobs, jobName)
+		if job == nil {
+			return fmt.Errorf("no matching job found in run %d", runID)
+		}
+		slog.Info("Tailing job logs", slog.String("job", job.GetName()), slog.String("url", job.GetHTMLURL()))
+		return tailJobLogs(ctx, client, owner, repo, job.GetID(), interval)
+	},
+}
+
+// findJobToTail returns the job with the given name, or the first job that is in
+// progress if the name is empty.
+func findJobToTail(jobs []*github.WorkflowJob, name string) *github.WorkflowJob {
+	for _, job := range jobs {
+		if name == "" && job.GetStatus() == "in_progress" {
+			return job
+		}
+		if name != "" && job.GetName() == name {
+			return job
+		}
+	}
+	return nil
+}
+
+// tailJobLogs polls the logs of the job and prints the part that has not been printed
+// yet, until the job completes.
+func tailJobLogs(ctx context.Context, client *github.Client, owner, repo string, jobID int64, interval time.Duration) error {
+	offset := 0
+	for {
+		job, _, err := client.Actions.GetWorkflowJobByID(ctx, owner, repo, jobID)
+		if err != nil {
+			return err
+		}
+		completed := job.GetStatus() == "completed"
+		logs, err := getJobLogs(ctx, client, owner, repo, jobID)
+		if err != nil {
+			slog.Debug("Logs are not available yet", slog.Any("error", err))
+		} else if len(logs) > offset {
+			os.Stdout.Write(logs[offset:])
+			offset = len(logs)
+		}
+		if completed {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func getJobLogs(ctx context.Context, client *github.Client, owner, repo string, jobID int64) ([]byte, error) {
+	logsURL, err := dashboard.GetJobLogsURL(ctx, client.Actions, owner, repo, jobID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Get(logsURL.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func init() {
+	rootCmd.AddCommand(tailCmd)
+
+	tailCmd.Flags().Duration("interval", 10*time.Second, "Polling interval")
+}
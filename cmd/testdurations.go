@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/spf13/cobra"
+)
+
+var testDurationsCmd = &cobra.Command{
+	Use:   "test-durations owner repo workflow",
+	Short: "Track test duration percentiles from test report artifacts and flag regressions",
+	Long: `Track test duration percentiles from the JUnit XML and go test JSON reports uploaded as
+artifacts of the workflow runs, and flag tests whose median duration in the recent half of
+the runs regressed by more than the threshold compared to the older half.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 3 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+		client := newClient()
+		owner := args[0]
+		repo := args[1]
+		workflow := args[2]
+		ctx := cmd.Context()
+		branch, err := cmd.Flags().GetString("branch")
+		if err != nil {
+			return err
+		}
+		numRuns, err := cmd.Flags().GetInt("number")
+		if err != nil {
+			return err
+		}
+		event, err := getEvent(cmd)
+		if err != nil {
+			return err
+		}
+		days, err := cmd.Flags().GetInt("days")
+		if err != nil {
+			return err
+		}
+		top, err := cmd.Flags().GetInt("top")
+		if err != nil {
+			return err
+		}
+		artifact, err := cmd.Flags().GetString("artifact")
+		if err != nil {
+			return err
+		}
+		artifactRegexp, err := regexp.Compile(artifact)
+		if err != nil {
+			return fmt.Errorf("invalid --artifact regex: %w", err)
+		}
+		threshold, err := cmd.Flags().GetFloat64("threshold")
+		if err != nil {
+			return err
+		}
+		printEventWarning(event)
+		runs, err := getWorkflowRuns(ctx, client, owner, repo, branch, workflow, event, numRuns, daysToTimeRange(days))
+		if err != nil {
+			return err
+		}
+		reports := getTestReportsForRuns(ctx, client, owner, repo, runs, artifactRegexp)
+		if len(reports) == 0 {
+			return fmt.Errorf("no test reports found in artifacts matching %q", artifact)
+		}
+		printTestDurations(getTestDurationStats(runs, reports), top, threshold)
+		return nil
+	},
+}
+
+type testDurationStats struct {
+	test string
+	// recent and previous are the durations of the passed test in the recent and the
+	// older half of the runs.
+	recent   []time.Duration
+	previous []time.Duration
+	// change is the percentage change of the recent median from the previous one.
+	change float64
+}
+
+// getTestDurationStats splits the runs, sorted newest first, in a recent and an older half
+// and collects the durations of the passed tests in each.
+func getTestDurationStats(runs []*github.WorkflowRun, reports map[int64][]testResult) []*testDurationStats {
+	var reported []*github.WorkflowRun
+	for _, run := range runs {
+		if _, ok := reports[run.GetID()]; ok {
+			reported = append(reported, run)
+		}
+	}
+	statsMap := map[string]*testDurationStats{}
+	for i, run := range reported {
+		for _, result := range reports[run.GetID()] {
+			if result.failed {
+				continue
+			}
+			stats, ok := statsMap[result.name]
+			if !ok {
+				stats = &testDurationStats{test: result.name}
+				statsMap[result.name] = stats
+			}
+			if i < (len(reported)+1)/2 {
+				stats.recent = append(stats.recent, result.duration)
+			} else {
+				stats.previous = append(stats.previous, result.duration)
+			}
+		}
+	}
+	var statsList []*testDurationStats
+	for _, stats := range statsMap {
+		if previous := dashboard.Percentile(stats.previous, 50); previous > 0 && len(stats.recent) > 0 {
+			stats.change = 100 * float64(dashboard.Percentile(stats.recent, 50)-previous) / float64(previous)
+		}
+		statsList = append(statsList, stats)
+	}
+	slices.SortFunc(statsList, func(a, b *testDurationStats) int {
+		return cmp.Or(cmp.Compare(b.change, a.change), cmp.Compare(a.test, b.test))
+	})
+	return statsList
+}
+
+func printTestDurations(statsList []*testDurationStats, top int, threshold float64) {
+	red := color.New(color.FgRed).SprintFunc()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "test name\tp50\tp90\tprevious p50\tprevious p90\tchange")
+	for i, stats := range statsList {
+		if i >= top {
+			break
+		}
+		change := ""
+		if len(stats.previous) > 0 && len(stats.recent) > 0 {
+			change = fmt.Sprintf("%+.0f%%", stats.change)
+			if stats.change > threshold {
+				change = red(change)
+			}
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s",
+			stats.test,
+			dashboard.Percentile(stats.recent, 50).Round(time.Millisecond),
+			dashboard.Percentile(stats.recent, 90).Round(time.Millisecond),
+			dashboard.Percentile(stats.previous, 50).Round(time.Millisecond),
+			dashboard.Percentile(stats.previous, 90).Round(time.Millisecond),
+			change,
+		))
+	}
+	w.Flush()
+	// Regressions beyond the top are counted too.
+	regressions := 0
+	for _, stats := range statsList {
+		if len(stats.previous) > 0 && len(stats.recent) > 0 && stats.change > threshold {
+			regressions++
+		}
+	}
+	if regressions > 0 {
+		color.New(color.FgRed, color.Bold).Printf("\n%d tests are more than %.0f%% slower\n", regressions, threshold)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(testDurationsCmd)
+
+	testDurationsCmd.Flags().StringP("branch", "b", "main", "Branch name")
+	testDurationsCmd.Flags().StringP("event", "e", "schedule", "Event type that triggered the workflows, or auto to select it based on the branch")
+	testDurationsCmd.Flags().IntP("number", "n", 64, "The number of workflow runs to process")
+	testDurationsCmd.Flags().Int("days", 30, "Limit workflow runs by the number of days")
+	testDurationsCmd.Flags().IntP("top", "t", 20, "Print top n tests sorted by duration change")
+	testDurationsCmd.Flags().String("artifact", "(?i)junit|test-results|test-report", "Regex of the names of the artifacts that contain test reports")
+	testDurationsCmd.Flags().Float64("threshold", 20, "Flag tests whose median duration increased by more than this percentage")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+)
+
+func TestGetTestDurationStats(t *testing.T) {
+	// Runs are sorted newest first. Run 2 has no reports, so runs 4 and 3 are the recent
+	// half and run 1 is the older half.
+	var runs []*github.WorkflowRun
+	for id := int64(4); id > 0; id-- {
+		runs = append(runs, &github.WorkflowRun{ID: github.Int64(id)})
+	}
+	reports := map[int64][]testResult{
+		4: {
+			{name: "a", duration: 3 * time.Second},
+			{name: "b", failed: true, duration: 10 * time.Second},
+			{name: "c", duration: time.Second},
+		},
+		3: {
+			{name: "a", duration: 3 * time.Second},
+			{name: "b", duration: time.Second},
+		},
+		1: {
+			{name: "a", duration: 2 * time.Second},
+			{name: "b", duration: 2 * time.Second},
+		},
+	}
+	for _, tt := range []struct {
+		test     string
+		recent   []time.Duration
+		previous []time.Duration
+		change   float64
+	}{
+		{"a", []time.Duration{3 * time.Second, 3 * time.Second}, []time.Duration{2 * time.Second}, 50},
+		// The failed run is skipped.
+		{"b", []time.Duration{time.Second}, []time.Duration{2 * time.Second}, -50},
+		// Tests without previous durations have no change.
+		{"c", []time.Duration{time.Second}, nil, 0},
+	} {
+		statsList := getTestDurationStats(runs, reports)
+		i := slices.IndexFunc(statsList, func(s *testDurationStats) bool { return s.test == tt.test })
+		if i < 0 {
+			t.Errorf("getTestDurationStats() has no stats for %s", tt.test)
+			continue
+		}
+		got := statsList[i]
+		if !slices.Equal(got.recent, tt.recent) || !slices.Equal(got.previous, tt.previous) || got.change != tt.change {
+			t.Errorf("getTestDurationStats() %s = %v %v %v, want %v %v %v", tt.test, got.recent, got.previous, got.change, tt.recent, tt.previous, tt.change)
+		}
+	}
+
+	var order []string
+	for _, stats := range getTestDurationStats(runs, reports) {
+		order = append(order, stats.test)
+	}
+	if want := []string{"a", "c", "b"}; !slices.Equal(order, want) {
+		t.Errorf("getTestDurationStats() order = %v, want %v", order, want)
+	}
+}
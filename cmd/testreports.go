@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// testResult is the result of a test case in a JUnit or go test JSON report.
+type testResult struct {
+	name     string
+	failed   bool
+	duration time.Duration
+}
+
+// junitTestSuite is a test suite of a JUnit XML report. Test suites can be nested.
+type junitTestSuite struct {
+	Suites []junitTestSuite `xml:"testsuite"`
+	Cases  []struct {
+		Name      string    `xml:"name,attr"`
+		Classname string    `xml:"classname,attr"`
+		Time      float64   `xml:"time,attr"`
+		Failure   *struct{} `xml:"failure"`
+		Error     *struct{} `xml:"error"`
+	} `xml:"testcase"`
+}
+
+// parseJUnitReport parses a JUnit XML report whose root is testsuites or testsuite.
+func parseJUnitReport(data []byte) ([]testResult, error) {
+	var root junitTestSuite
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	var results []testResult
+	var walk func(suite junitTestSuite)
+	walk = func(suite junitTestSuite) {
+		for _, c := range suite.Cases {
+			name := c.Name
+			if c.Classname != "" {
+				name = c.Classname + "." + c.Name
+			}
+			results = append(results, testResult{
+				name:     name,
+				failed:   c.Failure != nil || c.Error != nil,
+				duration: time.Duration(c.Time * float64(time.Second)),
+			})
+		}
+		for _, s := range suite.Suites {
+			walk(s)
+		}
+	}
+	walk(root)
+	return results, nil
+}
+
+// parseGoTestReport parses the output of go test -json.
+func parseGoTestReport(data []byte) ([]testResult, error) {
+	var results []testResult
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var event struct {
+			Action  string  `json:"Action"`
+			Package string  `json:"Package"`
+			Test    string  `json:"Test"`
+			Elapsed float64 `json:"Elapsed"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, err
+		}
+		if event.Test == "" || (event.Action != "pass" && event.Action != "fail") {
+			continue
+		}
+		results = append(results, testResult{
+			name:     event.Package + "." + event.Test,
+			failed:   event.Action == "fail",
+			duration: time.Duration(event.Elapsed * float64(time.Second)),
+		})
+	}
+	return results, scanner.Err()
+}
+
+// getTestReports downloads the artifacts of the run whose names match the regex, and
+// parses the JUnit XML (.xml) and go test JSON (.json) reports in them.
+func getTestReports(ctx context.Context, client *github.Client, owner, repo string, runID int64, artifactRegexp *regexp.Regexp) ([]testResult, error) {
+	var results []testResult
+	listOptions := github.ListOptions{PerPage: 100}
+	for {
+		artifacts, res, err := client.Actions.ListWorkflowRunArtifacts(ctx, owner, repo, runID, &listOptions)
+		if err != nil {
+			return results, err
+		}
+		for _, artifact := range artifacts.Artifacts {
+			if artifact.GetExpired() || !artifactRegexp.MatchString(artifact.GetName()) {
+				continue
+			}
+			artifactResults, err := getArtifactTestReports(ctx, client, owner, repo, artifact.GetID())
+			if err != nil {
+				return results, fmt.Errorf("failed to read artifact %s: %w", artifact.GetName(), err)
+			}
+			results = append(results, artifactResults...)
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		listOptions.Page = res.NextPage
+	}
+	return results, nil
+}
+
+func getArtifactTestReports(ctx context.Context, client *github.Client, owner, repo string, artifactID int64) ([]testResult, error) {
+	artifactURL, _, err := client.Actions.DownloadArtifact(ctx, owner, repo, artifactID, 10)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, artifactURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	var results []testResult
+	for _, f := range zr.File {
+		var parse func([]byte) ([]testResult, error)
+		switch strings.ToLower(path.Ext(f.Name)) {
+		case ".xml":
+			parse = parseJUnitReport
+		case ".json":
+			parse = parseGoTestReport
+		default:
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return results, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return results, err
+		}
+		fileResults, err := parse(content)
+		if err != nil {
+			slog.Debug("Skipping file that is not a test report", slog.String("file", f.Name), slog.Any("error", err))
+			continue
+		}
+		results = append(results, fileResults...)
+	}
+	return results, nil
+}
+
+// getTestReportsForRuns fetches the test reports of the runs in parallel, and returns them
+// keyed by run ID. Runs without test reports are omitted.
+func getTestReportsForRuns(ctx context.Context, client *github.Client, owner, repo string, runs []*github.WorkflowRun, artifactRegexp *regexp.Regexp) map[int64][]testResult {
+	result := map[int64][]testResult{}
+	tasks := make(chan int64)
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			for runID := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				results, err := getTestReports(ctx, client, owner, repo, runID, artifactRegexp)
+				if err != nil {
+					slog.Error("Failed to get test reports", slog.Int64("run-id", runID), slog.Any("error", err))
+					continue
+				}
+				if len(results) == 0 {
+					continue
+				}
+				mux.Lock()
+				result[runID] = results
+				mux.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	for _, run := range runs {
+		tasks <- run.GetID()
+	}
+	close(tasks)
+	wg.Wait()
+	return result
+}
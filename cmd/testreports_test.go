@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseJUnitReport(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		report  string
+		want    []testResult
+		wantErr bool
+	}{
+		{
+			name: "testsuites root",
+			report: `<testsuites>
+  <testsuite name="unit">
+    <testcase classname="pkg.Foo" name="testBar" time="1.5"/>
+    <testcase classname="pkg.Foo" name="testBaz" time="0.25"><failure message="boom"/></testcase>
+  </testsuite>
+  <testsuite name="e2e">
+    <testcase name="connectivity" time="2"><error/></testcase>
+  </testsuite>
+</testsuites>`,
+			want: []testResult{
+				{name: "pkg.Foo.testBar", duration: 1500 * time.Millisecond},
+				{name: "pkg.Foo.testBaz", failed: true, duration: 250 * time.Millisecond},
+				{name: "connectivity", failed: true, duration: 2 * time.Second},
+			},
+		},
+		{
+			name: "nested testsuite root",
+			report: `<testsuite name="outer">
+  <testcase name="a" time="1"/>
+  <testsuite name="inner">
+    <testcase name="b" time="3"/>
+  </testsuite>
+</testsuite>`,
+			want: []testResult{
+				{name: "a", duration: time.Second},
+				{name: "b", duration: 3 * time.Second},
+			},
+		},
+		{
+			name:    "not xml",
+			report:  `{"Action":"pass"}`,
+			wantErr: true,
+		},
+	} {
+		got, err := parseJUnitReport([]byte(tt.report))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseJUnitReport() returned %v, want error %t", tt.name, err, tt.wantErr)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: parseJUnitReport() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseGoTestReport(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		report  string
+		want    []testResult
+		wantErr bool
+	}{
+		{
+			name: "pass and fail",
+			report: `{"Action":"run","Package":"example.com/foo","Test":"TestA"}
+{"Action":"output","Package":"example.com/foo","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"pass","Package":"example.com/foo","Test":"TestA","Elapsed":0.5}
+{"Action":"fail","Package":"example.com/foo","Test":"TestB/sub","Elapsed":1.25}
+{"Action":"skip","Package":"example.com/foo","Test":"TestC","Elapsed":0}
+{"Action":"pass","Package":"example.com/foo","Elapsed":2}
+`,
+			want: []testResult{
+				{name: "example.com/foo.TestA", duration: 500 * time.Millisecond},
+				{name: "example.com/foo.TestB/sub", failed: true, duration: 1250 * time.Millisecond},
+			},
+		},
+		{
+			name:   "empty",
+			report: ``,
+		},
+		{
+			name:    "not json",
+			report:  `<testsuites/>`,
+			wantErr: true,
+		},
+	} {
+		got, err := parseGoTestReport([]byte(tt.report))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseGoTestReport() returned %v, want error %t", tt.name, err, tt.wantErr)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: parseGoTestReport() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
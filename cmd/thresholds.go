@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+// checkThresholds logs workflows whose success rate is below failUnder percent or whose
+// average duration is longer than slowerThan, and returns true if there are any.
+// Thresholds with a zero value are disabled, and workflows with an active known issue
+// are excluded.
+func checkThresholds(cfg *config, result map[string][]*github.WorkflowRun, failUnder float32, slowerThan time.Duration) bool {
+	var workflows []string
+	for workflow := range result {
+		workflows = append(workflows, workflow)
+	}
+	slices.Sort(workflows)
+	failed := false
+	for _, workflow := range workflows {
+		runs := result[workflow]
+		if len(runs) == 0 || cfg.getKnownIssue(workflow) != nil {
+			continue
+		}
+		if rate := dashboard.SuccessRate(runs); failUnder > 0 && rate < failUnder {
+			slog.Error("Success rate is below the threshold",
+				slog.String("workflow", workflow), slog.Float64("success-rate", float64(rate)), slog.Float64("threshold", float64(failUnder)))
+			failed = true
+		}
+		if duration := dashboard.AverageSuccessDuration(runs, runDurations); slowerThan > 0 && duration > slowerThan {
+			slog.Error("Average duration exceeds the threshold",
+				slog.String("workflow", workflow), slog.Duration("duration", duration), slog.Duration("threshold", slowerThan))
+			failed = true
+		}
+	}
+	return failed
+}
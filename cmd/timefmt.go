@@ -0,0 +1,27 @@
+package cmd
+
+import "time"
+
+// The time zone and the layouts of timestamps and dates in the output, set with the
+// --timezone, --time-format, and --date-format flags.
+var (
+	displayLocation = time.UTC
+	timeFormat      = time.DateTime
+	dateFormat      = time.DateOnly
+)
+
+// formatTime formats the timestamp in the display time zone.
+func formatTime(t time.Time) string {
+	return t.In(displayLocation).Format(timeFormat)
+}
+
+// formatDate formats the date of the timestamp in the display time zone.
+func formatDate(t time.Time) string {
+	return t.In(displayLocation).Format(dateFormat)
+}
+
+// timeZoneName returns the abbreviated name of the display time zone at the given time
+// (e.g. UTC, CEST).
+func timeZoneName(t time.Time) string {
+	return t.In(displayLocation).Format("MST")
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultJobTimeout is the timeout of jobs without timeout-minutes.
+const defaultJobTimeout = 360 * time.Minute
+
+// jobTimeout is the timeout of a job in a workflow file. Job names in the API include
+// matrix values and evaluated expressions, so they are matched with a regular expression.
+type jobTimeout struct {
+	id   string
+	name *regexp.Regexp
+	// literal is the length of the name without expressions. Names with longer literals
+	// are more specific.
+	literal int
+	timeout time.Duration
+}
+
+var expressionRegexp = regexp.MustCompile(`\$\{\{.*?\}\}`)
+
+// parseJobTimeouts returns the timeout of each job in the workflow file, from the most
+// specific name to the least specific one, and by job ID.
+func parseJobTimeouts(content string) ([]jobTimeout, error) {
+	var wf struct {
+		Jobs map[string]struct {
+			Name    string    `yaml:"name"`
+			Timeout yaml.Node `yaml:"timeout-minutes"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &wf); err != nil {
+		return nil, err
+	}
+	var timeouts []jobTimeout
+	for id, job := range wf.Jobs {
+		literal := 0
+		name := job.Name
+		if name == "" {
+			name = id
+		}
+		var pattern strings.Builder
+		for i, part := range expressionRegexp.Split(name, -1) {
+			if i > 0 {
+				pattern.WriteString(".*")
+			}
+			pattern.WriteString(regexp.QuoteMeta(part))
+			literal += len(part)
+		}
+		timeout := defaultJobTimeout
+		// Jobs whose timeout-minutes is an expression are skipped since its value is unknown.
+		var minutes float64
+		if job.Timeout.Kind == yaml.ScalarNode {
+			if err := job.Timeout.Decode(&minutes); err != nil {
+				continue
+			}
+			timeout = time.Duration(minutes * float64(time.Minute))
+		}
+		timeouts = append(timeouts, jobTimeout{
+			id: id,
+			// Matrix jobs are named "name (value, ...)" unless the name has an expression.
+			name:    regexp.MustCompile("^" + pattern.String() + `( \(.*\))?$`),
+			literal: literal,
+			timeout: timeout,
+		})
+	}
+	// Names with expressions match more job names, so the same job could otherwise match
+	// different entries depending on the map order.
+	slices.SortFunc(timeouts, func(a, b jobTimeout) int {
+		return cmp.Or(cmp.Compare(b.literal, a.literal), cmp.Compare(a.id, b.id))
+	})
+	return timeouts, nil
+}
+
+// findJobTimeout returns the most specific of the timeouts sorted by parseJobTimeouts
+// whose name matches the job name.
+func findJobTimeout(timeouts []jobTimeout, name string) (jobTimeout, bool) {
+	i := slices.IndexFunc(timeouts, func(t jobTimeout) bool { return t.name.MatchString(name) })
+	if i < 0 {
+		return jobTimeout{}, false
+	}
+	return timeouts[i], true
+}
+
+type timeoutStats struct {
+	workflow string
+	job      string
+	timeout  time.Duration
+	p90      time.Duration
+	max      time.Duration
+	count    int
+}
+
+// getTimeoutProneJobs returns the jobs whose p90 duration is at least ratio of their
+// timeout.
+func getTimeoutProneJobs(workflow string, timeouts []jobTimeout, jobs map[int64][]*github.WorkflowJob, ratio float64) []timeoutStats {
+	durations := map[string][]time.Duration{}
+	for _, runJobs := range jobs {
+		for _, job := range runJobs {
+			if job.GetConclusion() != "success" || job.StartedAt == nil || job.CompletedAt == nil {
+				continue
+			}
+			durations[job.GetName()] = append(durations[job.GetName()], job.GetCompletedAt().Sub(job.GetStartedAt().Time))
+		}
+	}
+	var statsList []timeoutStats
+	for name, jobDurations := range durations {
+		jt, ok := findJobTimeout(timeouts, name)
+		if !ok {
+			continue
+		}
+		stats := timeoutStats{
+			workflow: workflow,
+			job:      name,
+			timeout:  jt.timeout,
+			p90:      dashboard.Percentile(jobDurations, 90),
+			max:      dashboard.Percentile(jobDurations, 100),
+			count:    len(jobDurations),
+		}
+		if float64(stats.p90) >= ratio*float64(stats.timeout) {
+			statsList = append(statsList, stats)
+		}
+	}
+	return statsList
+}
+
+// printTimeoutProneJobs prints the jobs whose durations are close to their timeout-minutes,
+// before they start failing with timeouts.
+func printTimeoutProneJobs(ctx context.Context, client *github.Client, owner, repo, branch string, result map[string][]*github.WorkflowRun, ratio float64) {
+	var statsList []timeoutStats
+	for workflow, runs := range result {
+		content, err := getWorkflowFile(ctx, client, owner, repo, branch, workflow)
+		if err != nil {
+			slog.Error("Failed to get workflow file", slog.String("workflow", workflow), slog.Any("error", err))
+			continue
+		}
+		timeouts, err := parseJobTimeouts(content)
+		if err != nil {
+			slog.Error("Failed to parse workflow file", slog.String("workflow", workflow), slog.Any("error", err))
+			continue
+		}
+		jobs := dashboard.GetJobsForRuns(ctx, client.Actions, owner, repo, runs, numWorkers)
+		statsList = append(statsList, getTimeoutProneJobs(workflow, timeouts, jobs, ratio)...)
+	}
+	slices.SortFunc(statsList, func(a, b timeoutStats) int {
+		return cmp.Or(cmp.Compare(float64(b.p90)/float64(b.timeout), float64(a.p90)/float64(a.timeout)), cmp.Compare(a.job, b.job))
+	})
+	color.New(color.FgYellow, color.Bold).Println("\ntimeout-prone jobs")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "p90\tmax\ttimeout\tp90/timeout\tjobs\tworkflow\tjob name")
+	for _, stats := range statsList {
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%s\t%s\t%.0f%%\t%d\t%s\t%s",
+			stats.p90.Round(time.Second),
+			stats.max.Round(time.Second),
+			stats.timeout,
+			100*float64(stats.p90)/float64(stats.timeout),
+			stats.count,
+			stats.workflow,
+			stats.job,
+		))
+	}
+	w.Flush()
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+const timeoutsWorkflow = `
+jobs:
+  build:
+    timeout-minutes: 30
+  tests:
+    name: Test ${{ matrix.k8s }}
+    timeout-minutes: 60
+  matrix:
+    name: ${{ matrix.name }}
+  setup:
+    name: Set up
+    timeout-minutes: ${{ inputs.timeout }}
+`
+
+func TestParseJobTimeouts(t *testing.T) {
+	timeouts, err := parseJobTimeouts(timeoutsWorkflow)
+	if err != nil {
+		t.Fatalf("parseJobTimeouts() returned %v", err)
+	}
+	var ids []string
+	for _, jt := range timeouts {
+		ids = append(ids, jt.id)
+	}
+	// Most specific first: "Test " has 5 literal characters, "build" 5 and "${{ matrix.name }}" none.
+	if want := []string{"build", "tests", "matrix"}; !slices.Equal(ids, want) {
+		t.Errorf("parseJobTimeouts() = %v, want %v", ids, want)
+	}
+
+	for _, tt := range []struct {
+		job     string
+		want    time.Duration
+		wantJob string
+	}{
+		{"build", 30 * time.Minute, "build"},
+		{"build (amd64)", 30 * time.Minute, "build"},
+		{"Test 1.29", 60 * time.Minute, "tests"},
+		{"Test 1.29 (ipv6)", 60 * time.Minute, "tests"},
+		{"lint", defaultJobTimeout, "matrix"},
+		// The job whose timeout is an expression is skipped.
+		{"Set up", defaultJobTimeout, "matrix"},
+	} {
+		got, ok := findJobTimeout(timeouts, tt.job)
+		if !ok {
+			t.Errorf("findJobTimeout(%q) found no timeout", tt.job)
+			continue
+		}
+		if got.id != tt.wantJob || got.timeout != tt.want {
+			t.Errorf("findJobTimeout(%q) = %s %v, want %s %v", tt.job, got.id, got.timeout, tt.wantJob, tt.want)
+		}
+	}
+}
+
+func TestParseJobTimeoutsOrder(t *testing.T) {
+	// Both patterns match "a b"; the result must not depend on the map order.
+	const content = `
+jobs:
+  x:
+    name: ${{ matrix.os }} b
+  y:
+    name: a ${{ matrix.os }}
+    timeout-minutes: 10
+`
+	for i := 0; i < 20; i++ {
+		timeouts, err := parseJobTimeouts(content)
+		if err != nil {
+			t.Fatalf("parseJobTimeouts() returned %v", err)
+		}
+		got, _ := findJobTimeout(timeouts, "a b")
+		if got.id != "x" || got.timeout != defaultJobTimeout {
+			t.Fatalf("findJobTimeout(%q) = %s %v, want x %v", "a b", got.id, got.timeout, defaultJobTimeout)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// The time range set with the --since and --until flags. --since is either relative to
+// the current time (sinceAge) or absolute (sinceTime), and overrides --days.
+var (
+	sinceAge  time.Duration
+	sinceTime time.Time
+	untilTime time.Time
+)
+
+// parseTimeRange parses the --since and --until flags. Both accept dates (2024-01-01),
+// RFC 3339 timestamps, and ages such as 2w, 3d, or 12h. Dates are in the display time
+// zone, and an --until date includes the whole day.
+func parseTimeRange(since, until string) error {
+	if since != "" {
+		age, t, err := parseTimeSpec(since, false)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		sinceAge, sinceTime = age, t
+	}
+	if until != "" {
+		age, t, err := parseTimeSpec(until, true)
+		if err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+		if age > 0 {
+			t = time.Now().Add(-age)
+		}
+		untilTime = t
+	}
+	return nil
+}
+
+// parseTimeSpec parses an age or an absolute time. If endOfDay is true, a date is
+// converted to the last second of the day.
+func parseTimeSpec(s string, endOfDay bool) (time.Duration, time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, s, displayLocation); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		return 0, t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return 0, t, nil
+	}
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	if unit, ok := units[s[len(s)-1:]]; ok {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n < 0 {
+			return 0, time.Time{}, fmt.Errorf("expected a date, a timestamp, or an age like 2w, got %q", s)
+		}
+		return time.Duration(n) * unit, time.Time{}, nil
+	}
+	age, err := time.ParseDuration(s)
+	if err != nil || age < 0 {
+		return 0, time.Time{}, fmt.Errorf("expected a date, a timestamp, or an age like 2w, got %q", s)
+	}
+	return age, time.Time{}, nil
+}
+
+// timeRangeBounds returns the start and the end of the time range. The start is --days
+// ago unless --since is set. The end is zero unless --until is set.
+func timeRangeBounds(days int) (time.Time, time.Time) {
+	now := time.Now()
+	from := now.Add(-time.Duration(days) * 24 * time.Hour)
+	switch {
+	case sinceAge > 0:
+		from = now.Add(-sinceAge)
+	case !sinceTime.IsZero():
+		from = sinceTime
+	}
+	return from, untilTime
+}
+
+// daysToTimeRange returns the created filter of the runs for the time range.
+func daysToTimeRange(days int) string {
+	from, to := timeRangeBounds(days)
+	if to.IsZero() {
+		return fmt.Sprintf(">=%s", from.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s..%s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v59/github"
+)
+
+type testFailures struct {
+	test  string
+	count int
+	// workflows counts the failures in each workflow.
+	workflows map[string]int
+}
+
+// getTopFailingTests returns the failed tests sorted by the number of failures, along with
+// the workflows each test failed in.
+func getTopFailingTests(failedTestRuns map[string][]*github.WorkflowRun, result map[string][]*github.WorkflowRun) []testFailures {
+	workflowOf := map[int64]string{}
+	for workflow, runs := range result {
+		for _, run := range runs {
+			workflowOf[run.GetID()] = workflow
+		}
+	}
+	var tests []testFailures
+	for test, runs := range failedTestRuns {
+		failures := testFailures{test: test, count: len(runs), workflows: map[string]int{}}
+		for _, run := range runs {
+			failures.workflows[workflowOf[run.GetID()]]++
+		}
+		tests = append(tests, failures)
+	}
+	slices.SortFunc(tests, func(a, b testFailures) int {
+		return cmp.Or(b.count-a.count, cmp.Compare(a.test, b.test))
+	})
+	return tests
+}
+
+// printTopFailingTests analyzes the logs of the failed runs of all the workflows, and
+// prints the tests that failed the most. With maxLogs, only that many logs of the
+// workflows with the lowest success rates are analyzed.
+func printTopFailingTests(ctx context.Context, client *github.Client, owner, repo string, result map[string][]*github.WorkflowRun, top, maxLogs int, maxLogSize int64, yes bool) error {
+	var jobLogs []jobLog
+	for _, workflow := range sortWorkflowsBySuccessRate(result) {
+		jobLogs = append(jobLogs, getFailureDetails(ctx, client, owner, repo, result[workflow]).jobLogs...)
+	}
+	if maxLogs > 0 && len(jobLogs) > maxLogs {
+		slog.Info("Analyzing the logs of the workflows with the lowest success rates",
+			slog.Int("logs", maxLogs), slog.Int("failed-jobs", len(jobLogs)))
+		jobLogs = prioritizeJobLogs(jobLogs, maxLogs)
+	}
+	ok, asked, err := confirmLogDownload(ctx, jobLogs, maxLogSize, yes)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		slog.Info("Skipping log analysis")
+		return nil
+	}
+	if asked {
+		resolveLogURLs(ctx, client, owner, repo, jobLogs)
+	}
+	analysis := analyzeLogs(ctx, jobLogs, 0)
+	color.New(color.FgRed, color.Bold).Println("\ntop failing tests")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "test name\tfailure count\tworkflows")
+	for i, failures := range getTopFailingTests(analysis.failedTestRuns, result) {
+		if i >= top {
+			break
+		}
+		var workflows []string
+		for workflow := range failures.workflows {
+			workflows = append(workflows, workflow)
+		}
+		slices.SortFunc(workflows, func(a, b string) int {
+			return cmp.Or(failures.workflows[b]-failures.workflows[a], cmp.Compare(a, b))
+		})
+		for j, workflow := range workflows {
+			workflows[j] = fmt.Sprintf("%s (%d)", workflow, failures.workflows[workflow])
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s\t%d\t%s", failures.test, failures.count, strings.Join(workflows, ", ")))
+	}
+	w.Flush()
+	return nil
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// webhookQueueSize is the number of webhook events that can wait for the daemon to
+// refresh their workflows.
+const webhookQueueSize = 100
+
+// newWebhookHandler returns a handler for GitHub webhooks that sends completed
+// workflow_run events to the channel. Payloads are verified with the shared secret. The
+// handler does not wait for the channel, and drops events if it is full, since GitHub
+// times out slow deliveries and the next full evaluation refreshes all the workflows.
+func newWebhookHandler(secret []byte, events chan<- *github.WorkflowRunEvent) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := github.ValidatePayload(r, secret)
+		if err != nil {
+			slog.Warn("Invalid webhook payload", slog.Any("error", err))
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		event, err := github.ParseWebHook(github.WebHookType(r), payload)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if e, ok := event.(*github.WorkflowRunEvent); ok && e.GetAction() == "completed" {
+			select {
+			case events <- e:
+			default:
+				slog.Warn("Dropping webhook event, the queue is full", slog.String("workflow", e.GetWorkflow().GetPath()))
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
@@ -6,6 +6,8 @@ require (
 	github.com/fatih/color v1.16.0
 	github.com/google/go-github/v59 v59.0.0
 	github.com/spf13/cobra v1.8.0
+	golang.org/x/crypto v0.15.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,132 @@
+// Package dashboardtest provides an in-memory implementation of dashboard.ActionsService,
+// so that the fetching and the stats can be tested without the GitHub API.
+package dashboardtest
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+var _ dashboard.ActionsService = (*Actions)(nil)
+
+const (
+	// defaultPerPage is the page size of the API if the list options don't set one.
+	defaultPerPage = 30
+	// maxResults is the maximum number of runs the API returns for a query, regardless of
+	// pagination.
+	maxResults = 1000
+)
+
+// Actions serves workflows, runs, jobs, and logs URLs from memory. Runs are paginated
+// like the API, while workflows and jobs are returned in a single page. Owner and repo are
+// ignored.
+type Actions struct {
+	Workflows []*github.Workflow
+	// Runs are keyed by workflow file name, newest first.
+	Runs map[string][]*github.WorkflowRun
+	// Jobs are keyed by run ID.
+	Jobs map[int64][]*github.WorkflowJob
+	// LogsURLs are keyed by job ID.
+	LogsURLs map[int64]*url.URL
+}
+
+func (a *Actions) ListWorkflows(ctx context.Context, owner, repo string, opts *github.ListOptions) (*github.Workflows, *github.Response, error) {
+	return &github.Workflows{TotalCount: github.Int(len(a.Workflows)), Workflows: a.Workflows}, response(http.StatusOK), nil
+}
+
+// ListWorkflowRunsByFileName returns a page of the runs of the workflow that match the
+// branch, the event, and the created filter of the options, or a not found error if the
+// workflow does not exist. Like the API, only the first maxResults matching runs can be
+// paginated through.
+func (a *Actions) ListWorkflowRunsByFileName(ctx context.Context, owner, repo, workflowFileName string, opts *github.ListWorkflowRunsOptions) (*github.WorkflowRuns, *github.Response, error) {
+	if !a.hasWorkflow(workflowFileName) {
+		res := response(http.StatusNotFound)
+		return nil, res, &github.ErrorResponse{Response: res.Response, Message: "Not Found"}
+	}
+	if opts == nil {
+		opts = &github.ListWorkflowRunsOptions{}
+	}
+	from, to, err := parseCreated(opts.Created)
+	if err != nil {
+		return nil, response(http.StatusUnprocessableEntity), err
+	}
+	var runs []*github.WorkflowRun
+	for _, run := range a.Runs[workflowFileName] {
+		created := run.GetCreatedAt().Time
+		if opts.Branch != "" && run.GetHeadBranch() != opts.Branch ||
+			opts.Event != "" && run.GetEvent() != opts.Event ||
+			!from.IsZero() && created.Before(from) ||
+			!to.IsZero() && created.After(to) {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	total := len(runs)
+	runs = runs[:min(len(runs), maxResults)]
+	perPage := cmp.Or(opts.PerPage, defaultPerPage)
+	page := max(opts.Page, 1)
+	start := min((page-1)*perPage, len(runs))
+	end := min(start+perPage, len(runs))
+	res := response(http.StatusOK)
+	if end < len(runs) {
+		res.NextPage = page + 1
+	}
+	return &github.WorkflowRuns{TotalCount: github.Int(total), WorkflowRuns: runs[start:end]}, res, nil
+}
+
+// parseCreated returns the bounds of a created filter such as ">=2024-01-01T00:00:00Z" or
+// "2024-01-01T00:00:00Z..2024-02-01T00:00:00Z". Open bounds are zero.
+func parseCreated(created string) (time.Time, time.Time, error) {
+	var from, to time.Time
+	fromString, toString := dashboard.SplitTimeRange(created)
+	var err error
+	if fromString != "" {
+		if from, err = time.Parse(time.RFC3339, fromString); err != nil {
+			return from, to, err
+		}
+	}
+	if toString != "" {
+		if to, err = time.Parse(time.RFC3339, toString); err != nil {
+			return from, to, err
+		}
+	}
+	return from, to, nil
+}
+
+func (a *Actions) ListWorkflowJobs(ctx context.Context, owner, repo string, runID int64, opts *github.ListWorkflowJobsOptions) (*github.Jobs, *github.Response, error) {
+	jobs := a.Jobs[runID]
+	return &github.Jobs{TotalCount: github.Int(len(jobs)), Jobs: jobs}, response(http.StatusOK), nil
+}
+
+func (a *Actions) GetWorkflowJobLogs(ctx context.Context, owner, repo string, jobID int64, maxRedirects int) (*url.URL, *github.Response, error) {
+	logsURL, ok := a.LogsURLs[jobID]
+	if !ok {
+		return nil, response(http.StatusNotFound), fmt.Errorf("no logs for job %d", jobID)
+	}
+	return logsURL, response(http.StatusFound), nil
+}
+
+// hasWorkflow returns true if the workflow file name is in Workflows or Runs.
+func (a *Actions) hasWorkflow(workflowFileName string) bool {
+	if _, ok := a.Runs[workflowFileName]; ok {
+		return true
+	}
+	for _, workflow := range a.Workflows {
+		if path.Base(workflow.GetPath()) == workflowFileName {
+			return true
+		}
+	}
+	return false
+}
+
+func response(statusCode int) *github.Response {
+	return &github.Response{Response: &http.Response{StatusCode: statusCode}}
+}
@@ -0,0 +1,99 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// FailureReport counts the failed jobs and steps of failed workflow runs.
+type FailureReport struct {
+	FailedJobs     map[string]int
+	FailedSteps    map[string]int
+	CancelledSteps map[string]int
+	// JobURLs and StepURLs are links to the logs of a representative failed job or step.
+	JobURLs  map[string]string
+	StepURLs map[string]string
+	// Jobs are the failed jobs in the order they were added.
+	Jobs []*github.WorkflowJob
+	// MissingRuns is the number of failed runs whose jobs failed to fetch.
+	MissingRuns int
+}
+
+// FailureCount is the number of failures of a job or a step.
+type FailureCount struct {
+	Name  string
+	Count int
+}
+
+// NewFailureReport returns an empty report.
+func NewFailureReport() *FailureReport {
+	return &FailureReport{
+		FailedJobs:     map[string]int{},
+		FailedSteps:    map[string]int{},
+		CancelledSteps: map[string]int{},
+		JobURLs:        map[string]string{},
+		StepURLs:       map[string]string{},
+	}
+}
+
+// AddJob counts the job and its steps if the job failed.
+func (r *FailureReport) AddJob(job *github.WorkflowJob) {
+	if job.GetConclusion() != "failure" {
+		return
+	}
+	r.Jobs = append(r.Jobs, job)
+	r.FailedJobs[job.GetName()]++
+	if _, ok := r.JobURLs[job.GetName()]; !ok {
+		r.JobURLs[job.GetName()] = job.GetHTMLURL()
+	}
+	for _, step := range job.Steps {
+		if step.GetConclusion() == "failure" {
+			r.FailedSteps[step.GetName()]++
+			if _, ok := r.StepURLs[step.GetName()]; !ok {
+				r.StepURLs[step.GetName()] = fmt.Sprintf("%s#step:%d:1", job.GetHTMLURL(), step.GetNumber())
+			}
+		} else if step.GetConclusion() == "cancelled" {
+			r.CancelledSteps[step.GetName()]++
+		}
+	}
+}
+
+// GetFailureReport fetches the jobs of the failed runs with the given number of
+// concurrent requests, and counts the failed jobs and steps. The jobs are added in the
+// order of the runs.
+func GetFailureReport(ctx context.Context, actions ActionsService, owner, repo string, runs []*github.WorkflowRun, workers int) *FailureReport {
+	var failedRuns []*github.WorkflowRun
+	for _, run := range runs {
+		if run.GetConclusion() == "failure" {
+			failedRuns = append(failedRuns, run)
+		}
+	}
+	report := NewFailureReport()
+	jobsByRun := GetJobsForRuns(ctx, actions, owner, repo, failedRuns, workers)
+	for _, run := range failedRuns {
+		jobs, ok := jobsByRun[run.GetID()]
+		if !ok {
+			report.MissingRuns++
+			continue
+		}
+		for _, job := range jobs {
+			report.AddJob(job)
+		}
+	}
+	return report
+}
+
+// SortByCount returns the counts sorted from the most to the least failures.
+func SortByCount(counts map[string]int) []FailureCount {
+	var failureCounts []FailureCount
+	for name, count := range counts {
+		failureCounts = append(failureCounts, FailureCount{Name: name, Count: count})
+	}
+	slices.SortFunc(failureCounts, func(a, b FailureCount) int {
+		return b.Count - a.Count
+	})
+	return failureCounts
+}
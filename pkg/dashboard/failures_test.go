@@ -0,0 +1,64 @@
+package dashboard_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard/dashboardtest"
+)
+
+func newJob(id, runID int64, name, conclusion string, steps ...*github.TaskStep) *github.WorkflowJob {
+	return &github.WorkflowJob{
+		ID:         github.Int64(id),
+		RunID:      github.Int64(runID),
+		Name:       github.String(name),
+		Conclusion: github.String(conclusion),
+		HTMLURL:    github.String("https://github.com/owner/repo/actions/runs/1/job/1"),
+		Steps:      steps,
+	}
+}
+
+func newStep(number int64, name, conclusion string) *github.TaskStep {
+	return &github.TaskStep{Number: github.Int64(number), Name: github.String(name), Conclusion: github.String(conclusion)}
+}
+
+func TestGetFailureReport(t *testing.T) {
+	// Runs 4 and 2 failed, and the others succeeded.
+	runs := newRuns(4, func(i int) string {
+		if i%2 == 0 {
+			return "failure"
+		}
+		return "success"
+	})
+	actions := &dashboardtest.Actions{Jobs: map[int64][]*github.WorkflowJob{
+		4: {
+			newJob(41, 4, "build", "success"),
+			newJob(42, 4, "test", "failure", newStep(1, "setup", "success"), newStep(2, "run tests", "failure")),
+		},
+		2: {
+			newJob(21, 2, "test", "failure", newStep(1, "setup", "cancelled")),
+		},
+		3: {
+			newJob(31, 3, "test", "success"),
+		},
+	}}
+	// No workers are clamped to one instead of waiting forever.
+	report := dashboard.GetFailureReport(context.Background(), actions, "owner", "repo", runs, 0)
+	if got := report.FailedJobs; len(got) != 1 || got["test"] != 2 {
+		t.Errorf("FailedJobs = %v, want test: 2", got)
+	}
+	if got := report.FailedSteps; len(got) != 1 || got["run tests"] != 1 {
+		t.Errorf("FailedSteps = %v, want run tests: 1", got)
+	}
+	if got := report.CancelledSteps; len(got) != 1 || got["setup"] != 1 {
+		t.Errorf("CancelledSteps = %v, want setup: 1", got)
+	}
+	if len(report.Jobs) != 2 || report.Jobs[0].GetID() != 42 || report.Jobs[1].GetID() != 21 {
+		t.Errorf("Jobs = %v, want jobs 42 and 21", report.Jobs)
+	}
+	if report.MissingRuns != 0 {
+		t.Errorf("MissingRuns = %d, want 0", report.MissingRuns)
+	}
+}
@@ -0,0 +1,301 @@
+// Package dashboard fetches GitHub Actions workflow runs and jobs and computes the stats
+// shown by ci-dashboard, so that other programs and bots can embed the analysis without
+// shelling out to the CLI.
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// AllRuns is the number of runs to fetch every run within the created range regardless of
+// the limit mode.
+const AllRuns = math.MaxInt
+
+// maxQueryResults is the maximum number of runs the API returns for a query, regardless
+// of pagination.
+const maxQueryResults = 1000
+
+// ErrWorkflowNotFound is returned when the workflow file does not exist, for example
+// because it was deleted.
+var ErrWorkflowNotFound = errors.New("workflow not found")
+
+// DefaultConclusions are the run conclusions included in the stats unless RunOptions
+// specify otherwise.
+var DefaultConclusions = []string{"success", "failure"}
+
+// MergeQueueBranchRegexp matches the head branches of merge_group runs, such as
+// gh-readonly-queue/main/pr-123-0123abcd.
+var MergeQueueBranchRegexp = regexp.MustCompile(`^gh-readonly-queue/(.+)/pr-(\d+)-[0-9a-f]+$`)
+
+// ActionsService is the subset of the GitHub Actions API used by the package. It is
+// implemented by the Actions service of a github.Client, and by dashboardtest.Actions in
+// tests.
+type ActionsService interface {
+	ListWorkflows(ctx context.Context, owner, repo string, opts *github.ListOptions) (*github.Workflows, *github.Response, error)
+	ListWorkflowRunsByFileName(ctx context.Context, owner, repo, workflowFileName string, opts *github.ListWorkflowRunsOptions) (*github.WorkflowRuns, *github.Response, error)
+	ListWorkflowJobs(ctx context.Context, owner, repo string, runID int64, opts *github.ListWorkflowJobsOptions) (*github.Jobs, *github.Response, error)
+	GetWorkflowJobLogs(ctx context.Context, owner, repo string, jobID int64, maxRedirects int) (*url.URL, *github.Response, error)
+}
+
+// RunOptions select the workflow runs to fetch.
+type RunOptions struct {
+	Branch string
+	Event  string
+	// Count is the number of runs to fetch, or AllRuns.
+	Count int
+	// Created is a created filter of the API, such as ">=2024-01-01T00:00:00Z".
+	Created string
+	// LimitMode is how Count and Created are combined. In the and mode, which is the
+	// default, up to Count runs within the created range are fetched. In the or mode, the
+	// latest Count runs and all the runs within the created range are fetched.
+	LimitMode string
+	// Conclusions are the conclusions of the runs to include. Defaults to
+	// DefaultConclusions.
+	Conclusions []string
+	// Excluded is called with each fetched run whose conclusion is not included, for
+	// example to count cancelled runs without including them in the stats.
+	Excluded func(run *github.WorkflowRun)
+}
+
+// ListWorkflows returns all the workflows of the repository, including deleted ones.
+func ListWorkflows(ctx context.Context, actions ActionsService, owner, repo string) ([]*github.Workflow, error) {
+	listOptions := github.ListOptions{}
+	var workflows []*github.Workflow
+	for {
+		wf, res, err := actions.ListWorkflows(ctx, owner, repo, &listOptions)
+		if err != nil {
+			return nil, err
+		}
+		workflows = append(workflows, wf.Workflows...)
+		if res.NextPage == 0 {
+			break
+		}
+		listOptions.Page = res.NextPage
+	}
+	return workflows, nil
+}
+
+// GetWorkflows returns the file names of the workflows, excluding deleted ones.
+func GetWorkflows(ctx context.Context, actions ActionsService, owner, repo string) ([]string, error) {
+	workflows, err := ListWorkflows(ctx, actions, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	var filepaths []string
+	for _, workflow := range workflows {
+		if workflow.GetState() == "deleted" {
+			continue
+		}
+		filepaths = append(filepaths, path.Base(workflow.GetPath()))
+	}
+	slices.Sort(filepaths)
+	return filepaths, nil
+}
+
+// GetWorkflowRuns returns the runs of the workflow file, newest first. It returns an error
+// wrapping ErrWorkflowNotFound if the workflow does not exist.
+func GetWorkflowRuns(ctx context.Context, actions ActionsService, owner, repo, workflow string, opts RunOptions) ([]*github.WorkflowRun, error) {
+	runs, err := ListWorkflowRuns(opts, func(listOptions *github.ListWorkflowRunsOptions) (*github.WorkflowRuns, *github.Response, error) {
+		return actions.ListWorkflowRunsByFileName(ctx, owner, repo, workflow, listOptions)
+	})
+	var errorResponse *github.ErrorResponse
+	if errors.As(err, &errorResponse) && errorResponse.Response.StatusCode == http.StatusNotFound {
+		return runs, fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflow)
+	}
+	return runs, err
+}
+
+// ListWorkflowRuns returns the runs selected by the options using the given list
+// function, which is called with each page of the list options.
+func ListWorkflowRuns(opts RunOptions, list func(*github.ListWorkflowRunsOptions) (*github.WorkflowRuns, *github.Response, error)) ([]*github.WorkflowRun, error) {
+	conclusions := opts.Conclusions
+	if conclusions == nil {
+		conclusions = DefaultConclusions
+	}
+	count := opts.Count
+	listOptions := github.ListWorkflowRunsOptions{
+		Branch:      opts.Branch,
+		Event:       opts.Event,
+		Created:     opts.Created,
+		ListOptions: github.ListOptions{},
+	}
+	// In the or mode, runs older than the cutoff are fetched too until there are count
+	// runs, so the created filter is applied here instead of by the API.
+	var cutoff time.Time
+	if from, to := SplitTimeRange(opts.Created); opts.LimitMode == "or" && from != "" && count != AllRuns {
+		var err error
+		cutoff, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, err
+		}
+		listOptions.Created = ""
+		if to != "" {
+			listOptions.Created = "<=" + to
+		}
+	}
+	// The head branches of merge_group runs are temporary merge queue branches, so runs
+	// for the target branch are filtered here instead of by the API.
+	mergeQueueBase := ""
+	if opts.Event == "merge_group" {
+		mergeQueueBase = opts.Branch
+		listOptions.Branch = ""
+	}
+	var workflowRuns []*github.WorkflowRun
+	// The API returns at most maxQueryResults runs for a query, so once they are exhausted
+	// the query is narrowed down to the runs created before the oldest run so far.
+	seen := map[int64]bool{}
+	var returned, unseen int
+	var oldest time.Time
+	for {
+		runs, res, err := list(&listOptions)
+		if err != nil {
+			return workflowRuns, err
+		}
+		slog.Debug("Rate limit", slog.Int("remaining", res.Rate.Remaining), slog.Time("reset", res.Rate.Reset.Time))
+		returned += len(runs.WorkflowRuns)
+		for _, run := range runs.WorkflowRuns {
+			if seen[run.GetID()] {
+				continue
+			}
+			seen[run.GetID()] = true
+			unseen++
+			oldest = run.GetCreatedAt().Time
+			if mergeQueueBase != "" {
+				if match := MergeQueueBranchRegexp.FindStringSubmatch(run.GetHeadBranch()); match == nil || match[1] != mergeQueueBase {
+					continue
+				}
+			}
+			if slices.Contains(conclusions, run.GetConclusion()) {
+				workflowRuns = append(workflowRuns, run)
+			} else if opts.Excluded != nil {
+				opts.Excluded(run)
+			}
+		}
+		if count == AllRuns {
+			slog.Info("Fetching all runs", slog.Int("fetched", len(seen)), slog.Int("total", runs.GetTotalCount()))
+		}
+		if len(workflowRuns) >= count &&
+			(cutoff.IsZero() || workflowRuns[len(workflowRuns)-1].GetCreatedAt().Before(cutoff)) {
+			break
+		}
+		if res.NextPage == 0 {
+			if returned < maxQueryResults || unseen == 0 {
+				break
+			}
+			listOptions.Created = createdBefore(listOptions.Created, oldest)
+			listOptions.Page = 0
+			returned, unseen = 0, 0
+			continue
+		}
+		listOptions.Page = res.NextPage
+	}
+	if !cutoff.IsZero() {
+		i := count
+		for i < len(workflowRuns) && !workflowRuns[i].GetCreatedAt().Before(cutoff) {
+			i++
+		}
+		return workflowRuns[:min(i, len(workflowRuns))], nil
+	}
+	if len(workflowRuns) > count {
+		return workflowRuns[:count], nil
+	}
+	return workflowRuns, nil
+}
+
+// createdBefore narrows down the created filter of a query to runs created at or before t.
+func createdBefore(created string, t time.Time) string {
+	from, _ := SplitTimeRange(created)
+	if from == "" {
+		return "<=" + t.UTC().Format(time.RFC3339)
+	}
+	return from + ".." + t.UTC().Format(time.RFC3339)
+}
+
+// SplitTimeRange returns the start and the end of the created filter. Either of them is
+// empty if the range is open.
+func SplitTimeRange(created string) (string, string) {
+	if from, ok := strings.CutPrefix(created, ">="); ok {
+		return from, ""
+	}
+	if to, ok := strings.CutPrefix(created, "<="); ok {
+		return "", to
+	}
+	from, to, _ := strings.Cut(created, "..")
+	return from, to
+}
+
+// GetJobs returns the jobs of the latest attempt of the workflow run.
+func GetJobs(ctx context.Context, actions ActionsService, owner, repo string, runID int64) ([]*github.WorkflowJob, error) {
+	listOptions := github.ListWorkflowJobsOptions{
+		ListOptions: github.ListOptions{},
+	}
+	var result []*github.WorkflowJob
+	for {
+		jobs, res, err := actions.ListWorkflowJobs(ctx, owner, repo, runID, &listOptions)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, jobs.Jobs...)
+		if res.NextPage == 0 {
+			break
+		}
+		listOptions.Page = res.NextPage
+	}
+	return result, nil
+}
+
+// GetJobsForRuns fetches jobs for the given workflow runs with the given number of
+// concurrent requests, at least one, and returns them keyed by run ID. Runs whose jobs
+// failed to fetch are logged and omitted.
+func GetJobsForRuns(ctx context.Context, actions ActionsService, owner, repo string, runs []*github.WorkflowRun, workers int) map[int64][]*github.WorkflowJob {
+	workers = max(workers, 1)
+	result := map[int64][]*github.WorkflowJob{}
+	tasks := make(chan int64)
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			for runID := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				jobs, err := GetJobs(ctx, actions, owner, repo, runID)
+				if err != nil {
+					slog.Error("Failed to get jobs", slog.Int64("run-id", runID), slog.Any("error", err))
+					continue
+				}
+				mux.Lock()
+				result[runID] = jobs
+				mux.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	for _, run := range runs {
+		tasks <- run.GetID()
+	}
+	close(tasks)
+	wg.Wait()
+	return result
+}
+
+// GetJobLogsURL returns the URL to download the logs of the job from.
+func GetJobLogsURL(ctx context.Context, actions ActionsService, owner, repo string, jobID int64) (*url.URL, error) {
+	logsURL, _, err := actions.GetWorkflowJobLogs(ctx, owner, repo, jobID, 10)
+	return logsURL, err
+}
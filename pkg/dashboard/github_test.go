@@ -0,0 +1,103 @@
+package dashboard_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard/dashboardtest"
+)
+
+var now = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// newRuns returns n runs of the main branch created every hour before now, newest first,
+// with the conclusion returned by conclusion for each index.
+func newRuns(n int, conclusion func(i int) string) []*github.WorkflowRun {
+	var runs []*github.WorkflowRun
+	for i := 0; i < n; i++ {
+		created := now.Add(-time.Duration(i+1) * time.Hour)
+		runs = append(runs, &github.WorkflowRun{
+			ID:           github.Int64(int64(n - i)),
+			HeadBranch:   github.String("main"),
+			Event:        github.String("schedule"),
+			Conclusion:   github.String(conclusion(i)),
+			CreatedAt:    &github.Timestamp{Time: created},
+			RunStartedAt: &github.Timestamp{Time: created},
+			UpdatedAt:    &github.Timestamp{Time: created.Add(10 * time.Minute)},
+		})
+	}
+	return runs
+}
+
+func success(int) string { return "success" }
+
+func createdSince(hours int) string {
+	return ">=" + now.Add(-time.Duration(hours)*time.Hour).Format(time.RFC3339)
+}
+
+func TestListWorkflowRuns(t *testing.T) {
+	everyThirdCancelled := func(i int) string {
+		if i%3 == 0 {
+			return "cancelled"
+		}
+		return "success"
+	}
+	mergeQueue := newRuns(6, success)
+	for i, run := range mergeQueue {
+		run.Event = github.String("merge_group")
+		run.HeadBranch = github.String("gh-readonly-queue/main/pr-1-0123abcd")
+		if i%2 == 0 {
+			run.HeadBranch = github.String("gh-readonly-queue/v1.15/pr-2-0123abcd")
+		}
+	}
+	for _, tt := range []struct {
+		name string
+		runs []*github.WorkflowRun
+		opts dashboard.RunOptions
+		want int
+	}{
+		{"count", newRuns(250, success), dashboard.RunOptions{Count: 64}, 64},
+		{"fewer runs than count", newRuns(20, success), dashboard.RunOptions{Count: 64}, 20},
+		{"and mode", newRuns(100, success), dashboard.RunOptions{Count: 64, Created: createdSince(24)}, 24},
+		{"or mode with more runs than count", newRuns(100, success), dashboard.RunOptions{Count: 10, Created: createdSince(24), LimitMode: "or"}, 24},
+		{"or mode with fewer runs than count", newRuns(100, success), dashboard.RunOptions{Count: 64, Created: createdSince(24), LimitMode: "or"}, 64},
+		{"or mode with all runs", newRuns(100, success), dashboard.RunOptions{Count: dashboard.AllRuns, Created: createdSince(24), LimitMode: "or"}, 24},
+		{"all runs beyond the result limit", newRuns(2500, success), dashboard.RunOptions{Count: dashboard.AllRuns}, 2500},
+		{"all runs in a range beyond the result limit", newRuns(2500, success), dashboard.RunOptions{Count: dashboard.AllRuns, Created: createdSince(1200)}, 1200},
+		{"default conclusions", newRuns(30, everyThirdCancelled), dashboard.RunOptions{Count: 64}, 20},
+		{"counted conclusions", newRuns(30, everyThirdCancelled), dashboard.RunOptions{Count: 64, Conclusions: []string{"success", "cancelled"}}, 30},
+		{"merge queue", mergeQueue, dashboard.RunOptions{Branch: "main", Event: "merge_group", Count: 64}, 3},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := &dashboardtest.Actions{Runs: map[string][]*github.WorkflowRun{"ci.yaml": tt.runs}}
+			runs, err := dashboard.GetWorkflowRuns(context.Background(), actions, "owner", "repo", "ci.yaml", tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(runs) != tt.want {
+				t.Errorf("got %d runs, want %d", len(runs), tt.want)
+			}
+			seen := map[int64]bool{}
+			for i, run := range runs {
+				if seen[run.GetID()] {
+					t.Fatalf("run %d returned twice", run.GetID())
+				}
+				seen[run.GetID()] = true
+				if i > 0 && run.GetCreatedAt().After(runs[i-1].GetCreatedAt().Time) {
+					t.Fatalf("runs are not sorted from newest to oldest at %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestGetWorkflowRunsNotFound(t *testing.T) {
+	actions := &dashboardtest.Actions{}
+	_, err := dashboard.GetWorkflowRuns(context.Background(), actions, "owner", "repo", "missing.yaml", dashboard.RunOptions{Count: 1})
+	if !errors.Is(err, dashboard.ErrWorkflowNotFound) {
+		t.Errorf("got error %v, want %v", err, dashboard.ErrWorkflowNotFound)
+	}
+}
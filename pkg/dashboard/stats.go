@@ -0,0 +1,197 @@
+package dashboard
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// WorkflowStats are the stats of the runs of a workflow.
+type WorkflowStats struct {
+	Workflow string
+	// From and To are the start times of the oldest and the latest runs.
+	From time.Time
+	To   time.Time
+	// Runs is the number of runs, of which Success succeeded.
+	Runs    int
+	Success int
+	// SuccessRate is the percentage of successful runs.
+	SuccessRate float32
+	// AverageDuration is the average duration of the successful runs.
+	AverageDuration time.Duration
+	// AverageFailureDuration is the average duration of the unsuccessful runs, such as
+	// failed and timed out runs.
+	AverageFailureDuration time.Duration
+	// FailureStreak is the number of consecutive failed runs counting from the latest run.
+	FailureStreak int
+	// LastSuccess is the latest successful run, or nil if none of the runs succeeded.
+	LastSuccess *github.WorkflowRun
+	// Conclusions are the number of runs for each conclusion.
+	Conclusions map[string]int
+}
+
+// NewWorkflowStats returns the stats of the runs of the workflow, with the durations of
+// the runs from durations if set. Runs are expected to be sorted from newest to oldest.
+func NewWorkflowStats(workflow string, runs []*github.WorkflowRun, durations Durations) WorkflowStats {
+	stats := WorkflowStats{
+		Workflow:               workflow,
+		Runs:                   len(runs),
+		SuccessRate:            SuccessRate(runs),
+		AverageDuration:        AverageSuccessDuration(runs, durations),
+		AverageFailureDuration: AverageFailureDuration(runs, durations),
+		FailureStreak:          FailureStreak(runs),
+		LastSuccess:            LastSuccess(runs),
+		Conclusions:            map[string]int{},
+	}
+	if len(runs) > 0 {
+		stats.From = runs[len(runs)-1].GetRunStartedAt().Time
+		stats.To = runs[0].GetRunStartedAt().Time
+	}
+	for _, run := range runs {
+		stats.Conclusions[run.GetConclusion()]++
+	}
+	stats.Success = stats.Conclusions["success"]
+	return stats
+}
+
+// WindowStats returns the stats of the latest 4, 8, 16, and so on runs for as long as
+// there are enough runs, or of all the runs if there are fewer than 4. Runs are expected to
+// be sorted from newest to oldest.
+func WindowStats(workflow string, runs []*github.WorkflowRun, durations Durations) []WorkflowStats {
+	var result []WorkflowStats
+	for count := min(len(runs), 4); count > 0 && count <= len(runs); count *= 2 {
+		result = append(result, NewWorkflowStats(workflow, runs[:count], durations))
+	}
+	return result
+}
+
+// Durations are the durations of runs keyed by run ID, such as durations measured with
+// the timing API, to use instead of the time between the start and the last update of the
+// runs. A nil Durations uses RunDuration for all the runs.
+type Durations map[int64]time.Duration
+
+// Of returns the duration of the run, or RunDuration if it has none.
+func (d Durations) Of(run *github.WorkflowRun) time.Duration {
+	if duration, ok := d[run.GetID()]; ok {
+		return duration
+	}
+	return RunDuration(run)
+}
+
+// RunDuration returns the duration of a workflow run, which is the time between its start
+// and its last update.
+func RunDuration(run *github.WorkflowRun) time.Duration {
+	return run.GetUpdatedAt().Time.Sub(run.GetRunStartedAt().Time)
+}
+
+// SuccessRate returns the percentage of successful runs.
+func SuccessRate(runs []*github.WorkflowRun) float32 {
+	if len(runs) == 0 {
+		return 0
+	}
+	success := 0
+	for _, run := range runs {
+		if run.GetConclusion() == "success" {
+			success++
+		}
+	}
+	return 100 * float32(success) / float32(len(runs))
+}
+
+// Percentile returns the p-th percentile (0-100) of the durations using the
+// nearest-rank method.
+func Percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
+
+// Average returns the average of the durations.
+func Average(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	return total / time.Duration(len(durations))
+}
+
+// AverageSuccessDuration returns the average duration of the successful runs, with the
+// durations of the runs from durations if set.
+func AverageSuccessDuration(runs []*github.WorkflowRun, durations Durations) time.Duration {
+	var result []time.Duration
+	for _, run := range runs {
+		if run.GetConclusion() == "success" {
+			result = append(result, durations.Of(run))
+		}
+	}
+	return Average(result).Round(time.Second)
+}
+
+// AverageFailureDuration returns the average duration of the unsuccessful runs, with the
+// durations of the runs from durations if set.
+func AverageFailureDuration(runs []*github.WorkflowRun, durations Durations) time.Duration {
+	var result []time.Duration
+	for _, run := range runs {
+		if run.GetConclusion() != "success" {
+			result = append(result, durations.Of(run))
+		}
+	}
+	return Average(result).Round(time.Second)
+}
+
+// WeightedSuccessRate returns the percentage of successful runs where the weight of each
+// run halves with every halfLife of its age, so that recent runs count more. Ages are
+// relative to the newest run so that the weights don't underflow with short half-lives.
+func WeightedSuccessRate(runs []*github.WorkflowRun, halfLife time.Duration) float32 {
+	newest := time.Time{}
+	for _, run := range runs {
+		if run.GetCreatedAt().After(newest) {
+			newest = run.GetCreatedAt().Time
+		}
+	}
+	var success, total float64
+	for _, run := range runs {
+		weight := math.Pow(0.5, newest.Sub(run.GetCreatedAt().Time).Hours()/halfLife.Hours())
+		total += weight
+		if run.GetConclusion() == "success" {
+			success += weight
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float32(100 * success / total)
+}
+
+// FailureStreak returns the number of consecutive failed runs counting from the latest
+// run. Runs are expected to be sorted from newest to oldest.
+func FailureStreak(runs []*github.WorkflowRun) int {
+	streak := 0
+	for _, run := range runs {
+		if run.GetConclusion() == "success" {
+			break
+		}
+		streak++
+	}
+	return streak
+}
+
+// LastSuccess returns the latest successful run, or nil if none of the runs succeeded.
+// Runs are expected to be sorted from newest to oldest.
+func LastSuccess(runs []*github.WorkflowRun) *github.WorkflowRun {
+	for _, run := range runs {
+		if run.GetConclusion() == "success" {
+			return run
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package dashboard_test
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+)
+
+func TestNewWorkflowStats(t *testing.T) {
+	// The latest two runs failed, and every third run before them.
+	runs := newRuns(10, func(i int) string {
+		if i < 2 || i%3 == 0 {
+			return "failure"
+		}
+		return "success"
+	})
+	runs[8].UpdatedAt.Time = runs[8].GetRunStartedAt().Add(20 * time.Minute)
+	stats := dashboard.NewWorkflowStats("ci.yaml", runs, nil)
+	want := dashboard.WorkflowStats{
+		Workflow:               "ci.yaml",
+		From:                   runs[9].GetRunStartedAt().Time,
+		To:                     runs[0].GetRunStartedAt().Time,
+		Runs:                   10,
+		Success:                5,
+		SuccessRate:            50,
+		AverageDuration:        12 * time.Minute,
+		AverageFailureDuration: 10 * time.Minute,
+		FailureStreak:          2,
+		LastSuccess:            runs[2],
+		Conclusions:            map[string]int{"success": 5, "failure": 5},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("got %+v, want %+v", stats, want)
+	}
+}
+
+func TestNewWorkflowStatsWithoutRuns(t *testing.T) {
+	stats := dashboard.NewWorkflowStats("ci.yaml", nil, nil)
+	if stats.Runs != 0 || stats.SuccessRate != 0 || stats.AverageDuration != 0 || stats.LastSuccess != nil {
+		t.Errorf("got %+v, want empty stats", stats)
+	}
+}
+
+func TestDurations(t *testing.T) {
+	runs := newRuns(2, success)
+	durations := dashboard.Durations{runs[0].GetID(): time.Hour}
+	if got := durations.Of(runs[0]); got != time.Hour {
+		t.Errorf("duration of a measured run = %s, want 1h", got)
+	}
+	if got := durations.Of(runs[1]); got != 10*time.Minute {
+		t.Errorf("duration of another run = %s, want 10m", got)
+	}
+	if got := dashboard.Durations(nil).Of(runs[0]); got != 10*time.Minute {
+		t.Errorf("duration without measured durations = %s, want 10m", got)
+	}
+	if got := dashboard.AverageSuccessDuration(runs, durations); got != 35*time.Minute {
+		t.Errorf("AverageSuccessDuration = %s, want 35m", got)
+	}
+}
+
+func TestWindowStats(t *testing.T) {
+	for _, tt := range []struct {
+		runs int
+		want []int
+	}{
+		{0, nil},
+		{3, []int{3}},
+		{4, []int{4}},
+		{7, []int{4}},
+		{8, []int{4, 8}},
+		{64, []int{4, 8, 16, 32, 64}},
+		{100, []int{4, 8, 16, 32, 64}},
+	} {
+		var got []int
+		for _, stats := range dashboard.WindowStats("ci.yaml", newRuns(tt.runs, success), nil) {
+			got = append(got, stats.Runs)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("WindowStats of %d runs got windows %v, want %v", tt.runs, got, tt.want)
+		}
+	}
+}
+
+func TestWeightedSuccessRate(t *testing.T) {
+	// The latest run failed, and the run a day before it succeeded.
+	failedLatest := newRuns(25, func(i int) string {
+		if i == 0 {
+			return "failure"
+		}
+		return "success"
+	})
+	failedLatest = append(failedLatest[:1], failedLatest[24:]...)
+	// The latest run succeeded, and the run a day before it failed.
+	succeededLatest := newRuns(25, func(i int) string {
+		if i == 0 {
+			return "success"
+		}
+		return "failure"
+	})
+	succeededLatest = append(succeededLatest[:1], succeededLatest[24:]...)
+	for _, tt := range []struct {
+		name     string
+		runs     []*github.WorkflowRun
+		halfLife time.Duration
+		want     float32
+	}{
+		{"long half-life", failedLatest, 1000 * time.Hour, 50},
+		{"half-life of a day", failedLatest, 24 * time.Hour, 100.0 / 3},
+		{"half-life of an hour", failedLatest, time.Hour, 0},
+		{"half-life of an hour after a success", succeededLatest, time.Hour, 100},
+		// Weighting by the age since now would underflow to 0 for both runs.
+		{"half-life of a second", succeededLatest, time.Second, 100},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dashboard.WeightedSuccessRate(tt.runs, tt.halfLife)
+			if diff := got - tt.want; diff < -0.5 || diff > 0.5 {
+				t.Errorf("got %.1f%%, want %.1f%%", got, tt.want)
+			}
+		})
+	}
+	if got := dashboard.WeightedSuccessRate(nil, time.Hour); got != 0 {
+		t.Errorf("got %.1f%% without runs, want 0%%", got)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	seconds := func(values ...int) []time.Duration {
+		var durations []time.Duration
+		for _, v := range values {
+			durations = append(durations, time.Duration(v)*time.Second)
+		}
+		return durations
+	}
+	oneToTen := seconds(10, 9, 8, 7, 6, 5, 4, 3, 2, 1)
+	for _, tt := range []struct {
+		name      string
+		durations []time.Duration
+		p         float64
+		want      time.Duration
+	}{
+		{"no durations", nil, 50, 0},
+		{"single duration", seconds(5), 90, 5 * time.Second},
+		{"p50 of two", seconds(2, 1), 50, time.Second},
+		{"p0", oneToTen, 0, time.Second},
+		{"p10", oneToTen, 10, time.Second},
+		{"p50", oneToTen, 50, 5 * time.Second},
+		{"p90", oneToTen, 90, 9 * time.Second},
+		{"p95", oneToTen, 95, 10 * time.Second},
+		{"p100", oneToTen, 100, 10 * time.Second},
+	} {
+		if got := dashboard.Percentile(tt.durations, tt.p); got != tt.want {
+			t.Errorf("%s: Percentile(%v) = %s, want %s", tt.name, tt.p, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package dashboard
+
+import (
+	"context"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// SuccessCriteria decides whether a run counts as a success, for example to encode a
+// policy that failures of known flaky jobs don't count.
+type SuccessCriteria interface {
+	// Success is called for runs that did not succeed, along with their jobs.
+	Success(run *github.WorkflowRun, jobs []*github.WorkflowJob) bool
+}
+
+// SuccessCriteriaFunc is an adapter to use a function as SuccessCriteria.
+type SuccessCriteriaFunc func(run *github.WorkflowRun, jobs []*github.WorkflowJob) bool
+
+func (f SuccessCriteriaFunc) Success(run *github.WorkflowRun, jobs []*github.WorkflowJob) bool {
+	return f(run, jobs)
+}
+
+// ApplySuccessCriteria replaces the runs that did not succeed but meet the criteria with
+// copies whose conclusion is success, so that all the stats treat them as such. The jobs
+// of the runs that did not succeed are fetched with the given number of concurrent
+// requests.
+func ApplySuccessCriteria(ctx context.Context, actions ActionsService, owner, repo string, runs []*github.WorkflowRun, criteria SuccessCriteria, workers int) {
+	var failedRuns []*github.WorkflowRun
+	for _, run := range runs {
+		if run.GetConclusion() != "success" {
+			failedRuns = append(failedRuns, run)
+		}
+	}
+	if len(failedRuns) == 0 {
+		return
+	}
+	jobs := GetJobsForRuns(ctx, actions, owner, repo, failedRuns, workers)
+	for i, run := range runs {
+		if run.GetConclusion() != "success" && criteria.Success(run, jobs[run.GetID()]) {
+			success := *run
+			success.Conclusion = github.String("success")
+			runs[i] = &success
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package dashboard_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v59/github"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard"
+	"github.com/michi-covalent/ci-dashboard/pkg/dashboard/dashboardtest"
+)
+
+func TestApplySuccessCriteria(t *testing.T) {
+	// Runs 3 and 1 failed, and run 2 succeeded.
+	runs := newRuns(3, func(i int) string {
+		if i == 1 {
+			return "success"
+		}
+		return "failure"
+	})
+	original := runs[0]
+	actions := &dashboardtest.Actions{Jobs: map[int64][]*github.WorkflowJob{
+		3: {newJob(31, 3, "flaky", "failure")},
+		1: {newJob(11, 1, "test", "failure")},
+	}}
+	onlyFlaky := dashboard.SuccessCriteriaFunc(func(run *github.WorkflowRun, jobs []*github.WorkflowJob) bool {
+		for _, job := range jobs {
+			if job.GetConclusion() == "failure" && job.GetName() != "flaky" {
+				return false
+			}
+		}
+		return true
+	})
+	dashboard.ApplySuccessCriteria(context.Background(), actions, "owner", "repo", runs, onlyFlaky, 1)
+	for i, want := range []string{"success", "success", "failure"} {
+		if got := runs[i].GetConclusion(); got != want {
+			t.Errorf("run %d has conclusion %s, want %s", runs[i].GetID(), got, want)
+		}
+	}
+	if original.GetConclusion() != "failure" {
+		t.Errorf("the original run was modified")
+	}
+}